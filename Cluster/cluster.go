@@ -1,6 +1,7 @@
 package Cluster
 
 import (
+	"alg_bcDB/config"
 	"alg_bcDB/util"
 	"bytes"
 	"crypto/elliptic"
@@ -9,15 +10,42 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net"
 	"os"
+	"strconv"
 	"time"
 )
 
 // Node  定义集群节点结构
 type Node struct {
-	IP      string // IP
-	Port    int    // 端口号
-	Account bool   //是否有记账权
+	IP       string // IP
+	Port     int    // 端口号
+	Account  bool   //是否有记账权
+	RaftPort int    // Raft 的端口号
+	FilePort int    // 发送集群文件的端口号
+}
+
+// RaftAddr 节点 Raft 的地址, 旧的集群文件没有端口号时使用默认端口
+func (node *Node) RaftAddr() string {
+	port := node.RaftPort
+	if port == 0 {
+		port = config.Default().Ports.Raft
+	}
+	return net.JoinHostPort(node.IP, strconv.Itoa(port))
+}
+
+// FileAddr 节点发送集群文件的地址
+func (node *Node) FileAddr() string {
+	port := node.FilePort
+	if port == 0 {
+		port = config.Default().Ports.Cluster
+	}
+	return net.JoinHostPort(node.IP, strconv.Itoa(port))
+}
+
+// FilePath 集群文件的路径, 在数据目录下
+func FilePath() string {
+	return config.LocalConfig.Path("ClusterInfo")
 }
 
 // Cluster 定义集群的结构
@@ -35,9 +63,11 @@ func Init(key string) *Cluster {
 	// 节点信息
 	var nodes []*Node
 	var node Node
-	node.IP = util.GetLocalIp()
-	node.Port = 3301
+	node.IP = util.LocalIP
+	node.Port = util.LocalPort
 	node.Account = false
+	node.RaftPort = config.LocalConfig.Ports.Raft
+	node.FilePort = config.LocalConfig.Ports.Cluster
 	nodes = append(nodes, &node)
 	clu := Cluster{
 		Node: nodes,
@@ -57,7 +87,7 @@ func SaveClusterFile() {
 		fmt.Println("SaveClusterFile failed")
 		log.Panic(err)
 	}
-	err = ioutil.WriteFile("ClusterInfo", util.AesCTREncrypt(buf.Bytes(), []byte("1234567812345678")), 0644)
+	err = ioutil.WriteFile(FilePath(), util.AesCTREncrypt(buf.Bytes(), []byte("1234567812345678")), 0644)
 	if err != nil {
 		log.Panic(err)
 	}
@@ -100,7 +130,7 @@ func (clu *Cluster) UpdateClusterFile() {
 	for {
 		time.Sleep(time.Millisecond * 1500)
 		nodes := delRepeatElem(clu.Node)
-		newClu := Cluster{Node: nodes}
+		newClu := Cluster{Node: nodes, Key: clu.Key}
 		// 全局变量的更新
 		LocalNode = &newClu
 		// 写入文件
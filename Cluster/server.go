@@ -1,6 +1,7 @@
 package Cluster
 
 import (
+	"alg_bcDB/config"
 	"log"
 	"net"
 )
@@ -10,7 +11,7 @@ import (
 // Server 启动监听服务
 func Server() {
 	// 启动监听程序
-	listener, err := net.Listen("tcp", ":3303")
+	listener, err := net.Listen("tcp", config.LocalConfig.ListenAddr(config.LocalConfig.Ports.Cluster))
 	//defer listener.Close()
 	if err != nil {
 		log.Panic(err)
@@ -31,5 +32,5 @@ func Handler(conn net.Conn) {
 	// 关闭连接
 	defer conn.Close()
 	// 读取genesis文件与文件的发送
-	SendFile(conn, "./ClusterInfo")
+	SendFile(conn, FilePath())
 }
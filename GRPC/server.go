@@ -2,6 +2,7 @@ package GRPC
 
 import (
 	BcGrpc "alg_bcDB/Proto/blockchain"
+	"alg_bcDB/config"
 	"google.golang.org/grpc"
 	"log"
 	"net"
@@ -38,7 +39,7 @@ func (s *Server) startService() {
 	// 注册服务
 	BcGrpc.RegisterBlockChainServiceServer(grpcServer, &Service{})
 	// 创建监听
-	listen, err := net.Listen("tcp", ":3301")
+	listen, err := net.Listen("tcp", config.LocalConfig.ListenAddr(config.LocalConfig.Ports.Grpc))
 	if err != nil {
 		log.Panic(err)
 	}
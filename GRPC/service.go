@@ -7,12 +7,12 @@ import (
 	BCData "alg_bcDB/blockchain/blockchain_data"
 	BCTable "alg_bcDB/blockchain/blockchain_table"
 	"alg_bcDB/cache"
+	"alg_bcDB/config"
 	"alg_bcDB/txpool"
 	"alg_bcDB/util"
 	"bytes"
 	"context"
 	"errors"
-	"fmt"
 	"log"
 	"strconv"
 )
@@ -33,6 +33,13 @@ func (s *Service) DistributeDataBlock(ctx context.Context, req *BcGrpc.DataBlock
 	if !flag {
 		info.Info = "区块校验失败"
 		info.Status = false
+		return info, nil
+	}
+	// 写入权限的校验, 没有权限的写入不能上链
+	if err := cache.LocalCache.CheckBlockWrites(block); err != nil {
+		info.Info = "区块校验失败: " + err.Error()
+		info.Status = false
+		return info, nil
 	}
 	// 将区块上链
 	BCData.LocalDataBlockChain.AddBlockToChain(*block)
@@ -77,10 +84,11 @@ func (s *Service) DistributeTableBlock(ctx context.Context, req *BcGrpc.TableBlo
 	block := GrpcTableBlockToBlock(req)
 	// 区块的校验
 	//fmt.Println("rpcService 权限区块的进入校验")
-	flag := BCTable.LocalTableBlockChain.CheckTableBlock(block)
+	flag := BCTable.LocalTableBlockChain.CheckTableBlock(block, cache.LocalCache.Permissions)
 	if !flag {
 		info.Info = "区块校验失败"
 		info.Status = false
+		return info, nil
 	}
 	// 上链
 	BCTable.LocalTableBlockChain.AddBlockToChain(*block)
@@ -135,6 +143,11 @@ func (s *Service) DataTradingPool(ctx context.Context, req *BcGrpc.DataTransacti
 		info.Status = false
 		return info, errors.New("交易验证失败")
 	}
+	if err = cache.LocalCache.CheckWrite(tx); err != nil {
+		info.Info = "交易验证失败: " + err.Error()
+		info.Status = false
+		return info, err
+	}
 	// 交易入本地交易池
 	err = txpool.LocalTxPool.TxDataIN(*tx)
 	if err != nil {
@@ -159,7 +172,7 @@ func (s *Service) TableTradingPool(ctx context.Context, req *BcGrpc.TableTransac
 		info.Status = false
 		return info, errors.New("交易验证失败")
 	}
-	// 交易入本地交易池
+	// 交易入本地交易池, 入池时检查签名者的权限
 	err = txpool.LocalTxPool.TxTableIN(*tx)
 	if err != nil {
 		info.Info = "交易入池失败"
@@ -176,21 +189,27 @@ func (s *Service) JoinCluster(ctx context.Context, req *BcGrpc.ReqJoin) (info *B
 	info.Status = true
 
 	// 读取集群文件
-	cluster, err := Cluster.LoadClusterFile("./ClusterInfo")
+	cluster, err := Cluster.LoadClusterFile(Cluster.FilePath())
 	if err != nil {
 		info.Info = "读取集群文件失败"
 		info.Status = false
 		return info, err
 	}
-	fmt.Println(strconv.Itoa(3301) == req.LocalPort)
 	// 判断节点是否在集群中
 	for _, node := range cluster.Node {
-		if node.IP == req.LocalPort && strconv.Itoa(node.Port) == req.LocalPort {
+		if node.IP == req.LocalIp && strconv.Itoa(node.Port) == req.LocalPort {
 			info.Info = "节点已在集群中"
 			info.Status = false
 			return info, errors.New("节点已在集群中")
 		}
 	}
+	// 判断创世区块是否一致, 不同网络(创世文件不同)的节点不能加入
+	if !bytes.Equal(req.DataGenesisHash, BCData.LocalDataBlockChain.GetHashByRound(0)) ||
+		!bytes.Equal(req.TableGenesisHash, BCTable.LocalTableBlockChain.GetHashByID(1)) {
+		info.Info = "创世区块和集群不一致"
+		info.Status = false
+		return info, errors.New("创世区块和集群不一致")
+	}
 	// 判断加入密钥是否正确
 	if !bytes.Equal(req.JoinKey, cluster.Key) {
 		info.Info = "加入集群的密钥错误"
@@ -204,7 +223,10 @@ func (s *Service) JoinCluster(ctx context.Context, req *BcGrpc.ReqJoin) (info *B
 	if err != nil {
 		log.Panic(err)
 	}
+	node.RaftPort = int(req.RaftPort)
+	node.FilePort = int(req.FilePort)
 	cluster.AddNodeToClusterFile(&node)
+	info.FilePort = int32(config.LocalConfig.Ports.Cluster)
 
 	return info, nil
 }
@@ -223,6 +245,8 @@ func (s *Service) BroadcastNode(ctx context.Context, req *BcGrpc.NodeInfo) (info
 		return info, err
 	}
 	node.Port = port
+	node.RaftPort = int(req.RaftPort)
+	node.FilePort = int(req.FilePort)
 	Cluster.LocalNode.AddNodeToClusterFile(node)
 	return info, nil
 }
@@ -245,3 +269,48 @@ func (s Service) Handle(ctx context.Context, data *BcGrpc.TypAndData) (info *BcG
 //	algorand.LocalPeer.SetTime(data.Time)
 //	return info, nil
 //}
+
+// VerifyChain 审计本地的区块链, 从创世区块到链尾
+func (s *Service) VerifyChain(ctx context.Context, req *BcGrpc.ReqVerifyChain) (*BcGrpc.ResVerifyChain, error) {
+	out := &BcGrpc.ResVerifyChain{}
+	if req.Chain == "" || req.Chain == "data" {
+		out.Reports = append(out.Reports, ReportToGrpcReport(BCData.LocalDataBlockChain.VerifyChain()))
+	}
+	if req.Chain == "" || req.Chain == "table" {
+		out.Reports = append(out.Reports, ReportToGrpcReport(BCTable.LocalTableBlockChain.VerifyChain()))
+	}
+	if len(out.Reports) == 0 {
+		return nil, errors.New("no such chain")
+	}
+	return out, nil
+}
+
+// DataHeaderSynchronization 数据区块头的同步, 返回请求的区块之后的所有区块头(按照 Round 从小到大)
+func (s *Service) DataHeaderSynchronization(ctx context.Context, req *BcGrpc.ReqDataBlock) (*BcGrpc.ResDataBlocks, error) {
+	out := &BcGrpc.ResDataBlocks{}
+	chain := BCData.LocalDataBlockChain
+	if !bytes.Equal(chain.GetHashByRound(uint64(req.BlockID)), req.Hash) {
+		return nil, errors.New("区块链不一致")
+	}
+	for round := uint64(req.BlockID) + 1; round < chain.LastID; round++ {
+		header, err := chain.GetHeaderByHash(chain.GetHashByRound(round))
+		if err != nil {
+			return nil, err
+		}
+		out.Blocks = append(out.Blocks, BlockToGrpcDataBlock(header))
+	}
+	return out, nil
+}
+
+// GetDataBlockBody 获取数据区块的区块体
+func (s *Service) GetDataBlockBody(ctx context.Context, req *BcGrpc.ReqBlockBody) (*BcGrpc.DataBlock, error) {
+	chain := BCData.LocalDataBlockChain
+	if _, err := chain.GetHeaderByHash(req.Hash); err != nil {
+		return nil, errors.New("不存在这个区块")
+	}
+	block, err := chain.GetBlockByHash(req.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return BlockToGrpcDataBlock(block), nil
+}
@@ -3,11 +3,15 @@ package GRPC
 import (
 	"alg_bcDB/Cluster"
 	BcGrpc "alg_bcDB/Proto/blockchain"
+	"alg_bcDB/algorand"
 	BCData "alg_bcDB/blockchain/blockchain_data"
 	BCTable "alg_bcDB/blockchain/blockchain_table"
 	"alg_bcDB/blockqueue"
 	"alg_bcDB/cache"
+	"alg_bcDB/common"
+	"alg_bcDB/config"
 	"alg_bcDB/util"
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"errors"
@@ -15,6 +19,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"log"
+	"net"
 	"os"
 	"strconv"
 	"time"
@@ -27,20 +32,16 @@ func BlockToGrpcDataBlock(block *BCData.Block) *BcGrpc.DataBlock {
 		PreviousBlockHash: block.PreviousBlockHash,
 		MerKelRoot:        block.MerKelRoot,
 		TimeTamp:          block.TimeStamp,
+		Seed:              block.Seed,
+		Author:            block.Author[:],
+		Proof:             block.Proof,
+		Type:              int32(block.Type),
+		Signature:         block.Signature,
+		Header:            block.EncodeHeader(),
 	}
 	newGrpcBlock.TxInfo = []*BcGrpc.DataTransaction{}
 	for _, tx := range block.Transactions {
-		newGrpcBlock.TxInfo = append(newGrpcBlock.TxInfo, &BcGrpc.DataTransaction{
-			TxID:      tx.TxID,
-			DataID:    tx.DataID,
-			Table:     tx.Table,
-			Key:       tx.Key,
-			Value:     tx.Value,
-			Possessor: tx.Possessor,
-			TimeStamp: tx.TimeStamp,
-			PublicKey: tx.PublicKey,
-			Signature: tx.Signature,
-		})
+		newGrpcBlock.TxInfo = append(newGrpcBlock.TxInfo, DataTxToGrpcDataTx(tx))
 	}
 	return newGrpcBlock
 }
@@ -52,91 +53,148 @@ func BlockToGrpcTableBlock(block *BCTable.Block) *BcGrpc.TableBlock {
 		PreviousBlockHash: block.PreviousBlockHash,
 		MerKelRoot:        block.MerKelRoot,
 		TimeTamp:          block.TimeStamp,
+		Header:            block.EncodeHeader(),
 	}
 	newGrpcBlock.TxInfo = []*BcGrpc.TableTransaction{}
 	for _, tx := range block.Transactions {
-		newGrpcBlock.TxInfo = append(newGrpcBlock.TxInfo, &BcGrpc.TableTransaction{
-			TxID:             tx.TxID,
-			Table:            tx.Table,
-			PermissionTables: tx.PermissionTable,
-			Possessor:        tx.Possessor,
-			TimeStamp:        tx.TimeStamp,
-			PublicKey:        tx.PublicKey,
-			Signature:        tx.Signature,
-		})
+		newGrpcBlock.TxInfo = append(newGrpcBlock.TxInfo, TableTxToGrpcTableTx(tx))
 	}
 	return newGrpcBlock
 }
 
+// GrpcDataBlockToBlock 区块的转换
+// 有区块头的规范编码时直接由它还原区块头, 没有时(旧的节点)使用各个字段
 func GrpcDataBlockToBlock(block *BcGrpc.DataBlock) *BCData.Block {
 	newBlock := &BCData.Block{
 		Round:             uint64(block.ID),
-		CurrentBlockHash:  block.CurrentBlockHash,
 		PreviousBlockHash: block.PreviousBlockHash,
 		MerKelRoot:        block.MerKelRoot,
 		TimeStamp:         block.TimeTamp,
+		Seed:              block.Seed,
+		Author:            common.BytesToAddress(block.Author),
+		Proof:             block.Proof,
+		Type:              int8(block.Type),
+	}
+	if len(block.Header) != 0 {
+		header, err := BCData.DecodeHeader(block.Header)
+		if err != nil {
+			log.Println("区块头解码失败", err)
+		} else {
+			newBlock = header
+		}
 	}
+	// 区块的 HASH 使用收到的, 由区块的校验检查它和区块头是否一致
+	newBlock.CurrentBlockHash = block.CurrentBlockHash
+	newBlock.Signature = block.Signature
 	newBlock.Transactions = []*BCData.Transaction{}
 	for _, tx := range block.TxInfo {
-		newBlock.Transactions = append(newBlock.Transactions, &BCData.Transaction{
-			TxID:      tx.TxID,
-			DataID:    tx.DataID,
-			Table:     tx.Table,
-			Key:       tx.Key,
-			Value:     tx.Value,
-			Possessor: tx.Possessor,
-			TimeStamp: tx.TimeStamp,
-			PublicKey: tx.PublicKey,
-			Signature: tx.Signature,
-		})
+		newBlock.Transactions = append(newBlock.Transactions, GrpcDataTxToDataTx(tx))
 	}
 	return newBlock
 }
 
+// GrpcTableBlockToBlock 区块的转换
+// 有区块头的规范编码时直接由它还原区块头, 没有时(旧的节点)使用各个字段
 func GrpcTableBlockToBlock(block *BcGrpc.TableBlock) *BCTable.Block {
 	newBlock := &BCTable.Block{
 		ID:                int(block.ID),
-		CurrentBlockHash:  block.CurrentBlockHash,
 		PreviousBlockHash: block.PreviousBlockHash,
 		MerKelRoot:        block.MerKelRoot,
 		TimeStamp:         block.TimeTamp,
 	}
+	if len(block.Header) != 0 {
+		header, err := BCTable.DecodeHeader(block.Header)
+		if err != nil {
+			log.Println("区块头解码失败", err)
+		} else {
+			newBlock = header
+		}
+	}
+	newBlock.CurrentBlockHash = block.CurrentBlockHash
 	newBlock.Transactions = []*BCTable.Transaction{}
 	for _, tx := range block.TxInfo {
-		newBlock.Transactions = append(newBlock.Transactions, &BCTable.Transaction{
-			TxID:            tx.TxID,
-			Table:           tx.Table,
-			PermissionTable: tx.PermissionTables,
-			Possessor:       tx.Possessor,
-			TimeStamp:       tx.TimeStamp,
-			PublicKey:       tx.PublicKey,
-			Signature:       tx.Signature,
-		})
+		newBlock.Transactions = append(newBlock.Transactions, GrpcTableTxToTableTx(tx))
 	}
 	return newBlock
 }
 
+// DataTxToGrpcDataTx 交易的转换, 同时带上交易的规范编码
+func DataTxToGrpcDataTx(tx *BCData.Transaction) *BcGrpc.DataTransaction {
+	return &BcGrpc.DataTransaction{
+		TxID:          tx.TxID,
+		DataID:        tx.DataID,
+		Table:         tx.Table,
+		Key:           tx.Key,
+		Value:         util.TextValue(tx.Value),
+		ValueType:     int32(tx.Type),
+		Op:            int32(tx.Op),
+		SchemaVersion: tx.SchemaVersion,
+		Possessor:     tx.Possessor,
+		TimeStamp:     tx.TimeStamp,
+		PublicKey:     tx.PublicKey,
+		Signature:     tx.Signature,
+		Encoded:       tx.Encode(),
+	}
+}
+
+func TableTxToGrpcTableTx(tx *BCTable.Transaction) *BcGrpc.TableTransaction {
+	return &BcGrpc.TableTransaction{
+		TxID:             tx.TxID,
+		Table:            tx.Table,
+		PermissionTables: tx.PermissionTable,
+		Op:               int32(tx.Op),
+		Schema:           tx.Schema,
+		Indexes:          tx.Indexes,
+		Possessor:        tx.Possessor,
+		TimeStamp:        tx.TimeStamp,
+		PublicKey:        tx.PublicKey,
+		Signature:        tx.Signature,
+		Encoded:          tx.Encode(),
+	}
+}
+
 // GrpcDataTxToDataTx 交易的转换
+// 有交易的规范编码时直接由它还原交易, 没有时(旧的节点)使用各个字段
 func GrpcDataTxToDataTx(tx *BcGrpc.DataTransaction) *BCData.Transaction {
+	if len(tx.Encoded) != 0 {
+		newTx, err := BCData.DecodeTransaction(tx.Encoded)
+		if err == nil {
+			return newTx
+		}
+		log.Println("交易解码失败", err)
+	}
 	newTx := &BCData.Transaction{
-		TxID:      tx.TxID,
-		DataID:    tx.DataID,
-		Table:     tx.Table,
-		Key:       tx.Key,
-		Value:     tx.Value,
-		Possessor: tx.Possessor,
-		TimeStamp: tx.TimeStamp,
-		PublicKey: tx.PublicKey,
-		Signature: tx.Signature,
+		TxID:          tx.TxID,
+		DataID:        tx.DataID,
+		Table:         tx.Table,
+		Key:           tx.Key,
+		Value:         tx.Value,
+		Type:          BCData.ValueType(tx.ValueType),
+		Op:            BCData.DataOp(tx.Op),
+		SchemaVersion: tx.SchemaVersion,
+		Possessor:     tx.Possessor,
+		TimeStamp:     tx.TimeStamp,
+		PublicKey:     tx.PublicKey,
+		Signature:     tx.Signature,
 	}
 	return newTx
 }
 
 func GrpcTableTxToTableTx(tx *BcGrpc.TableTransaction) *BCTable.Transaction {
+	if len(tx.Encoded) != 0 {
+		newTx, err := BCTable.DecodeTransaction(tx.Encoded)
+		if err == nil {
+			return newTx
+		}
+		log.Println("交易解码失败", err)
+	}
 	newTx := &BCTable.Transaction{
 		TxID:            tx.TxID,
 		Table:           tx.Table,
 		PermissionTable: tx.PermissionTables,
+		Op:              BCTable.PermissionOp(tx.Op),
+		Schema:          tx.Schema,
+		Indexes:         tx.Indexes,
 		Possessor:       tx.Possessor,
 		TimeStamp:       tx.TimeStamp,
 		PublicKey:       tx.PublicKey,
@@ -145,6 +203,60 @@ func GrpcTableTxToTableTx(tx *BcGrpc.TableTransaction) *BCTable.Transaction {
 	return newTx
 }
 
+// ReportToGrpcReport 审计结果的转换
+func ReportToGrpcReport(report *common.ChainReport) *BcGrpc.ChainReport {
+	newReport := &BcGrpc.ChainReport{
+		Chain:       report.Chain,
+		Blocks:      report.Blocks,
+		FirstBroken: report.FirstBroken,
+	}
+	for _, issue := range report.Issues {
+		newReport.Issues = append(newReport.Issues, &BcGrpc.BlockIssue{
+			Round:  issue.Round,
+			Hash:   issue.Hash,
+			Kind:   issue.Kind,
+			Detail: issue.Detail,
+		})
+	}
+	return newReport
+}
+
+func GrpcReportToReport(report *BcGrpc.ChainReport) *common.ChainReport {
+	newReport := &common.ChainReport{
+		Chain:       report.Chain,
+		Blocks:      report.Blocks,
+		FirstBroken: report.FirstBroken,
+	}
+	for _, issue := range report.Issues {
+		newReport.Issues = append(newReport.Issues, common.BlockIssue{
+			Round:  issue.Round,
+			Hash:   issue.Hash,
+			Kind:   issue.Kind,
+			Detail: issue.Detail,
+		})
+	}
+	return newReport
+}
+
+// VerifyRemoteChain 请求指定节点审计它的区块链, chain 为空时审计两条区块链
+func VerifyRemoteChain(ip string, port int, chain string) ([]*common.ChainReport, error) {
+	conn, err := grpc.Dial(fmt.Sprintf("%s:%d", ip, port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	client := BcGrpc.NewBlockChainServiceClient(conn)
+	re, err := client.VerifyChain(context.Background(), &BcGrpc.ReqVerifyChain{Chain: chain})
+	if err != nil {
+		return nil, err
+	}
+	var reports []*common.ChainReport
+	for _, report := range re.Reports {
+		reports = append(reports, GrpcReportToReport(report))
+	}
+	return reports, nil
+}
+
 // BroadCast 广播自己已加入集群
 func BroadCast(ip string, port int) {
 	// 参数为本节点向哪个节点提交的申请
@@ -163,8 +275,10 @@ func BroadCast(ip string, port int) {
 		c := BcGrpc.NewBlockChainServiceClient(conn)
 		// 通过句柄调用函数
 		_, err = c.BroadcastNode(context.Background(), &BcGrpc.NodeInfo{
-			LocalIp:   node.IP,
-			LocalPort: strconv.Itoa(node.Port),
+			LocalIp:   util.LocalIP,
+			LocalPort: strconv.Itoa(util.LocalPort),
+			RaftPort:  int32(config.LocalConfig.Ports.Raft),
+			FilePort:  int32(config.LocalConfig.Ports.Cluster),
 		})
 		if err != nil {
 			log.Panic(err)
@@ -178,13 +292,13 @@ func JoinToCluster(ip string, port int, key string) error {
 	var node Cluster.Node
 	fmt.Println(ip)
 	// 获得本机的IP和端口号
-	node.IP = util.GetLocalIp()
-	node.Port = port
+	node.IP = util.LocalIP
+	node.Port = util.LocalPort
 	// 获得密钥的hash
 	KeyHash := sha256.Sum256([]byte(key))
 	Key := KeyHash[:]
 	// 提交加入集群的请求(向已加入集群的节点)
-	conn, err := grpc.Dial(fmt.Sprintf("%s:%d", ip, node.Port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(net.JoinHostPort(ip, strconv.Itoa(port)), grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		fmt.Println("Network exception!")
 		log.Panic(err)
@@ -192,30 +306,36 @@ func JoinToCluster(ip string, port int, key string) error {
 	// 获得grpc句柄
 	client := BcGrpc.NewBlockChainServiceClient(conn)
 	// 通过句柄调用函数，验证节点是否可以加入集群
-	_, err = client.JoinCluster(context.Background(), &BcGrpc.ReqJoin{
+	info, err := client.JoinCluster(context.Background(), &BcGrpc.ReqJoin{
 		LocalIp:   node.IP,
 		LocalPort: strconv.Itoa(node.Port),
 		JoinKey:   Key,
+		RaftPort:  int32(config.LocalConfig.Ports.Raft),
+		FilePort:  int32(config.LocalConfig.Ports.Cluster),
+		// 集群会检查创世区块, 不同网络的节点不能加入
+		DataGenesisHash:  BCData.LocalDataBlockChain.GetHashByRound(0),
+		TableGenesisHash: BCTable.LocalTableBlockChain.GetHashByID(1),
 	})
 	if err != nil {
 		log.Panic(err)
 	}
 	// 判断集群文件是否存在
-	_, err = os.Stat("./ClusterInfo")
+	_, err = os.Stat(Cluster.FilePath())
 	if !os.IsNotExist(err) { // 如果没有报错即集群文件存在
 		// 如果存在就先清除集群文件
-		err = os.Remove("./ClusterInfo")
+		err = os.Remove(Cluster.FilePath())
 		if err != nil {
 			log.Panic(err)
 		}
 	}
-	// 接收集群文件
-	flag := Cluster.Client(ip)
+	// 接收集群文件, 旧的节点没有返回端口号时使用默认端口
+	server := Cluster.Node{IP: ip, FilePort: int(info.FilePort)}
+	flag := Cluster.Client(server.FileAddr())
 	if !flag {
 		return errors.New("接收集群文件失败")
 	}
 	// 读取集群文件
-	clu, err := Cluster.LoadClusterFile("./ClusterInfo")
+	clu, err := Cluster.LoadClusterFile(Cluster.FilePath())
 	if err != nil {
 		log.Panic(err)
 	}
@@ -243,17 +363,7 @@ func SubmitDataTransaction(tx BCData.Transaction) {
 		// 获得grpc句柄
 		client := BcGrpc.NewBlockChainServiceClient(conn)
 		// 通过句柄调用函数
-		_, err = client.DataTradingPool(context.Background(), &BcGrpc.DataTransaction{
-			TxID:      tx.TxID,
-			DataID:    tx.DataID,
-			Table:     tx.Table,
-			Key:       tx.Key,
-			Value:     tx.Value,
-			Possessor: tx.Possessor,
-			TimeStamp: tx.TimeStamp,
-			PublicKey: tx.PublicKey,
-			Signature: tx.Signature,
-		})
+		_, err = client.DataTradingPool(context.Background(), DataTxToGrpcDataTx(&tx))
 		if err != nil {
 			log.Panic(err)
 		}
@@ -275,15 +385,7 @@ func SubmitTableTransaction(tx BCTable.Transaction) {
 		// 获得grpc句柄
 		client := BcGrpc.NewBlockChainServiceClient(conn)
 		// 通过句柄调用函数
-		_, err = client.TableTradingPool(context.Background(), &BcGrpc.TableTransaction{
-			TxID:             tx.TxID,
-			Table:            tx.Table,
-			PermissionTables: tx.PermissionTable,
-			Possessor:        tx.Possessor,
-			TimeStamp:        tx.TimeStamp,
-			PublicKey:        tx.PublicKey,
-			Signature:        tx.Signature,
-		})
+		_, err = client.TableTradingPool(context.Background(), TableTxToGrpcTableTx(&tx))
 		if err != nil {
 			log.Panic(err)
 		}
@@ -385,6 +487,16 @@ func DataBlockSynchronization() {
 			for i := 0; i < len(re.Blocks); i++ {
 				// 区块的转换
 				newBlock := GrpcDataBlockToBlock(re.Blocks[len(re.Blocks)-1-i])
+				// 区块头里面的无效交易要和本地计算的一致, 后面的区块也不再同步
+				if !BCData.LocalDataBlockChain.CheckDataBlock(newBlock) {
+					fmt.Println("数据区块同步失败: 区块校验失败")
+					return
+				}
+				// 没有写入权限的写入不能上链, 和接收分发的区块一样 (DistributeDataBlock)
+				if err := cache.LocalCache.CheckBlockWrites(newBlock); err != nil {
+					fmt.Println("数据区块同步失败:", err)
+					return
+				}
 				//上链
 				BCData.LocalDataBlockChain.AddBlockToChain(*newBlock)
 				// 缓存的更新
@@ -425,6 +537,11 @@ func TableBlockSynchronization() {
 			for i := 0; i < len(re.Blocks); i++ {
 				// 区块的转换
 				newBlock := GrpcTableBlockToBlock(re.Blocks[len(re.Blocks)-1-i])
+				// 签名者没有权限的表交易不能上链, 后面的区块也不再同步
+				if !BCTable.LocalTableBlockChain.CheckTableBlock(newBlock, cache.LocalCache.Permissions) {
+					fmt.Println("权限区块同步失败: 区块校验失败")
+					return
+				}
 				//上链
 				BCTable.LocalTableBlockChain.AddBlockToChain(*newBlock)
 				// 缓存的更新
@@ -435,3 +552,82 @@ func TableBlockSynchronization() {
 	}
 	fmt.Println("权限区块同步完成")
 }
+
+// LightSynchronization 轻节点向指定的全节点同步表区块链和数据区块头。
+// 表区块链同步完整的区块; 数据区块链只同步区块头, 并校验区块HASH, 前一个区块HASH, 提议者的签名和抽签证明
+func LightSynchronization(ip string, port int) error {
+	conn, err := grpc.Dial(fmt.Sprintf("%s:%d", ip, port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	client := BcGrpc.NewBlockChainServiceClient(conn)
+
+	// 表区块链
+	tableChain := BCTable.LocalTableBlockChain
+	tableTail, err := tableChain.GetBlockByHash(tableChain.TailHash)
+	if err != nil {
+		return err
+	}
+	re, err := client.TableBlockSynchronization(context.Background(), &BcGrpc.ReqTableBlock{
+		BlockID: int64(tableTail.ID),
+		Hash:    tableTail.CurrentBlockHash,
+	})
+	if err != nil {
+		return err
+	}
+	for i := len(re.Blocks) - 1; i >= 0; i-- {
+		newBlock := GrpcTableBlockToBlock(re.Blocks[i])
+		if !bytes.Equal(newBlock.PreviousBlockHash, tableChain.TailHash) || !tableChain.CheckTableBlock(newBlock, cache.LocalCache.Permissions) {
+			return errors.New("权限区块校验失败")
+		}
+		tableChain.AddBlockToChain(*newBlock)
+		cache.LocalCache.UpdateByTableBlock(*newBlock)
+	}
+
+	// 数据区块头
+	dataChain := BCData.LocalDataBlockChain
+	headers, err := client.DataHeaderSynchronization(context.Background(), &BcGrpc.ReqDataBlock{
+		BlockID: int64(dataChain.LastID - 1),
+		Hash:    dataChain.TailHash,
+	})
+	if err != nil {
+		return err
+	}
+	for _, h := range headers.Blocks {
+		header := GrpcDataBlockToBlock(h)
+		header.Transactions = nil
+		if !bytes.Equal(header.PreviousBlockHash, dataChain.TailHash) {
+			return fmt.Errorf("区块头 #%d 的前一个区块HASH错误", header.Round)
+		}
+		if !bytes.Equal(header.ComputeHash(), header.CurrentBlockHash) {
+			return fmt.Errorf("区块头 #%d 的HASH错误", header.Round)
+		}
+		if err := header.VerifySignature(); err != nil {
+			return fmt.Errorf("区块头 #%d 的签名错误; %v", header.Round, err)
+		}
+		if err := algorand.VerifyProposer(dataChain, header); err != nil {
+			return fmt.Errorf("区块头 #%d 的提议者抽签错误; %v", header.Round, err)
+		}
+		dataChain.AddBlockToChain(*header)
+	}
+	return nil
+}
+
+// DataBodyFetcher 轻节点从指定的全节点按需获取数据区块的区块体
+func DataBodyFetcher(ip string, port int) func(hash []byte) ([]*BCData.Transaction, error) {
+	return func(hash []byte) ([]*BCData.Transaction, error) {
+		conn, err := grpc.Dial(fmt.Sprintf("%s:%d", ip, port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		client := BcGrpc.NewBlockChainServiceClient(conn)
+		re, err := client.GetDataBlockBody(context.Background(), &BcGrpc.ReqBlockBody{Hash: hash})
+		if err != nil {
+			return nil, err
+		}
+		block := GrpcDataBlockToBlock(re)
+		return block.Transactions, nil
+	}
+}
@@ -2,10 +2,21 @@ package MerkleTree
 
 import (
 	"bytes"
-	"crypto/md5"
+	"crypto/sha256"
 	"errors"
 )
 
+// SHA-256 默克尔树, 结构和 RFC 6962 一致:
+// 叶子节点 HASH = sha256(0x00 || 数据), 中间节点 HASH = sha256(0x01 || 左 || 右),
+// 叶子节点和中间节点使用不同的前缀, 不能用中间节点伪造叶子节点(第二原像攻击)。
+// 叶子节点数量不是 2 的幂时不复制最后一个节点, 而是在小于数量的最大的 2 的幂处分成左右两棵子树。
+// 没有叶子节点时根为 sha256("")。
+
+const (
+	leafPrefix byte = 0x00
+	nodePrefix byte = 0x01
+)
+
 //MerkleTree 树
 type MerkleTree struct {
 	Root *MerkleNode //根节点
@@ -16,100 +27,113 @@ type MerkleTree struct {
 type MerkleNode struct {
 	Left  *MerkleNode //左节点
 	Right *MerkleNode //右节点
-	Data  []byte      //节点数据
+	Data  []byte      //节点数据(节点的哈希)
+	Size  uint64      // 子树的叶子节点数量
 }
 
-//GetMerkleRoot 生成默克尔树根节点
-func GetMerkleRoot(data [][]byte) *MerkleTree {
-	//节点
-	var nodes []*MerkleNode
-	//奇数，添加最后一个数，变为偶数
-	if len(data)%2 != 0 {
-		data = append(data, data[len(data)-1])
-	}
-	for _, v := range data {
-		//所有叶子节点
-		hash := md5.Sum(v)
-		node := MerkleNode{nil, nil, hash[:]}
-		nodes = append(nodes, &node)
-		//fmt.Printf("原始数据: %v，hash:%x\n", string(v), node.Data)  // 输出原始数据
-	}
-	nodes = GetMerkleNode(nodes, true)
-	//取根节点返回
-	//hash := []byte(&MerkleTree{nodes[0]})
-	return &MerkleTree{nodes[0], nodes[0].Data}
+// Proof 默克尔证明, 证明第 Index 个叶子节点在 Size 个叶子节点的树里面
+type Proof struct {
+	Index  uint64   // 叶子节点的序号
+	Size   uint64   // 叶子节点的数量
+	Hashes [][]byte // 从叶子节点到根的兄弟节点的哈希
 }
 
-// GetMerkleNode 生成节点
-func GetMerkleNode(merkleNodes []*MerkleNode, isLeaf bool) (returnMerkleNodes []*MerkleNode) {
-	//如果不是叶子，且只有一个节点传入，这是根节点，返回
-	if !isLeaf && len(merkleNodes) == 1 {
-		return merkleNodes
-	}
-	var nodeLen = len(merkleNodes)
-	//判断节点奇偶，奇数复制最后一份
-	var isHaveCopy = false
-	if nodeLen%2 != 0 {
-		copyNode := *merkleNodes[nodeLen-1]
-		merkleNodes = append(merkleNodes, &copyNode)
-		nodeLen = nodeLen + 1
-		isHaveCopy = true
-	}
+// LeafHash 叶子节点的哈希
+func LeafHash(data []byte) []byte {
+	hash := sha256.Sum256(append([]byte{leafPrefix}, data...))
+	return hash[:]
+}
 
-	for i := 0; i < nodeLen; i = i + 2 {
-		data := append(merkleNodes[i].Data, merkleNodes[i+1].Data...)
-		hash := md5.Sum(data)
+// NodeHash 中间节点的哈希
+func NodeHash(left, right []byte) []byte {
+	data := make([]byte, 0, 1+len(left)+len(right))
+	data = append(data, nodePrefix)
+	data = append(data, left...)
+	data = append(data, right...)
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
 
-		//生成新的merkleNode，并加入到返回值
-		node := MerkleNode{merkleNodes[i], merkleNodes[i+1], hash[:]}
-		returnMerkleNodes = append(returnMerkleNodes, &node)
+//GetMerkleRoot 生成默克尔树
+func GetMerkleRoot(data [][]byte) *MerkleTree {
+	if len(data) == 0 {
+		hash := sha256.Sum256([]byte{})
+		return &MerkleTree{nil, hash[:]}
 	}
+	root := GetMerkleNode(data)
+	return &MerkleTree{root, root.Data}
+}
 
-	//处理最后一个,如果是复制前一个的，则子节点置空
-	if isHaveCopy {
-		merkleNodes[nodeLen-1].Right = nil
-		merkleNodes[nodeLen-1].Left = nil
+// GetMerkleNode 生成子树
+func GetMerkleNode(data [][]byte) *MerkleNode {
+	if len(data) == 1 {
+		return &MerkleNode{Data: LeafHash(data[0]), Size: 1}
 	}
-	//递归进行
-	return GetMerkleNode(returnMerkleNodes, false)
+	k := splitPoint(uint64(len(data)))
+	left := GetMerkleNode(data[:k])
+	right := GetMerkleNode(data[k:])
+	return &MerkleNode{left, right, NodeHash(left.Data, right.Data), left.Size + right.Size}
 }
 
-//GetProve 根据节点原始数据返回默克尔证明路径
-func (t *MerkleTree) GetProve(node []byte) (prove [][]byte, err error) {
-	if t == nil {
-		err = errors.New("默克尔树不存在")
-		return
-	}
-	if len(node) == 0 {
-		err = errors.New("原始数据不能为空")
-		return
+// splitPoint 小于 n 的最大的 2 的幂 (n > 1)
+func splitPoint(n uint64) uint64 {
+	k := uint64(1)
+	for k<<1 < n {
+		k <<= 1
 	}
-	nodeHash := md5.Sum(node)
-	hash := nodeHash[:]
-	prove = t.Root.GetNodeProve(&hash, nil)
-	return
+	return k
 }
-func (n *MerkleNode) GetNodeProve(hash *[]byte, prove [][]byte) [][]byte {
-	//相等，说明是原始数据
-	if bytes.Equal(n.Data, *hash) {
-		return append(prove, n.Data)
+
+// GetProof 得到第 index 个叶子节点的默克尔证明
+func (t *MerkleTree) GetProof(index int) (*Proof, error) {
+	if t == nil || t.Root == nil {
+		return nil, errors.New("默克尔树不存在")
 	}
-	prove = append(prove, n.Data)
-	//左节点或者右节点为空，说明是叶子节点
-	if n.Left == nil || n.Right == nil {
-		prove = nil
+	if index < 0 || uint64(index) >= t.Root.Size {
+		return nil, errors.New("叶子节点的序号超出范围")
 	}
-	if l := n.Left; l != nil {
-		p := l.GetNodeProve(hash, prove)
-		if p != nil {
-			return p
+	proof := &Proof{Index: uint64(index), Size: t.Root.Size}
+	// 从根向下走到叶子节点, 记录兄弟节点, 再反转为从下到上
+	node, i := t.Root, uint64(index)
+	for node.Size > 1 {
+		if i < node.Left.Size {
+			proof.Hashes = append(proof.Hashes, node.Right.Data)
+			node = node.Left
+		} else {
+			proof.Hashes = append(proof.Hashes, node.Left.Data)
+			i -= node.Left.Size
+			node = node.Right
 		}
 	}
-	if r := n.Right; r != nil {
-		p := r.GetNodeProve(hash, prove)
-		if p != nil {
-			return p
+	for l, r := 0, len(proof.Hashes)-1; l < r; l, r = l+1, r-1 {
+		proof.Hashes[l], proof.Hashes[r] = proof.Hashes[r], proof.Hashes[l]
+	}
+	return proof, nil
+}
+
+// VerifyProof 校验默克尔证明, leaf 为叶子节点的原始数据。 由 Index 和 Size 确定每个兄弟节点在左边还是右边
+func VerifyProof(root, leaf []byte, proof *Proof) bool {
+	if proof == nil || proof.Index >= proof.Size {
+		return false
+	}
+	fn, sn := proof.Index, proof.Size-1
+	hash := LeafHash(leaf)
+	for _, p := range proof.Hashes {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			hash = NodeHash(p, hash)
+			// 右边的子树没有兄弟节点时, 跳过这些层
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			hash = NodeHash(hash, p)
 		}
+		fn >>= 1
+		sn >>= 1
 	}
-	return nil
+	return sn == 0 && bytes.Equal(hash, root)
 }
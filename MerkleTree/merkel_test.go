@@ -0,0 +1,88 @@
+package MerkleTree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+func leaves(n int) [][]byte {
+	data := make([][]byte, n)
+	for i := range data {
+		data[i] = []byte(fmt.Sprintf("tx-%d", i))
+	}
+	return data
+}
+
+func TestMerkleRoot(t *testing.T) {
+	l := func(i int) []byte { return LeafHash([]byte(fmt.Sprintf("tx-%d", i))) }
+	empty := sha256.Sum256([]byte{})
+	tests := []struct {
+		name string
+		n    int
+		root []byte
+	}{
+		{"没有叶子节点", 0, empty[:]},
+		{"一个叶子节点", 1, l(0)},
+		{"两个叶子节点", 2, NodeHash(l(0), l(1))},
+		// 不复制最后一个节点
+		{"三个叶子节点", 3, NodeHash(NodeHash(l(0), l(1)), l(2))},
+		{"五个叶子节点", 5, NodeHash(NodeHash(NodeHash(l(0), l(1)), NodeHash(l(2), l(3))), l(4))},
+		{"六个叶子节点", 6, NodeHash(NodeHash(NodeHash(l(0), l(1)), NodeHash(l(2), l(3))), NodeHash(l(4), l(5)))},
+	}
+	for _, test := range tests {
+		if root := GetMerkleRoot(leaves(test.n)).Hash; !bytes.Equal(root, test.root) {
+			t.Errorf("%s: root = %x", test.name, root)
+		}
+	}
+}
+
+func TestMerkleProof(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 6, 7, 9, 13} {
+		data := leaves(n)
+		tree := GetMerkleRoot(data)
+		for i := 0; i < n; i++ {
+			proof, err := tree.GetProof(i)
+			if err != nil {
+				t.Fatalf("n=%d i=%d: %v", n, i, err)
+			}
+			if !VerifyProof(tree.Hash, data[i], proof) {
+				t.Errorf("n=%d i=%d: 证明校验失败", n, i)
+			}
+			if VerifyProof(tree.Hash, []byte("other"), proof) {
+				t.Errorf("n=%d i=%d: 错误的叶子节点通过校验", n, i)
+			}
+			if n > 1 {
+				moved := *proof
+				moved.Index = uint64((i + 1) % n)
+				if VerifyProof(tree.Hash, data[i], &moved) {
+					t.Errorf("n=%d i=%d: 错误的序号通过校验", n, i)
+				}
+			}
+			outside := *proof
+			outside.Size = outside.Index
+			if VerifyProof(tree.Hash, data[i], &outside) {
+				t.Errorf("n=%d i=%d: 序号超出叶子节点数量的证明通过校验", n, i)
+			}
+			extra := *proof
+			extra.Hashes = append(append([][]byte{}, proof.Hashes...), tree.Hash)
+			if VerifyProof(tree.Hash, data[i], &extra) {
+				t.Errorf("n=%d i=%d: 多余的哈希通过校验", n, i)
+			}
+			if len(proof.Hashes) > 0 {
+				truncated := *proof
+				truncated.Hashes = proof.Hashes[:len(proof.Hashes)-1]
+				if VerifyProof(tree.Hash, data[i], &truncated) {
+					t.Errorf("n=%d i=%d: 截断的证明通过校验", n, i)
+				}
+			}
+		}
+		if _, err := tree.GetProof(n); err == nil {
+			t.Errorf("n=%d: 超出范围的序号得到了证明", n)
+		}
+	}
+	if _, err := GetMerkleRoot(nil).GetProof(0); err == nil {
+		t.Error("空的树得到了证明")
+	}
+}
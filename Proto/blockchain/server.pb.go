@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.28.0
+// 	protoc-gen-go v1.28.1
 // 	protoc        v3.20.0
 // source: server.proto
 
@@ -39,8 +39,12 @@ type DataTransaction struct {
 	Possessor string `protobuf:"bytes,6,opt,name=Possessor,proto3" json:"Possessor,omitempty"` // 交易所属者
 	TimeStamp int64  `protobuf:"varint,7,opt,name=TimeStamp,proto3" json:"TimeStamp,omitempty"`
 	// 验证信息
-	PublicKey []byte `protobuf:"bytes,8,opt,name=PublicKey,proto3" json:"PublicKey,omitempty"` // 公钥
-	Signature []byte `protobuf:"bytes,9,opt,name=Signature,proto3" json:"Signature,omitempty"` // 签名
+	PublicKey     []byte `protobuf:"bytes,8,opt,name=PublicKey,proto3" json:"PublicKey,omitempty"`           // 公钥
+	Signature     []byte `protobuf:"bytes,9,opt,name=Signature,proto3" json:"Signature,omitempty"`           // 签名
+	Encoded       []byte `protobuf:"bytes,10,opt,name=Encoded,proto3" json:"Encoded,omitempty"`              // 交易的规范编码, 接收方直接由它还原交易
+	Op            int32  `protobuf:"varint,11,opt,name=Op,proto3" json:"Op,omitempty"`                       // 数据操作: 0 写入, 1 删除
+	ValueType     int32  `protobuf:"varint,12,opt,name=ValueType,proto3" json:"ValueType,omitempty"`         // 值的类型: 0 string, 1 bytes, 2 json. bytes 的值不是 UTF-8 时 Value 为空, 使用 Encoded
+	SchemaVersion uint64 `protobuf:"varint,13,opt,name=SchemaVersion,proto3" json:"SchemaVersion,omitempty"` // 写入时检查的表的 schema 的版本
 }
 
 func (x *DataTransaction) Reset() {
@@ -138,6 +142,34 @@ func (x *DataTransaction) GetSignature() []byte {
 	return nil
 }
 
+func (x *DataTransaction) GetEncoded() []byte {
+	if x != nil {
+		return x.Encoded
+	}
+	return nil
+}
+
+func (x *DataTransaction) GetOp() int32 {
+	if x != nil {
+		return x.Op
+	}
+	return 0
+}
+
+func (x *DataTransaction) GetValueType() int32 {
+	if x != nil {
+		return x.ValueType
+	}
+	return 0
+}
+
+func (x *DataTransaction) GetSchemaVersion() uint64 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
 // 表 (交易)
 type TableTransaction struct {
 	state         protoimpl.MessageState
@@ -150,8 +182,12 @@ type TableTransaction struct {
 	Possessor        string   `protobuf:"bytes,4,opt,name=Possessor,proto3" json:"Possessor,omitempty"`
 	TimeStamp        int64    `protobuf:"varint,5,opt,name=TimeStamp,proto3" json:"TimeStamp,omitempty"`
 	// 验证信息
-	PublicKey []byte `protobuf:"bytes,6,opt,name=PublicKey,proto3" json:"PublicKey,omitempty"`
-	Signature []byte `protobuf:"bytes,7,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	PublicKey []byte   `protobuf:"bytes,6,opt,name=PublicKey,proto3" json:"PublicKey,omitempty"`
+	Signature []byte   `protobuf:"bytes,7,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	Encoded   []byte   `protobuf:"bytes,8,opt,name=Encoded,proto3" json:"Encoded,omitempty"`  // 交易的规范编码, 接收方直接由它还原交易
+	Op        int32    `protobuf:"varint,9,opt,name=Op,proto3" json:"Op,omitempty"`           // 对权限表的操作: 0 授予, 1 移除, 2 替换, 3 声明 schema, 4 声明索引
+	Schema    string   `protobuf:"bytes,10,opt,name=Schema,proto3" json:"Schema,omitempty"`   // Op 为 3 时表的 schema 的 JSON
+	Indexes   []string `protobuf:"bytes,11,rep,name=Indexes,proto3" json:"Indexes,omitempty"` // Op 为 4 时表的所有索引字段
 }
 
 func (x *TableTransaction) Reset() {
@@ -235,6 +271,34 @@ func (x *TableTransaction) GetSignature() []byte {
 	return nil
 }
 
+func (x *TableTransaction) GetEncoded() []byte {
+	if x != nil {
+		return x.Encoded
+	}
+	return nil
+}
+
+func (x *TableTransaction) GetOp() int32 {
+	if x != nil {
+		return x.Op
+	}
+	return 0
+}
+
+func (x *TableTransaction) GetSchema() string {
+	if x != nil {
+		return x.Schema
+	}
+	return ""
+}
+
+func (x *TableTransaction) GetIndexes() []string {
+	if x != nil {
+		return x.Indexes
+	}
+	return nil
+}
+
 // 数据交易
 type DataTransactions struct {
 	state         protoimpl.MessageState
@@ -343,6 +407,12 @@ type DataBlock struct {
 	MerKelRoot        []byte             `protobuf:"bytes,4,opt,name=MerKelRoot,proto3" json:"MerKelRoot,omitempty"`               //MerKelRoot MerKelRoot
 	TxInfo            []*DataTransaction `protobuf:"bytes,5,rep,name=TxInfo,proto3" json:"TxInfo,omitempty"`                       //区块的所有交易
 	TimeTamp          uint64             `protobuf:"varint,6,opt,name=TimeTamp,proto3" json:"TimeTamp,omitempty"`                  //时间戳
+	Seed              []byte             `protobuf:"bytes,7,opt,name=Seed,proto3" json:"Seed,omitempty"`                           //区块的种子
+	Author            []byte             `protobuf:"bytes,8,opt,name=Author,proto3" json:"Author,omitempty"`                       //区块提议者的地址
+	Proof             []byte             `protobuf:"bytes,9,opt,name=Proof,proto3" json:"Proof,omitempty"`                         //VRF 证明
+	Type              int32              `protobuf:"varint,10,opt,name=Type,proto3" json:"Type,omitempty"`                         //区块的类型
+	Signature         []byte             `protobuf:"bytes,11,opt,name=Signature,proto3" json:"Signature,omitempty"`                //提议者的签名
+	Header            []byte             `protobuf:"bytes,12,opt,name=Header,proto3" json:"Header,omitempty"`                      //区块头的规范编码, 区块的 HASH = sha256(Header)
 }
 
 func (x *DataBlock) Reset() {
@@ -419,6 +489,48 @@ func (x *DataBlock) GetTimeTamp() uint64 {
 	return 0
 }
 
+func (x *DataBlock) GetSeed() []byte {
+	if x != nil {
+		return x.Seed
+	}
+	return nil
+}
+
+func (x *DataBlock) GetAuthor() []byte {
+	if x != nil {
+		return x.Author
+	}
+	return nil
+}
+
+func (x *DataBlock) GetProof() []byte {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *DataBlock) GetType() int32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *DataBlock) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *DataBlock) GetHeader() []byte {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
 // 表区块
 type TableBlock struct {
 	state         protoimpl.MessageState
@@ -431,6 +543,7 @@ type TableBlock struct {
 	MerKelRoot        []byte              `protobuf:"bytes,4,opt,name=MerKelRoot,proto3" json:"MerKelRoot,omitempty"`               //MerKelRoot MerKelRoot
 	TxInfo            []*TableTransaction `protobuf:"bytes,5,rep,name=TxInfo,proto3" json:"TxInfo,omitempty"`                       //区块的所有交易
 	TimeTamp          uint64              `protobuf:"varint,6,opt,name=TimeTamp,proto3" json:"TimeTamp,omitempty"`                  //时间戳
+	Header            []byte              `protobuf:"bytes,7,opt,name=Header,proto3" json:"Header,omitempty"`                       //区块头的规范编码, 区块的 HASH = sha256(Header)
 }
 
 func (x *TableBlock) Reset() {
@@ -507,6 +620,13 @@ func (x *TableBlock) GetTimeTamp() uint64 {
 	return 0
 }
 
+func (x *TableBlock) GetHeader() []byte {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
 type ReqDataBlock struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -609,6 +729,53 @@ func (x *ResDataBlocks) GetBlocks() []*DataBlock {
 	return nil
 }
 
+type ReqBlockBody struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash []byte `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"` // 区块的哈希
+}
+
+func (x *ReqBlockBody) Reset() {
+	*x = ReqBlockBody{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReqBlockBody) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReqBlockBody) ProtoMessage() {}
+
+func (x *ReqBlockBody) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReqBlockBody.ProtoReflect.Descriptor instead.
+func (*ReqBlockBody) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReqBlockBody) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
 type ReqTableBlock struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -621,7 +788,7 @@ type ReqTableBlock struct {
 func (x *ReqTableBlock) Reset() {
 	*x = ReqTableBlock{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_server_proto_msgTypes[8]
+		mi := &file_server_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -634,7 +801,7 @@ func (x *ReqTableBlock) String() string {
 func (*ReqTableBlock) ProtoMessage() {}
 
 func (x *ReqTableBlock) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[8]
+	mi := &file_server_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -647,7 +814,7 @@ func (x *ReqTableBlock) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReqTableBlock.ProtoReflect.Descriptor instead.
 func (*ReqTableBlock) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{8}
+	return file_server_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ReqTableBlock) GetHash() []byte {
@@ -675,7 +842,7 @@ type ResTableBlocks struct {
 func (x *ResTableBlocks) Reset() {
 	*x = ResTableBlocks{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_server_proto_msgTypes[9]
+		mi := &file_server_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -688,7 +855,7 @@ func (x *ResTableBlocks) String() string {
 func (*ResTableBlocks) ProtoMessage() {}
 
 func (x *ResTableBlocks) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[9]
+	mi := &file_server_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -701,7 +868,7 @@ func (x *ResTableBlocks) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResTableBlocks.ProtoReflect.Descriptor instead.
 func (*ResTableBlocks) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{9}
+	return file_server_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ResTableBlocks) GetBlocks() []*TableBlock {
@@ -717,14 +884,15 @@ type VerifyInfo struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Status bool   `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"` //状态
-	Info   string `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`      // 验证信息
+	Status   bool   `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`     //状态
+	Info     string `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`          // 验证信息
+	FilePort int32  `protobuf:"varint,3,opt,name=FilePort,proto3" json:"FilePort,omitempty"` // 加入集群时返回发送集群文件的端口号
 }
 
 func (x *VerifyInfo) Reset() {
 	*x = VerifyInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_server_proto_msgTypes[10]
+		mi := &file_server_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -737,7 +905,7 @@ func (x *VerifyInfo) String() string {
 func (*VerifyInfo) ProtoMessage() {}
 
 func (x *VerifyInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[10]
+	mi := &file_server_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -750,7 +918,7 @@ func (x *VerifyInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VerifyInfo.ProtoReflect.Descriptor instead.
 func (*VerifyInfo) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{10}
+	return file_server_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *VerifyInfo) GetStatus() bool {
@@ -767,6 +935,13 @@ func (x *VerifyInfo) GetInfo() string {
 	return ""
 }
 
+func (x *VerifyInfo) GetFilePort() int32 {
+	if x != nil {
+		return x.FilePort
+	}
+	return 0
+}
+
 type NodeInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -774,12 +949,14 @@ type NodeInfo struct {
 
 	LocalIp   string `protobuf:"bytes,1,opt,name=LocalIp,proto3" json:"LocalIp,omitempty"`     // ip号
 	LocalPort string `protobuf:"bytes,2,opt,name=LocalPort,proto3" json:"LocalPort,omitempty"` // 端口号
+	RaftPort  int32  `protobuf:"varint,3,opt,name=RaftPort,proto3" json:"RaftPort,omitempty"`  // Raft 的端口号
+	FilePort  int32  `protobuf:"varint,4,opt,name=FilePort,proto3" json:"FilePort,omitempty"`  // 发送集群文件的端口号
 }
 
 func (x *NodeInfo) Reset() {
 	*x = NodeInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_server_proto_msgTypes[11]
+		mi := &file_server_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -792,7 +969,7 @@ func (x *NodeInfo) String() string {
 func (*NodeInfo) ProtoMessage() {}
 
 func (x *NodeInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[11]
+	mi := &file_server_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -805,7 +982,7 @@ func (x *NodeInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeInfo.ProtoReflect.Descriptor instead.
 func (*NodeInfo) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{11}
+	return file_server_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *NodeInfo) GetLocalIp() string {
@@ -822,6 +999,20 @@ func (x *NodeInfo) GetLocalPort() string {
 	return ""
 }
 
+func (x *NodeInfo) GetRaftPort() int32 {
+	if x != nil {
+		return x.RaftPort
+	}
+	return 0
+}
+
+func (x *NodeInfo) GetFilePort() int32 {
+	if x != nil {
+		return x.FilePort
+	}
+	return 0
+}
+
 type Nodes struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -833,7 +1024,7 @@ type Nodes struct {
 func (x *Nodes) Reset() {
 	*x = Nodes{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_server_proto_msgTypes[12]
+		mi := &file_server_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -846,7 +1037,7 @@ func (x *Nodes) String() string {
 func (*Nodes) ProtoMessage() {}
 
 func (x *Nodes) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[12]
+	mi := &file_server_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -859,7 +1050,7 @@ func (x *Nodes) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Nodes.ProtoReflect.Descriptor instead.
 func (*Nodes) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{12}
+	return file_server_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *Nodes) GetNodes() []*NodeInfo {
@@ -882,7 +1073,7 @@ type Heartbeat struct {
 func (x *Heartbeat) Reset() {
 	*x = Heartbeat{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_server_proto_msgTypes[13]
+		mi := &file_server_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -895,7 +1086,7 @@ func (x *Heartbeat) String() string {
 func (*Heartbeat) ProtoMessage() {}
 
 func (x *Heartbeat) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[13]
+	mi := &file_server_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -908,7 +1099,7 @@ func (x *Heartbeat) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
 func (*Heartbeat) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{13}
+	return file_server_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *Heartbeat) GetIsAccountant() bool {
@@ -943,7 +1134,7 @@ type TableName struct {
 func (x *TableName) Reset() {
 	*x = TableName{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_server_proto_msgTypes[14]
+		mi := &file_server_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -956,7 +1147,7 @@ func (x *TableName) String() string {
 func (*TableName) ProtoMessage() {}
 
 func (x *TableName) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[14]
+	mi := &file_server_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -969,7 +1160,7 @@ func (x *TableName) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TableName.ProtoReflect.Descriptor instead.
 func (*TableName) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{14}
+	return file_server_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *TableName) GetName() string {
@@ -985,15 +1176,19 @@ type ReqJoin struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	LocalIp   string `protobuf:"bytes,1,opt,name=localIp,proto3" json:"localIp,omitempty"`     // ip
-	LocalPort string `protobuf:"bytes,2,opt,name=localPort,proto3" json:"localPort,omitempty"` // 端口号
-	JoinKey   []byte `protobuf:"bytes,3,opt,name=JoinKey,proto3" json:"JoinKey,omitempty"`     // 加入集群的密钥
+	LocalIp          string `protobuf:"bytes,1,opt,name=localIp,proto3" json:"localIp,omitempty"`                   // ip
+	LocalPort        string `protobuf:"bytes,2,opt,name=localPort,proto3" json:"localPort,omitempty"`               // 端口号
+	JoinKey          []byte `protobuf:"bytes,3,opt,name=JoinKey,proto3" json:"JoinKey,omitempty"`                   // 加入集群的密钥
+	RaftPort         int32  `protobuf:"varint,4,opt,name=RaftPort,proto3" json:"RaftPort,omitempty"`                // Raft 的端口号
+	FilePort         int32  `protobuf:"varint,5,opt,name=FilePort,proto3" json:"FilePort,omitempty"`                // 发送集群文件的端口号
+	DataGenesisHash  []byte `protobuf:"bytes,6,opt,name=DataGenesisHash,proto3" json:"DataGenesisHash,omitempty"`   // 数据区块链创世区块的 HASH, 和集群不一致时不能加入
+	TableGenesisHash []byte `protobuf:"bytes,7,opt,name=TableGenesisHash,proto3" json:"TableGenesisHash,omitempty"` // 表区块链创世区块的 HASH
 }
 
 func (x *ReqJoin) Reset() {
 	*x = ReqJoin{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_server_proto_msgTypes[15]
+		mi := &file_server_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1006,7 +1201,7 @@ func (x *ReqJoin) String() string {
 func (*ReqJoin) ProtoMessage() {}
 
 func (x *ReqJoin) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[15]
+	mi := &file_server_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1019,7 +1214,7 @@ func (x *ReqJoin) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReqJoin.ProtoReflect.Descriptor instead.
 func (*ReqJoin) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{15}
+	return file_server_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *ReqJoin) GetLocalIp() string {
@@ -1043,6 +1238,34 @@ func (x *ReqJoin) GetJoinKey() []byte {
 	return nil
 }
 
+func (x *ReqJoin) GetRaftPort() int32 {
+	if x != nil {
+		return x.RaftPort
+	}
+	return 0
+}
+
+func (x *ReqJoin) GetFilePort() int32 {
+	if x != nil {
+		return x.FilePort
+	}
+	return 0
+}
+
+func (x *ReqJoin) GetDataGenesisHash() []byte {
+	if x != nil {
+		return x.DataGenesisHash
+	}
+	return nil
+}
+
+func (x *ReqJoin) GetTableGenesisHash() []byte {
+	if x != nil {
+		return x.TableGenesisHash
+	}
+	return nil
+}
+
 // 类型和数据
 type TypAndData struct {
 	state         protoimpl.MessageState
@@ -1056,7 +1279,7 @@ type TypAndData struct {
 func (x *TypAndData) Reset() {
 	*x = TypAndData{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_server_proto_msgTypes[16]
+		mi := &file_server_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1069,7 +1292,7 @@ func (x *TypAndData) String() string {
 func (*TypAndData) ProtoMessage() {}
 
 func (x *TypAndData) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[16]
+	mi := &file_server_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1082,7 +1305,7 @@ func (x *TypAndData) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TypAndData.ProtoReflect.Descriptor instead.
 func (*TypAndData) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{16}
+	return file_server_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *TypAndData) GetTyp() int32 {
@@ -1112,7 +1335,7 @@ type Info struct {
 func (x *Info) Reset() {
 	*x = Info{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_server_proto_msgTypes[17]
+		mi := &file_server_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1125,7 +1348,7 @@ func (x *Info) String() string {
 func (*Info) ProtoMessage() {}
 
 func (x *Info) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_msgTypes[17]
+	mi := &file_server_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1138,7 +1361,7 @@ func (x *Info) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Info.ProtoReflect.Descriptor instead.
 func (*Info) Descriptor() ([]byte, []int) {
-	return file_server_proto_rawDescGZIP(), []int{17}
+	return file_server_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *Info) GetStatus() bool {
@@ -1155,245 +1378,698 @@ func (x *Info) GetInfo() string {
 	return ""
 }
 
-var File_server_proto protoreflect.FileDescriptor
+// 审计区块链的请求
+type ReqVerifyChain struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_server_proto_rawDesc = []byte{
-	0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e,
-	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x22, 0xf3,
-	0x01, 0x0a, 0x0f, 0x44, 0x61, 0x74, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
-	0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x04, 0x54, 0x78, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x44, 0x61, 0x74, 0x61, 0x49, 0x44,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x44, 0x61, 0x74, 0x61, 0x49, 0x44, 0x12, 0x14,
-	0x0a, 0x05, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x54,
-	0x61, 0x62, 0x6c, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x4b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x03, 0x4b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1c, 0x0a, 0x09,
-	0x50, 0x6f, 0x73, 0x73, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x09, 0x50, 0x6f, 0x73, 0x73, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x54, 0x69,
-	0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x54,
-	0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x50, 0x75, 0x62, 0x6c,
-	0x69, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x50, 0x75, 0x62,
-	0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
-	0x75, 0x72, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61,
-	0x74, 0x75, 0x72, 0x65, 0x22, 0xe0, 0x01, 0x0a, 0x10, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x72,
-	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x78, 0x49,
-	0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x54, 0x78, 0x49, 0x44, 0x12, 0x14, 0x0a,
-	0x05, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x54, 0x61,
-	0x62, 0x6c, 0x65, 0x12, 0x2a, 0x0a, 0x10, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f,
-	0x6e, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x50,
-	0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x12,
-	0x1c, 0x0a, 0x09, 0x50, 0x6f, 0x73, 0x73, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x09, 0x50, 0x6f, 0x73, 0x73, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x12, 0x1c, 0x0a,
-	0x09, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03,
-	0x52, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x50,
-	0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
-	0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x69, 0x67,
-	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x53, 0x69,
-	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x57, 0x0a, 0x10, 0x44, 0x61, 0x74, 0x61, 0x54,
-	0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x43, 0x0a, 0x0c, 0x54,
-	0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x1f, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72,
-	0x70, 0x63, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
-	0x6f, 0x6e, 0x52, 0x0c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
-	0x22, 0x59, 0x0a, 0x11, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
-	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x44, 0x0a, 0x0c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
-	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x62, 0x6c,
-	0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x61, 0x62,
-	0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x54,
-	0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xea, 0x01, 0x0a, 0x09,
-	0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x49, 0x44, 0x12, 0x2a, 0x0a, 0x10, 0x43, 0x75, 0x72,
-	0x72, 0x65, 0x6e, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x10, 0x43, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x42, 0x6c, 0x6f, 0x63,
-	0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x2c, 0x0a, 0x11, 0x50, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75,
-	0x73, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x11, 0x50, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48,
-	0x61, 0x73, 0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x4d, 0x65, 0x72, 0x4b, 0x65, 0x6c, 0x52, 0x6f, 0x6f,
-	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x4d, 0x65, 0x72, 0x4b, 0x65, 0x6c, 0x52,
-	0x6f, 0x6f, 0x74, 0x12, 0x37, 0x0a, 0x06, 0x54, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x05, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e,
-	0x47, 0x72, 0x70, 0x63, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
-	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x54, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1a, 0x0a, 0x08,
-	0x54, 0x69, 0x6d, 0x65, 0x54, 0x61, 0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08,
-	0x54, 0x69, 0x6d, 0x65, 0x54, 0x61, 0x6d, 0x70, 0x22, 0xec, 0x01, 0x0a, 0x0a, 0x54, 0x61, 0x62,
-	0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x02, 0x49, 0x44, 0x12, 0x2a, 0x0a, 0x10, 0x43, 0x75, 0x72, 0x72, 0x65,
-	0x6e, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x10, 0x43, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48,
-	0x61, 0x73, 0x68, 0x12, 0x2c, 0x0a, 0x11, 0x50, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x42,
-	0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x11,
-	0x50, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73,
-	0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x4d, 0x65, 0x72, 0x4b, 0x65, 0x6c, 0x52, 0x6f, 0x6f, 0x74, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x4d, 0x65, 0x72, 0x4b, 0x65, 0x6c, 0x52, 0x6f, 0x6f,
-	0x74, 0x12, 0x38, 0x0a, 0x06, 0x54, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x05, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x20, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72,
-	0x70, 0x63, 0x2e, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
-	0x69, 0x6f, 0x6e, 0x52, 0x06, 0x54, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x54,
-	0x69, 0x6d, 0x65, 0x54, 0x61, 0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x54,
-	0x69, 0x6d, 0x65, 0x54, 0x61, 0x6d, 0x70, 0x22, 0x3c, 0x0a, 0x0c, 0x52, 0x65, 0x71, 0x44, 0x61,
-	0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x42,
-	0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x42, 0x6c,
-	0x6f, 0x63, 0x6b, 0x49, 0x44, 0x22, 0x42, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x44, 0x61, 0x74, 0x61,
-	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x31, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
-	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68,
-	0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63,
-	0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22, 0x3d, 0x0a, 0x0d, 0x52, 0x65, 0x71,
-	0x54, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61,
-	0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x12, 0x18,
-	0x0a, 0x07, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x07, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x44, 0x22, 0x44, 0x0a, 0x0e, 0x52, 0x65, 0x73, 0x54,
-	0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x32, 0x0a, 0x06, 0x62, 0x6c,
-	0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x62, 0x6c, 0x6f,
-	0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x61, 0x62, 0x6c,
-	0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22, 0x38,
-	0x0a, 0x0a, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x16, 0x0a, 0x06,
-	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x22, 0x42, 0x0a, 0x08, 0x4e, 0x6f, 0x64, 0x65,
-	0x49, 0x6e, 0x66, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x49, 0x70, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x49, 0x70, 0x12, 0x1c,
-	0x0a, 0x09, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x09, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x6f, 0x72, 0x74, 0x22, 0x37, 0x0a, 0x05,
-	0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x2e, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69,
-	0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05,
-	0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x67, 0x0a, 0x09, 0x48, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65,
-	0x61, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x49, 0x73, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x61,
-	0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x49, 0x73, 0x41, 0x63, 0x63, 0x6f,
-	0x75, 0x6e, 0x74, 0x61, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x49,
-	0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x49, 0x70,
-	0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x6f, 0x72, 0x74, 0x22, 0x1f,
-	0x0a, 0x09, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e,
-	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22,
-	0x5b, 0x0a, 0x07, 0x52, 0x65, 0x71, 0x4a, 0x6f, 0x69, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f,
-	0x63, 0x61, 0x6c, 0x49, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f, 0x63,
-	0x61, 0x6c, 0x49, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x6f, 0x72,
-	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x6f,
-	0x72, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x4a, 0x6f, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x07, 0x4a, 0x6f, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x22, 0x32, 0x0a, 0x0a,
-	0x54, 0x79, 0x70, 0x41, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x61, 0x12, 0x10, 0x0a, 0x03, 0x54, 0x79,
-	0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x54, 0x79, 0x70, 0x12, 0x12, 0x0a, 0x04,
-	0x44, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x44, 0x61, 0x74, 0x61,
-	0x22, 0x32, 0x0a, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x12, 0x12, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x69, 0x6e, 0x66, 0x6f, 0x32, 0xe1, 0x05, 0x0a, 0x11, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68,
-	0x61, 0x69, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4e, 0x0a, 0x13, 0x44, 0x69,
-	0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63,
-	0x6b, 0x12, 0x19, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72,
-	0x70, 0x63, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x1a, 0x1a, 0x2e, 0x62,
-	0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x56, 0x65,
-	0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00, 0x12, 0x59, 0x0a, 0x18, 0x44, 0x61,
-	0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69,
-	0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68,
-	0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x52, 0x65, 0x71, 0x44, 0x61, 0x74, 0x61, 0x42,
-	0x6c, 0x6f, 0x63, 0x6b, 0x1a, 0x1d, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69,
-	0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x52, 0x65, 0x73, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f,
-	0x63, 0x6b, 0x73, 0x22, 0x00, 0x12, 0x50, 0x0a, 0x14, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62,
-	0x75, 0x74, 0x65, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1a, 0x2e,
-	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x54,
-	0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x1a, 0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63,
-	0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66,
-	0x79, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00, 0x12, 0x5c, 0x0a, 0x19, 0x54, 0x61, 0x62, 0x6c, 0x65,
-	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69,
-	0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x52, 0x65, 0x71, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c,
-	0x6f, 0x63, 0x6b, 0x1a, 0x1e, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e,
-	0x47, 0x72, 0x70, 0x63, 0x2e, 0x52, 0x65, 0x73, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f,
-	0x63, 0x6b, 0x73, 0x22, 0x00, 0x12, 0x50, 0x0a, 0x0f, 0x44, 0x61, 0x74, 0x61, 0x54, 0x72, 0x61,
-	0x64, 0x69, 0x6e, 0x67, 0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x1f, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
-	0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x54, 0x72,
-	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63,
-	0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66,
-	0x79, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00, 0x12, 0x52, 0x0a, 0x10, 0x54, 0x61, 0x62, 0x6c, 0x65,
-	0x54, 0x72, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x20, 0x2e, 0x62, 0x6c,
-	0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x61, 0x62,
-	0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x1a, 0x2e,
-	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x56,
-	0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00, 0x12, 0x44, 0x0a, 0x0b, 0x4a,
-	0x6f, 0x69, 0x6e, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x12, 0x17, 0x2e, 0x62, 0x6c, 0x6f,
-	0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x52, 0x65, 0x71, 0x4a,
-	0x6f, 0x69, 0x6e, 0x1a, 0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e,
-	0x47, 0x72, 0x70, 0x63, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x22,
-	0x00, 0x12, 0x47, 0x0a, 0x0d, 0x42, 0x72, 0x6f, 0x61, 0x64, 0x63, 0x61, 0x73, 0x74, 0x4e, 0x6f,
-	0x64, 0x65, 0x12, 0x18, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47,
-	0x72, 0x70, 0x63, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x1a, 0x1a, 0x2e, 0x62,
-	0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x56, 0x65,
-	0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00, 0x12, 0x3c, 0x0a, 0x06, 0x48, 0x61,
-	0x6e, 0x64, 0x6c, 0x65, 0x12, 0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69,
-	0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x79, 0x70, 0x41, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x61,
-	0x1a, 0x14, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70,
-	0x63, 0x2e, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00, 0x42, 0x11, 0x50, 0x01, 0x5a, 0x0d, 0x2e, 0x2f,
-	0x3b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	Chain string `protobuf:"bytes,1,opt,name=Chain,proto3" json:"Chain,omitempty"` // data 或者 table, 为空时审计两条区块链
 }
 
-var (
-	file_server_proto_rawDescOnce sync.Once
-	file_server_proto_rawDescData = file_server_proto_rawDesc
-)
-
-func file_server_proto_rawDescGZIP() []byte {
-	file_server_proto_rawDescOnce.Do(func() {
-		file_server_proto_rawDescData = protoimpl.X.CompressGZIP(file_server_proto_rawDescData)
-	})
-	return file_server_proto_rawDescData
+func (x *ReqVerifyChain) Reset() {
+	*x = ReqVerifyChain{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-var file_server_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
-var file_server_proto_goTypes = []interface{}{
-	(*DataTransaction)(nil),   // 0: blockChainGrpc.DataTransaction
-	(*TableTransaction)(nil),  // 1: blockChainGrpc.TableTransaction
-	(*DataTransactions)(nil),  // 2: blockChainGrpc.DataTransactions
-	(*TableTransactions)(nil), // 3: blockChainGrpc.TableTransactions
-	(*DataBlock)(nil),         // 4: blockChainGrpc.DataBlock
-	(*TableBlock)(nil),        // 5: blockChainGrpc.TableBlock
-	(*ReqDataBlock)(nil),      // 6: blockChainGrpc.ReqDataBlock
-	(*ResDataBlocks)(nil),     // 7: blockChainGrpc.ResDataBlocks
-	(*ReqTableBlock)(nil),     // 8: blockChainGrpc.ReqTableBlock
-	(*ResTableBlocks)(nil),    // 9: blockChainGrpc.ResTableBlocks
-	(*VerifyInfo)(nil),        // 10: blockChainGrpc.VerifyInfo
-	(*NodeInfo)(nil),          // 11: blockChainGrpc.NodeInfo
-	(*Nodes)(nil),             // 12: blockChainGrpc.Nodes
-	(*Heartbeat)(nil),         // 13: blockChainGrpc.Heartbeat
-	(*TableName)(nil),         // 14: blockChainGrpc.TableName
-	(*ReqJoin)(nil),           // 15: blockChainGrpc.ReqJoin
-	(*TypAndData)(nil),        // 16: blockChainGrpc.TypAndData
-	(*Info)(nil),              // 17: blockChainGrpc.Info
+func (x *ReqVerifyChain) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
-var file_server_proto_depIdxs = []int32{
-	0,  // 0: blockChainGrpc.DataTransactions.Transactions:type_name -> blockChainGrpc.DataTransaction
-	1,  // 1: blockChainGrpc.TableTransactions.Transactions:type_name -> blockChainGrpc.TableTransaction
+
+func (*ReqVerifyChain) ProtoMessage() {}
+
+func (x *ReqVerifyChain) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReqVerifyChain.ProtoReflect.Descriptor instead.
+func (*ReqVerifyChain) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ReqVerifyChain) GetChain() string {
+	if x != nil {
+		return x.Chain
+	}
+	return ""
+}
+
+// 审计发现的问题
+type BlockIssue struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round  uint64 `protobuf:"varint,1,opt,name=Round,proto3" json:"Round,omitempty"` // 区块的 Round (表区块为 ID)
+	Hash   []byte `protobuf:"bytes,2,opt,name=Hash,proto3" json:"Hash,omitempty"`    // 区块的 HASH
+	Kind   string `protobuf:"bytes,3,opt,name=Kind,proto3" json:"Kind,omitempty"`    // 问题的类型
+	Detail string `protobuf:"bytes,4,opt,name=Detail,proto3" json:"Detail,omitempty"`
+}
+
+func (x *BlockIssue) Reset() {
+	*x = BlockIssue{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlockIssue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockIssue) ProtoMessage() {}
+
+func (x *BlockIssue) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockIssue.ProtoReflect.Descriptor instead.
+func (*BlockIssue) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *BlockIssue) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *BlockIssue) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *BlockIssue) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *BlockIssue) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+// 一条区块链的审计结果
+type ChainReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Chain       string        `protobuf:"bytes,1,opt,name=Chain,proto3" json:"Chain,omitempty"`
+	Blocks      uint64        `protobuf:"varint,2,opt,name=Blocks,proto3" json:"Blocks,omitempty"`           // 检查过的区块数量
+	FirstBroken int64         `protobuf:"varint,3,opt,name=FirstBroken,proto3" json:"FirstBroken,omitempty"` // 第一个出错的区块, 没有错误时为 -1
+	Issues      []*BlockIssue `protobuf:"bytes,4,rep,name=Issues,proto3" json:"Issues,omitempty"`
+}
+
+func (x *ChainReport) Reset() {
+	*x = ChainReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChainReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChainReport) ProtoMessage() {}
+
+func (x *ChainReport) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChainReport.ProtoReflect.Descriptor instead.
+func (*ChainReport) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ChainReport) GetChain() string {
+	if x != nil {
+		return x.Chain
+	}
+	return ""
+}
+
+func (x *ChainReport) GetBlocks() uint64 {
+	if x != nil {
+		return x.Blocks
+	}
+	return 0
+}
+
+func (x *ChainReport) GetFirstBroken() int64 {
+	if x != nil {
+		return x.FirstBroken
+	}
+	return 0
+}
+
+func (x *ChainReport) GetIssues() []*BlockIssue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+type ResVerifyChain struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reports []*ChainReport `protobuf:"bytes,1,rep,name=Reports,proto3" json:"Reports,omitempty"`
+}
+
+func (x *ResVerifyChain) Reset() {
+	*x = ResVerifyChain{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResVerifyChain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResVerifyChain) ProtoMessage() {}
+
+func (x *ResVerifyChain) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResVerifyChain.ProtoReflect.Descriptor instead.
+func (*ResVerifyChain) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ResVerifyChain) GetReports() []*ChainReport {
+	if x != nil {
+		return x.Reports
+	}
+	return nil
+}
+
+// 区块链归档文件的清单, 写在归档文件的第一个记录
+type ArchiveManifest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version          uint32 `protobuf:"varint,1,opt,name=Version,proto3" json:"Version,omitempty"`                  // 归档格式的版本
+	DataGenesisHash  []byte `protobuf:"bytes,2,opt,name=DataGenesisHash,proto3" json:"DataGenesisHash,omitempty"`   // 数据区块链创世区块的 HASH
+	TableGenesisHash []byte `protobuf:"bytes,3,opt,name=TableGenesisHash,proto3" json:"TableGenesisHash,omitempty"` // 表区块链创世区块的 HASH
+	DataTailHash     []byte `protobuf:"bytes,4,opt,name=DataTailHash,proto3" json:"DataTailHash,omitempty"`         // 数据区块链链尾区块的 HASH
+	TableTailHash    []byte `protobuf:"bytes,5,opt,name=TableTailHash,proto3" json:"TableTailHash,omitempty"`       // 表区块链链尾区块的 HASH
+	DataBlocks       uint64 `protobuf:"varint,6,opt,name=DataBlocks,proto3" json:"DataBlocks,omitempty"`            // 数据区块的数量(包括创世区块)
+	TableBlocks      uint64 `protobuf:"varint,7,opt,name=TableBlocks,proto3" json:"TableBlocks,omitempty"`          // 表区块的数量(包括创世区块)
+	TimeStamp        int64  `protobuf:"varint,8,opt,name=TimeStamp,proto3" json:"TimeStamp,omitempty"`              // 导出的时间
+}
+
+func (x *ArchiveManifest) Reset() {
+	*x = ArchiveManifest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_server_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArchiveManifest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveManifest) ProtoMessage() {}
+
+func (x *ArchiveManifest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveManifest.ProtoReflect.Descriptor instead.
+func (*ArchiveManifest) Descriptor() ([]byte, []int) {
+	return file_server_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ArchiveManifest) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ArchiveManifest) GetDataGenesisHash() []byte {
+	if x != nil {
+		return x.DataGenesisHash
+	}
+	return nil
+}
+
+func (x *ArchiveManifest) GetTableGenesisHash() []byte {
+	if x != nil {
+		return x.TableGenesisHash
+	}
+	return nil
+}
+
+func (x *ArchiveManifest) GetDataTailHash() []byte {
+	if x != nil {
+		return x.DataTailHash
+	}
+	return nil
+}
+
+func (x *ArchiveManifest) GetTableTailHash() []byte {
+	if x != nil {
+		return x.TableTailHash
+	}
+	return nil
+}
+
+func (x *ArchiveManifest) GetDataBlocks() uint64 {
+	if x != nil {
+		return x.DataBlocks
+	}
+	return 0
+}
+
+func (x *ArchiveManifest) GetTableBlocks() uint64 {
+	if x != nil {
+		return x.TableBlocks
+	}
+	return 0
+}
+
+func (x *ArchiveManifest) GetTimeStamp() int64 {
+	if x != nil {
+		return x.TimeStamp
+	}
+	return 0
+}
+
+var File_server_proto protoreflect.FileDescriptor
+
+var file_server_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x22, 0xe1,
+	0x02, 0x0a, 0x0f, 0x44, 0x61, 0x74, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x04, 0x54, 0x78, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x44, 0x61, 0x74, 0x61, 0x49, 0x44,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x44, 0x61, 0x74, 0x61, 0x49, 0x44, 0x12, 0x14,
+	0x0a, 0x05, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x54,
+	0x61, 0x62, 0x6c, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x4b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x4b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x50, 0x6f, 0x73, 0x73, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x50, 0x6f, 0x73, 0x73, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x54, 0x69,
+	0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x54,
+	0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x50, 0x75, 0x62, 0x6c,
+	0x69, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x50, 0x75, 0x62,
+	0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x64, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x64, 0x12, 0x0e,
+	0x0a, 0x02, 0x4f, 0x70, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x4f, 0x70, 0x12, 0x1c,
+	0x0a, 0x09, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x54, 0x79, 0x70, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x09, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x24, 0x0a, 0x0d,
+	0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0d, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0d, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x22, 0xbc, 0x02, 0x0a, 0x10, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x78, 0x49, 0x44, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x54, 0x78, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x54,
+	0x61, 0x62, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x54, 0x61, 0x62, 0x6c,
+	0x65, 0x12, 0x2a, 0x0a, 0x10, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x54,
+	0x61, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x50, 0x65, 0x72,
+	0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x12, 0x1c, 0x0a,
+	0x09, 0x50, 0x6f, 0x73, 0x73, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x50, 0x6f, 0x73, 0x73, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x54,
+	0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x50, 0x75, 0x62,
+	0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x50, 0x75,
+	0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x53, 0x69, 0x67, 0x6e,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x64,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x64, 0x12,
+	0x0e, 0x0a, 0x02, 0x4f, 0x70, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x4f, 0x70, 0x12,
+	0x16, 0x0a, 0x06, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12, 0x18, 0x0a, 0x07, 0x49, 0x6e, 0x64, 0x65, 0x78,
+	0x65, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x65,
+	0x73, 0x22, 0x57, 0x0a, 0x10, 0x44, 0x61, 0x74, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x43, 0x0a, 0x0c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x44, 0x61, 0x74,
+	0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x59, 0x0a, 0x11, 0x54, 0x61,
+	0x62, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x44, 0x0a, 0x0c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61,
+	0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xf6, 0x02, 0x0a, 0x09, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x02, 0x49, 0x44, 0x12, 0x2a, 0x0a, 0x10, 0x43, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x10, 0x43,
+	0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12,
+	0x2c, 0x0a, 0x11, 0x50, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x48, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x11, 0x50, 0x72, 0x65, 0x76,
+	0x69, 0x6f, 0x75, 0x73, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1e, 0x0a,
+	0x0a, 0x4d, 0x65, 0x72, 0x4b, 0x65, 0x6c, 0x52, 0x6f, 0x6f, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0a, 0x4d, 0x65, 0x72, 0x4b, 0x65, 0x6c, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x37, 0x0a,
+	0x06, 0x54, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x44,
+	0x61, 0x74, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06,
+	0x54, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x54, 0x61,
+	0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x54, 0x61,
+	0x6d, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x53, 0x65, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x04, 0x53, 0x65, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x12, 0x14,
+	0x0a, 0x05, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x50,
+	0x72, 0x6f, 0x6f, 0x66, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x69, 0x67, 0x6e,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x53, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x22, 0x84,
+	0x02, 0x0a, 0x0a, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x0e, 0x0a,
+	0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x49, 0x44, 0x12, 0x2a, 0x0a,
+	0x10, 0x43, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73,
+	0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x10, 0x43, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x2c, 0x0a, 0x11, 0x50, 0x72, 0x65,
+	0x76, 0x69, 0x6f, 0x75, 0x73, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x11, 0x50, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x4d, 0x65, 0x72, 0x4b, 0x65,
+	0x6c, 0x52, 0x6f, 0x6f, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x4d, 0x65, 0x72,
+	0x4b, 0x65, 0x6c, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x38, 0x0a, 0x06, 0x54, 0x78, 0x49, 0x6e, 0x66,
+	0x6f, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43,
+	0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x54, 0x78, 0x49, 0x6e, 0x66,
+	0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x54, 0x61, 0x6d, 0x70, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x54, 0x61, 0x6d, 0x70, 0x12, 0x16, 0x0a,
+	0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x48,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x22, 0x3c, 0x0a, 0x0c, 0x52, 0x65, 0x71, 0x44, 0x61, 0x74, 0x61,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x49, 0x44, 0x22, 0x42, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x73, 0x12, 0x31, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69,
+	0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52,
+	0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22, 0x22, 0x0a, 0x0c, 0x52, 0x65, 0x71, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x64, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x22, 0x3d, 0x0a, 0x0d, 0x52,
+	0x65, 0x71, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x12, 0x0a, 0x04,
+	0x48, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68,
+	0x12, 0x18, 0x0a, 0x07, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x07, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x44, 0x22, 0x44, 0x0a, 0x0e, 0x52, 0x65,
+	0x73, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x32, 0x0a, 0x06,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x61,
+	0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
+	0x22, 0x54, 0x0a, 0x0a, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x46, 0x69,
+	0x6c, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x46, 0x69,
+	0x6c, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x22, 0x7a, 0x0a, 0x08, 0x4e, 0x6f, 0x64, 0x65, 0x49, 0x6e,
+	0x66, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x49, 0x70, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x49, 0x70, 0x12, 0x1c, 0x0a, 0x09,
+	0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x61,
+	0x66, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x52, 0x61,
+	0x66, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x6f,
+	0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x6f,
+	0x72, 0x74, 0x22, 0x37, 0x0a, 0x05, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x2e, 0x0a, 0x05, 0x6e,
+	0x6f, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x4e, 0x6f, 0x64, 0x65,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x67, 0x0a, 0x09, 0x48,
+	0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x49, 0x73, 0x41, 0x63,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c,
+	0x49, 0x73, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x61, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07,
+	0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x49, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6c,
+	0x6f, 0x63, 0x61, 0x6c, 0x49, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x50,
+	0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x6c,
+	0x50, 0x6f, 0x72, 0x74, 0x22, 0x1f, 0x0a, 0x09, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x4e, 0x61, 0x6d,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0xe9, 0x01, 0x0a, 0x07, 0x52, 0x65, 0x71, 0x4a, 0x6f, 0x69,
+	0x6e, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x49, 0x70, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x49, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x6c,
+	0x6f, 0x63, 0x61, 0x6c, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x4a, 0x6f, 0x69,
+	0x6e, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x4a, 0x6f, 0x69, 0x6e,
+	0x4b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x61, 0x66, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x52, 0x61, 0x66, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x28, 0x0a, 0x0f, 0x44,
+	0x61, 0x74, 0x61, 0x47, 0x65, 0x6e, 0x65, 0x73, 0x69, 0x73, 0x48, 0x61, 0x73, 0x68, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f, 0x44, 0x61, 0x74, 0x61, 0x47, 0x65, 0x6e, 0x65, 0x73, 0x69,
+	0x73, 0x48, 0x61, 0x73, 0x68, 0x12, 0x2a, 0x0a, 0x10, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x47, 0x65,
+	0x6e, 0x65, 0x73, 0x69, 0x73, 0x48, 0x61, 0x73, 0x68, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x10, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x47, 0x65, 0x6e, 0x65, 0x73, 0x69, 0x73, 0x48, 0x61, 0x73,
+	0x68, 0x22, 0x32, 0x0a, 0x0a, 0x54, 0x79, 0x70, 0x41, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x61, 0x12,
+	0x10, 0x0a, 0x03, 0x54, 0x79, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x54, 0x79,
+	0x70, 0x12, 0x12, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x04, 0x44, 0x61, 0x74, 0x61, 0x22, 0x32, 0x0a, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x22, 0x26, 0x0a, 0x0e, 0x52, 0x65, 0x71,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x43,
+	0x68, 0x61, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x43, 0x68, 0x61, 0x69,
+	0x6e, 0x22, 0x62, 0x0a, 0x0a, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x73, 0x73, 0x75, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05,
+	0x52, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x4b, 0x69, 0x6e,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x16, 0x0a,
+	0x06, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x44,
+	0x65, 0x74, 0x61, 0x69, 0x6c, 0x22, 0x91, 0x01, 0x0a, 0x0b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x46, 0x69, 0x72, 0x73, 0x74, 0x42, 0x72, 0x6f, 0x6b,
+	0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x46, 0x69, 0x72, 0x73, 0x74, 0x42,
+	0x72, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x32, 0x0a, 0x06, 0x49, 0x73, 0x73, 0x75, 0x65, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61,
+	0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x73, 0x73, 0x75,
+	0x65, 0x52, 0x06, 0x49, 0x73, 0x73, 0x75, 0x65, 0x73, 0x22, 0x47, 0x0a, 0x0e, 0x52, 0x65, 0x73,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x35, 0x0a, 0x07, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x43, 0x68,
+	0x61, 0x69, 0x6e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x07, 0x52, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x73, 0x22, 0xab, 0x02, 0x0a, 0x0f, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x4d, 0x61,
+	0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x28, 0x0a, 0x0f, 0x44, 0x61, 0x74, 0x61, 0x47, 0x65, 0x6e, 0x65, 0x73, 0x69, 0x73, 0x48,
+	0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f, 0x44, 0x61, 0x74, 0x61, 0x47,
+	0x65, 0x6e, 0x65, 0x73, 0x69, 0x73, 0x48, 0x61, 0x73, 0x68, 0x12, 0x2a, 0x0a, 0x10, 0x54, 0x61,
+	0x62, 0x6c, 0x65, 0x47, 0x65, 0x6e, 0x65, 0x73, 0x69, 0x73, 0x48, 0x61, 0x73, 0x68, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x10, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x47, 0x65, 0x6e, 0x65, 0x73,
+	0x69, 0x73, 0x48, 0x61, 0x73, 0x68, 0x12, 0x22, 0x0a, 0x0c, 0x44, 0x61, 0x74, 0x61, 0x54, 0x61,
+	0x69, 0x6c, 0x48, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x44, 0x61,
+	0x74, 0x61, 0x54, 0x61, 0x69, 0x6c, 0x48, 0x61, 0x73, 0x68, 0x12, 0x24, 0x0a, 0x0d, 0x54, 0x61,
+	0x62, 0x6c, 0x65, 0x54, 0x61, 0x69, 0x6c, 0x48, 0x61, 0x73, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0d, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x61, 0x69, 0x6c, 0x48, 0x61, 0x73, 0x68,
+	0x12, 0x1e, 0x0a, 0x0a, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
+	0x12, 0x20, 0x0a, 0x0b, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x6d, 0x70,
+	0x32, 0xdd, 0x07, 0x0a, 0x11, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4e, 0x0a, 0x13, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x19, 0x2e,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x44,
+	0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x1a, 0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79,
+	0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00, 0x12, 0x59, 0x0a, 0x18, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x53, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x1c, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47,
+	0x72, 0x70, 0x63, 0x2e, 0x52, 0x65, 0x71, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x1a, 0x1d, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70,
+	0x63, 0x2e, 0x52, 0x65, 0x73, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22,
+	0x00, 0x12, 0x50, 0x0a, 0x14, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x54,
+	0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x61, 0x62, 0x6c, 0x65,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x1a, 0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61,
+	0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x66,
+	0x6f, 0x22, 0x00, 0x12, 0x5c, 0x0a, 0x19, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x53, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x1d, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70,
+	0x63, 0x2e, 0x52, 0x65, 0x71, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x1a,
+	0x1e, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63,
+	0x2e, 0x52, 0x65, 0x73, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22,
+	0x00, 0x12, 0x50, 0x0a, 0x0f, 0x44, 0x61, 0x74, 0x61, 0x54, 0x72, 0x61, 0x64, 0x69, 0x6e, 0x67,
+	0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x1f, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69,
+	0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61,
+	0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x66,
+	0x6f, 0x22, 0x00, 0x12, 0x52, 0x0a, 0x10, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x72, 0x61, 0x64,
+	0x69, 0x6e, 0x67, 0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x20, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43,
+	0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66,
+	0x79, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00, 0x12, 0x44, 0x0a, 0x0b, 0x4a, 0x6f, 0x69, 0x6e, 0x43,
+	0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x12, 0x17, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68,
+	0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x52, 0x65, 0x71, 0x4a, 0x6f, 0x69, 0x6e, 0x1a,
+	0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63,
+	0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00, 0x12, 0x47, 0x0a,
+	0x0d, 0x42, 0x72, 0x6f, 0x61, 0x64, 0x63, 0x61, 0x73, 0x74, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x18,
+	0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e,
+	0x4e, 0x6f, 0x64, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x1a, 0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79,
+	0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00, 0x12, 0x3c, 0x0a, 0x06, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65,
+	0x12, 0x1a, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70,
+	0x63, 0x2e, 0x54, 0x79, 0x70, 0x41, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x61, 0x1a, 0x14, 0x2e, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x49, 0x6e,
+	0x66, 0x6f, 0x22, 0x00, 0x12, 0x4f, 0x0a, 0x0b, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x43, 0x68,
+	0x61, 0x69, 0x6e, 0x12, 0x1e, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e,
+	0x47, 0x72, 0x70, 0x63, 0x2e, 0x52, 0x65, 0x71, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x43, 0x68,
+	0x61, 0x69, 0x6e, 0x1a, 0x1e, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e,
+	0x47, 0x72, 0x70, 0x63, 0x2e, 0x52, 0x65, 0x73, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x43, 0x68,
+	0x61, 0x69, 0x6e, 0x22, 0x00, 0x12, 0x5a, 0x0a, 0x19, 0x44, 0x61, 0x74, 0x61, 0x48, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x53, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x1c, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47,
+	0x72, 0x70, 0x63, 0x2e, 0x52, 0x65, 0x71, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x1a, 0x1d, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x47, 0x72, 0x70,
+	0x63, 0x2e, 0x52, 0x65, 0x73, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22,
+	0x00, 0x12, 0x4d, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x42, 0x6f, 0x64, 0x79, 0x12, 0x1c, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61,
+	0x69, 0x6e, 0x47, 0x72, 0x70, 0x63, 0x2e, 0x52, 0x65, 0x71, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x42,
+	0x6f, 0x64, 0x79, 0x1a, 0x19, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x61, 0x69, 0x6e,
+	0x47, 0x72, 0x70, 0x63, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x00,
+	0x42, 0x11, 0x50, 0x01, 0x5a, 0x0d, 0x2e, 0x2f, 0x3b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x63, 0x68,
+	0x61, 0x69, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_server_proto_rawDescOnce sync.Once
+	file_server_proto_rawDescData = file_server_proto_rawDesc
+)
+
+func file_server_proto_rawDescGZIP() []byte {
+	file_server_proto_rawDescOnce.Do(func() {
+		file_server_proto_rawDescData = protoimpl.X.CompressGZIP(file_server_proto_rawDescData)
+	})
+	return file_server_proto_rawDescData
+}
+
+var file_server_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_server_proto_goTypes = []interface{}{
+	(*DataTransaction)(nil),   // 0: blockChainGrpc.DataTransaction
+	(*TableTransaction)(nil),  // 1: blockChainGrpc.TableTransaction
+	(*DataTransactions)(nil),  // 2: blockChainGrpc.DataTransactions
+	(*TableTransactions)(nil), // 3: blockChainGrpc.TableTransactions
+	(*DataBlock)(nil),         // 4: blockChainGrpc.DataBlock
+	(*TableBlock)(nil),        // 5: blockChainGrpc.TableBlock
+	(*ReqDataBlock)(nil),      // 6: blockChainGrpc.ReqDataBlock
+	(*ResDataBlocks)(nil),     // 7: blockChainGrpc.ResDataBlocks
+	(*ReqBlockBody)(nil),      // 8: blockChainGrpc.ReqBlockBody
+	(*ReqTableBlock)(nil),     // 9: blockChainGrpc.ReqTableBlock
+	(*ResTableBlocks)(nil),    // 10: blockChainGrpc.ResTableBlocks
+	(*VerifyInfo)(nil),        // 11: blockChainGrpc.VerifyInfo
+	(*NodeInfo)(nil),          // 12: blockChainGrpc.NodeInfo
+	(*Nodes)(nil),             // 13: blockChainGrpc.Nodes
+	(*Heartbeat)(nil),         // 14: blockChainGrpc.Heartbeat
+	(*TableName)(nil),         // 15: blockChainGrpc.TableName
+	(*ReqJoin)(nil),           // 16: blockChainGrpc.ReqJoin
+	(*TypAndData)(nil),        // 17: blockChainGrpc.TypAndData
+	(*Info)(nil),              // 18: blockChainGrpc.Info
+	(*ReqVerifyChain)(nil),    // 19: blockChainGrpc.ReqVerifyChain
+	(*BlockIssue)(nil),        // 20: blockChainGrpc.BlockIssue
+	(*ChainReport)(nil),       // 21: blockChainGrpc.ChainReport
+	(*ResVerifyChain)(nil),    // 22: blockChainGrpc.ResVerifyChain
+	(*ArchiveManifest)(nil),   // 23: blockChainGrpc.ArchiveManifest
+}
+var file_server_proto_depIdxs = []int32{
+	0,  // 0: blockChainGrpc.DataTransactions.Transactions:type_name -> blockChainGrpc.DataTransaction
+	1,  // 1: blockChainGrpc.TableTransactions.Transactions:type_name -> blockChainGrpc.TableTransaction
 	0,  // 2: blockChainGrpc.DataBlock.TxInfo:type_name -> blockChainGrpc.DataTransaction
 	1,  // 3: blockChainGrpc.TableBlock.TxInfo:type_name -> blockChainGrpc.TableTransaction
 	4,  // 4: blockChainGrpc.ResDataBlocks.blocks:type_name -> blockChainGrpc.DataBlock
 	5,  // 5: blockChainGrpc.ResTableBlocks.blocks:type_name -> blockChainGrpc.TableBlock
-	11, // 6: blockChainGrpc.Nodes.nodes:type_name -> blockChainGrpc.NodeInfo
-	4,  // 7: blockChainGrpc.BlockChainService.DistributeDataBlock:input_type -> blockChainGrpc.DataBlock
-	6,  // 8: blockChainGrpc.BlockChainService.DataBlockSynchronization:input_type -> blockChainGrpc.ReqDataBlock
-	5,  // 9: blockChainGrpc.BlockChainService.DistributeTableBlock:input_type -> blockChainGrpc.TableBlock
-	8,  // 10: blockChainGrpc.BlockChainService.TableBlockSynchronization:input_type -> blockChainGrpc.ReqTableBlock
-	0,  // 11: blockChainGrpc.BlockChainService.DataTradingPool:input_type -> blockChainGrpc.DataTransaction
-	1,  // 12: blockChainGrpc.BlockChainService.TableTradingPool:input_type -> blockChainGrpc.TableTransaction
-	15, // 13: blockChainGrpc.BlockChainService.JoinCluster:input_type -> blockChainGrpc.ReqJoin
-	11, // 14: blockChainGrpc.BlockChainService.BroadcastNode:input_type -> blockChainGrpc.NodeInfo
-	16, // 15: blockChainGrpc.BlockChainService.Handle:input_type -> blockChainGrpc.TypAndData
-	10, // 16: blockChainGrpc.BlockChainService.DistributeDataBlock:output_type -> blockChainGrpc.VerifyInfo
-	7,  // 17: blockChainGrpc.BlockChainService.DataBlockSynchronization:output_type -> blockChainGrpc.ResDataBlocks
-	10, // 18: blockChainGrpc.BlockChainService.DistributeTableBlock:output_type -> blockChainGrpc.VerifyInfo
-	9,  // 19: blockChainGrpc.BlockChainService.TableBlockSynchronization:output_type -> blockChainGrpc.ResTableBlocks
-	10, // 20: blockChainGrpc.BlockChainService.DataTradingPool:output_type -> blockChainGrpc.VerifyInfo
-	10, // 21: blockChainGrpc.BlockChainService.TableTradingPool:output_type -> blockChainGrpc.VerifyInfo
-	10, // 22: blockChainGrpc.BlockChainService.JoinCluster:output_type -> blockChainGrpc.VerifyInfo
-	10, // 23: blockChainGrpc.BlockChainService.BroadcastNode:output_type -> blockChainGrpc.VerifyInfo
-	17, // 24: blockChainGrpc.BlockChainService.Handle:output_type -> blockChainGrpc.Info
-	16, // [16:25] is the sub-list for method output_type
-	7,  // [7:16] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	12, // 6: blockChainGrpc.Nodes.nodes:type_name -> blockChainGrpc.NodeInfo
+	20, // 7: blockChainGrpc.ChainReport.Issues:type_name -> blockChainGrpc.BlockIssue
+	21, // 8: blockChainGrpc.ResVerifyChain.Reports:type_name -> blockChainGrpc.ChainReport
+	4,  // 9: blockChainGrpc.BlockChainService.DistributeDataBlock:input_type -> blockChainGrpc.DataBlock
+	6,  // 10: blockChainGrpc.BlockChainService.DataBlockSynchronization:input_type -> blockChainGrpc.ReqDataBlock
+	5,  // 11: blockChainGrpc.BlockChainService.DistributeTableBlock:input_type -> blockChainGrpc.TableBlock
+	9,  // 12: blockChainGrpc.BlockChainService.TableBlockSynchronization:input_type -> blockChainGrpc.ReqTableBlock
+	0,  // 13: blockChainGrpc.BlockChainService.DataTradingPool:input_type -> blockChainGrpc.DataTransaction
+	1,  // 14: blockChainGrpc.BlockChainService.TableTradingPool:input_type -> blockChainGrpc.TableTransaction
+	16, // 15: blockChainGrpc.BlockChainService.JoinCluster:input_type -> blockChainGrpc.ReqJoin
+	12, // 16: blockChainGrpc.BlockChainService.BroadcastNode:input_type -> blockChainGrpc.NodeInfo
+	17, // 17: blockChainGrpc.BlockChainService.Handle:input_type -> blockChainGrpc.TypAndData
+	19, // 18: blockChainGrpc.BlockChainService.VerifyChain:input_type -> blockChainGrpc.ReqVerifyChain
+	6,  // 19: blockChainGrpc.BlockChainService.DataHeaderSynchronization:input_type -> blockChainGrpc.ReqDataBlock
+	8,  // 20: blockChainGrpc.BlockChainService.GetDataBlockBody:input_type -> blockChainGrpc.ReqBlockBody
+	11, // 21: blockChainGrpc.BlockChainService.DistributeDataBlock:output_type -> blockChainGrpc.VerifyInfo
+	7,  // 22: blockChainGrpc.BlockChainService.DataBlockSynchronization:output_type -> blockChainGrpc.ResDataBlocks
+	11, // 23: blockChainGrpc.BlockChainService.DistributeTableBlock:output_type -> blockChainGrpc.VerifyInfo
+	10, // 24: blockChainGrpc.BlockChainService.TableBlockSynchronization:output_type -> blockChainGrpc.ResTableBlocks
+	11, // 25: blockChainGrpc.BlockChainService.DataTradingPool:output_type -> blockChainGrpc.VerifyInfo
+	11, // 26: blockChainGrpc.BlockChainService.TableTradingPool:output_type -> blockChainGrpc.VerifyInfo
+	11, // 27: blockChainGrpc.BlockChainService.JoinCluster:output_type -> blockChainGrpc.VerifyInfo
+	11, // 28: blockChainGrpc.BlockChainService.BroadcastNode:output_type -> blockChainGrpc.VerifyInfo
+	18, // 29: blockChainGrpc.BlockChainService.Handle:output_type -> blockChainGrpc.Info
+	22, // 30: blockChainGrpc.BlockChainService.VerifyChain:output_type -> blockChainGrpc.ResVerifyChain
+	7,  // 31: blockChainGrpc.BlockChainService.DataHeaderSynchronization:output_type -> blockChainGrpc.ResDataBlocks
+	4,  // 32: blockChainGrpc.BlockChainService.GetDataBlockBody:output_type -> blockChainGrpc.DataBlock
+	21, // [21:33] is the sub-list for method output_type
+	9,  // [9:21] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_server_proto_init() }
@@ -1499,7 +2175,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ReqTableBlock); i {
+			switch v := v.(*ReqBlockBody); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1511,7 +2187,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ResTableBlocks); i {
+			switch v := v.(*ReqTableBlock); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1523,7 +2199,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*VerifyInfo); i {
+			switch v := v.(*ResTableBlocks); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1535,7 +2211,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NodeInfo); i {
+			switch v := v.(*VerifyInfo); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1547,7 +2223,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Nodes); i {
+			switch v := v.(*NodeInfo); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1559,7 +2235,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Heartbeat); i {
+			switch v := v.(*Nodes); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1571,7 +2247,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TableName); i {
+			switch v := v.(*Heartbeat); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1583,7 +2259,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ReqJoin); i {
+			switch v := v.(*TableName); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1595,7 +2271,7 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TypAndData); i {
+			switch v := v.(*ReqJoin); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1607,6 +2283,18 @@ func file_server_proto_init() {
 			}
 		}
 		file_server_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TypAndData); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Info); i {
 			case 0:
 				return &v.state
@@ -1618,6 +2306,66 @@ func file_server_proto_init() {
 				return nil
 			}
 		}
+		file_server_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReqVerifyChain); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlockIssue); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChainReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResVerifyChain); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_server_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ArchiveManifest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1625,7 +2373,7 @@ func file_server_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_server_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   18,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -1668,6 +2416,12 @@ type BlockChainServiceClient interface {
 	// 向集群中其他节点发送本节点的信息
 	BroadcastNode(ctx context.Context, in *NodeInfo, opts ...grpc.CallOption) (*VerifyInfo, error)
 	Handle(ctx context.Context, in *TypAndData, opts ...grpc.CallOption) (*Info, error)
+	// 审计本地的区块链
+	VerifyChain(ctx context.Context, in *ReqVerifyChain, opts ...grpc.CallOption) (*ResVerifyChain, error)
+	// 数据区块头的同步(轻节点), 返回的区块没有交易
+	DataHeaderSynchronization(ctx context.Context, in *ReqDataBlock, opts ...grpc.CallOption) (*ResDataBlocks, error)
+	// 获取数据区块的区块体(轻节点按需获取)
+	GetDataBlockBody(ctx context.Context, in *ReqBlockBody, opts ...grpc.CallOption) (*DataBlock, error)
 }
 
 type blockChainServiceClient struct {
@@ -1759,6 +2513,33 @@ func (c *blockChainServiceClient) Handle(ctx context.Context, in *TypAndData, op
 	return out, nil
 }
 
+func (c *blockChainServiceClient) VerifyChain(ctx context.Context, in *ReqVerifyChain, opts ...grpc.CallOption) (*ResVerifyChain, error) {
+	out := new(ResVerifyChain)
+	err := c.cc.Invoke(ctx, "/blockChainGrpc.BlockChainService/VerifyChain", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockChainServiceClient) DataHeaderSynchronization(ctx context.Context, in *ReqDataBlock, opts ...grpc.CallOption) (*ResDataBlocks, error) {
+	out := new(ResDataBlocks)
+	err := c.cc.Invoke(ctx, "/blockChainGrpc.BlockChainService/DataHeaderSynchronization", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockChainServiceClient) GetDataBlockBody(ctx context.Context, in *ReqBlockBody, opts ...grpc.CallOption) (*DataBlock, error) {
+	out := new(DataBlock)
+	err := c.cc.Invoke(ctx, "/blockChainGrpc.BlockChainService/GetDataBlockBody", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BlockChainServiceServer is the server API for BlockChainService service.
 type BlockChainServiceServer interface {
 	// 数据区块的分发
@@ -1778,6 +2559,12 @@ type BlockChainServiceServer interface {
 	// 向集群中其他节点发送本节点的信息
 	BroadcastNode(context.Context, *NodeInfo) (*VerifyInfo, error)
 	Handle(context.Context, *TypAndData) (*Info, error)
+	// 审计本地的区块链
+	VerifyChain(context.Context, *ReqVerifyChain) (*ResVerifyChain, error)
+	// 数据区块头的同步(轻节点), 返回的区块没有交易
+	DataHeaderSynchronization(context.Context, *ReqDataBlock) (*ResDataBlocks, error)
+	// 获取数据区块的区块体(轻节点按需获取)
+	GetDataBlockBody(context.Context, *ReqBlockBody) (*DataBlock, error)
 }
 
 // UnimplementedBlockChainServiceServer can be embedded to have forward compatible implementations.
@@ -1811,6 +2598,15 @@ func (*UnimplementedBlockChainServiceServer) BroadcastNode(context.Context, *Nod
 func (*UnimplementedBlockChainServiceServer) Handle(context.Context, *TypAndData) (*Info, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Handle not implemented")
 }
+func (*UnimplementedBlockChainServiceServer) VerifyChain(context.Context, *ReqVerifyChain) (*ResVerifyChain, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyChain not implemented")
+}
+func (*UnimplementedBlockChainServiceServer) DataHeaderSynchronization(context.Context, *ReqDataBlock) (*ResDataBlocks, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DataHeaderSynchronization not implemented")
+}
+func (*UnimplementedBlockChainServiceServer) GetDataBlockBody(context.Context, *ReqBlockBody) (*DataBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDataBlockBody not implemented")
+}
 
 func RegisterBlockChainServiceServer(s *grpc.Server, srv BlockChainServiceServer) {
 	s.RegisterService(&_BlockChainService_serviceDesc, srv)
@@ -1978,6 +2774,60 @@ func _BlockChainService_Handle_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlockChainService_VerifyChain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReqVerifyChain)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockChainServiceServer).VerifyChain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blockChainGrpc.BlockChainService/VerifyChain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockChainServiceServer).VerifyChain(ctx, req.(*ReqVerifyChain))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockChainService_DataHeaderSynchronization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReqDataBlock)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockChainServiceServer).DataHeaderSynchronization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blockChainGrpc.BlockChainService/DataHeaderSynchronization",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockChainServiceServer).DataHeaderSynchronization(ctx, req.(*ReqDataBlock))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockChainService_GetDataBlockBody_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReqBlockBody)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockChainServiceServer).GetDataBlockBody(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blockChainGrpc.BlockChainService/GetDataBlockBody",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockChainServiceServer).GetDataBlockBody(ctx, req.(*ReqBlockBody))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _BlockChainService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "blockChainGrpc.BlockChainService",
 	HandlerType: (*BlockChainServiceServer)(nil),
@@ -2018,6 +2868,18 @@ var _BlockChainService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Handle",
 			Handler:    _BlockChainService_Handle_Handler,
 		},
+		{
+			MethodName: "VerifyChain",
+			Handler:    _BlockChainService_VerifyChain_Handler,
+		},
+		{
+			MethodName: "DataHeaderSynchronization",
+			Handler:    _BlockChainService_DataHeaderSynchronization_Handler,
+		},
+		{
+			MethodName: "GetDataBlockBody",
+			Handler:    _BlockChainService_GetDataBlockBody_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "server.proto",
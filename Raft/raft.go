@@ -3,6 +3,7 @@ package Raft
 import (
 	"fmt"
 	"math/rand"
+	"net"
 	"sync"
 	"time"
 )
@@ -12,25 +13,30 @@ type NodeInfo struct {
 	Port string
 }
 
+// id 节点编号(ip加port), 同一台机器上的节点用端口区分
+func (node NodeInfo) id() string {
+	return net.JoinHostPort(node.IP, node.Port)
+}
+
 type Message struct {
 	MsgBody string
 	MsgID   int
 }
 
 type Raft struct {
-	node                  *NodeInfo  //本节点信息
-	vote                  int        //本节点获得的投票数
-	lock                  sync.Mutex //互斥锁
-	me                    string     //本节点编号(ip加port)
-	currentTerm           int        //当前任期
-	votedFor              string     //为哪个节点投票
-	state                 int        //当前节点状态0 follower  1 candidate  2 leader
-	lastSendMessageTime   int64      //发送最后一条消息的时间
-	lastSendHeartBeatTime int64      //发送最后一次心跳的时间
-	currentLeader         string     //当前集群的领导
-	heartBeatTimeout      int        //心跳超时时间（秒）
-	voteChan              chan bool  //接收投票成功通道
-	heartChan             chan bool  //心跳信号
+	node                  *NodeInfo     //本节点信息
+	vote                  int           //本节点获得的投票数
+	lock                  sync.Mutex    //互斥锁
+	me                    string        //本节点编号(ip加port)
+	currentTerm           int           //当前任期
+	votedFor              string        //为哪个节点投票
+	state                 int           //当前节点状态0 follower  1 candidate  2 leader
+	lastSendMessageTime   int64         //发送最后一条消息的时间
+	lastSendHeartBeatTime int64         //发送最后一次心跳的时间
+	currentLeader         string        //当前集群的领导
+	heartBeatTimeout      time.Duration //心跳超时时间
+	voteChan              chan bool     //接收投票成功通道
+	heartChan             chan bool     //心跳信号
 
 }
 
@@ -41,7 +47,7 @@ func NewRaft(ip, port string) *Raft {
 		Port: port,
 	}
 	rf.setVote(0)                          //当前节点获得票数
-	rf.me = ip + port                      //编号
+	rf.me = rf.node.id()                   //编号
 	rf.setVoteFor("-1")                    //给0  1  2三个节点投票，给谁都不投
 	rf.setStatus(0)                        //设置节点状态 0 follower
 	rf.lastSendHeartBeatTime = 0           //最后一次心跳检测时间
@@ -133,7 +139,7 @@ func (rf *Raft) sendHeartPacket() {
 		//最后一次心跳的时间
 		rf.lastSendHeartBeatTime = millisecond()
 		//休眠 --》心跳检测频率的时间
-		time.Sleep(time.Second * time.Duration(heartBeatRate))
+		time.Sleep(heartBeatRate)
 	}
 }
 
@@ -170,7 +176,7 @@ func (rf *Raft) election() bool {
 	for {
 		select {
 		//选举超时
-		case <-time.After(time.Second * time.Duration(electionTimeout)):
+		case <-time.After(electionTimeout):
 			//fmt.Println("领导者选举超时，节点变更为追随者状态")
 			rf.reDefault()
 			return false
@@ -223,7 +229,7 @@ func (rf *Raft) heartTimeoutDetection() {
 		//0.5秒检测一次
 		time.Sleep(time.Millisecond * 500)
 		//心跳超时
-		if rf.lastSendHeartBeatTime != 0 && (millisecond()-rf.lastSendHeartBeatTime) > int64(rf.heartBeatTimeout*1000) {
+		if rf.lastSendHeartBeatTime != 0 && (millisecond()-rf.lastSendHeartBeatTime) > rf.heartBeatTimeout.Milliseconds() {
 			//fmt.Printf("心跳检测超时，已超过%d秒\n", rf.heartBeatTimeout)
 			//fmt.Println("即将重新开启选举")
 			rf.reDefault()
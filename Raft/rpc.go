@@ -2,6 +2,7 @@ package Raft
 
 import (
 	"alg_bcDB/Cluster"
+	"alg_bcDB/config"
 	"alg_bcDB/txpool"
 	"alg_bcDB/util"
 	"fmt"
@@ -22,7 +23,7 @@ func rpcRegister(raft *Raft) {
 	//把RPC服务绑定到http协议上c
 	rpc.HandleHTTP()
 	//127.0.0.1:6870|6871|6872
-	err := http.ListenAndServe(":3302", nil)
+	err := http.ListenAndServe(config.LocalConfig.ListenAddr(config.LocalConfig.Ports.Raft), nil)
 	if err != nil {
 		log.Panic(err)
 	}
@@ -32,11 +33,11 @@ func rpcRegister(raft *Raft) {
 func (rf *Raft) broadcast(method string, args interface{}, fun func(ok bool)) {
 	for _, node := range Cluster.LocalNode.Node {
 		//不广播自己
-		if node.IP+"3302" == rf.me {
+		if node.RaftAddr() == rf.me {
 			continue
 		}
 		//连接远程节点的rpc
-		conn, err := rpc.DialHTTP("tcp", node.IP+":3302")
+		conn, err := rpc.DialHTTP("tcp", node.RaftAddr())
 		if err != nil {
 			//连接失败，调用回调
 			fun(false)
@@ -57,7 +58,7 @@ func (rf *Raft) broadcast(method string, args interface{}, fun func(ok bool)) {
 // HeartBeatResponse 心跳检测回复
 func (rf *Raft) HeartBeatResponse(node NodeInfo, b *bool) error {
 	//因为发送心跳的一定是leader，之所以写这一句的目的是如果有down的节点恢复了，直接是follower，所以直接告诉它leader是谁即可
-	rf.setCurrentLeader(node.IP)
+	rf.setCurrentLeader(node.id())
 	//最后一次心跳的时间
 	rf.lastSendHeartBeatTime = millisecond()
 	//fmt.Printf("收到来自leader[%s]节点的心跳检测\n", node.IP)
@@ -67,14 +68,14 @@ func (rf *Raft) HeartBeatResponse(node NodeInfo, b *bool) error {
 
 // ConfirmationLeader 确认领导者
 func (rf *Raft) ConfirmationLeader(node NodeInfo, b *bool) error {
-	rf.setCurrentLeader(node.IP)
+	rf.setCurrentLeader(node.id())
 	*b = true
 	//fmt.Println(node.IP, "成为了领导者")
 	fmt.Printf("> ")
 	rf.reDefault()
 	// 将记账权给领导者
 	for _, nodes := range Cluster.LocalNode.Node {
-		if nodes.IP == node.IP {
+		if nodes.RaftAddr() == node.id() {
 			nodes.Account = true
 		} else {
 			nodes.Account = false
@@ -88,7 +89,7 @@ func (rf *Raft) ConfirmationLeader(node NodeInfo, b *bool) error {
 // Vote 投票
 func (rf *Raft) Vote(node NodeInfo, b *bool) error {
 	if rf.votedFor == "-1" && rf.currentLeader == "-1" {
-		rf.setVoteFor(node.IP)
+		rf.setVoteFor(node.id())
 		//fmt.Printf("投票成功，已投%s节点\n", node.IP)
 		*b = true
 	} else {
@@ -2,9 +2,11 @@ package Raft
 
 import (
 	"alg_bcDB/Cluster"
+	"alg_bcDB/config"
 	"alg_bcDB/txpool"
 	"alg_bcDB/util"
 	"strconv"
+	"time"
 )
 
 //定义节点数量
@@ -13,21 +15,24 @@ var nodeCount = 3
 //节点池
 var nodePool int
 
-//选举超时时间（秒）
-var electionTimeout = 3
+//选举超时时间
+var electionTimeout = 3 * time.Second
 
 //心跳检测超时时间
-var heartBeatTimeout = 4
+var heartBeatTimeout = 4 * time.Second
 
-//心跳检测频率（秒）
-var heartBeatRate = 2
+//心跳检测频率
+var heartBeatRate = 2 * time.Second
 
 //// MessageStore 存储信息
 //var MessageStore = make(map[int]string)
 
 func Start(tpl *txpool.TxPool) {
 	nodeCount = len(Cluster.LocalNode.Node)
-	nodePool = 3302
+	nodePool = config.LocalConfig.Ports.Raft
+	electionTimeout = config.LocalConfig.Consensus.ElectionTimeout
+	heartBeatTimeout = config.LocalConfig.Consensus.HeartbeatTimeout
+	heartBeatRate = config.LocalConfig.Consensus.HeartbeatRate
 
 	//传入节点编号，端口号，创建raft实例
 	raft := NewRaft(util.LocalIP, strconv.Itoa(nodePool))
@@ -3,6 +3,7 @@ package algorand
 import (
 	"alg_bcDB/blockchain/blockchain_data"
 	"alg_bcDB/common"
+	"alg_bcDB/config"
 	"alg_bcDB/util"
 	"bytes"
 	"crypto/sha256"
@@ -10,14 +11,14 @@ import (
 	"log"
 	"math/big"
 	"math/rand"
+	"net"
+	"strconv"
 	"time"
 )
 
 var LocalAlg *Algorand
 
 var (
-	ip                   = util.GetLocalIp()
-	port                 = "3304"
 	errCountVotesTimeout = errors.New("count votes timeout")
 
 	// global metrics
@@ -41,7 +42,7 @@ func NewAlgorand() *Algorand {
 	rand.Seed(time.Now().UnixNano())
 	pub, priv, _ := util.NewKeyPair()
 	alg := &Algorand{
-		id:      ip + port,
+		id:      net.JoinHostPort(util.LocalIP, strconv.Itoa(config.LocalConfig.Ports.Algorand)),
 		privkey: priv,
 		Pubkey:  pub,
 		chain:   blockchain_data.LocalDataBlockChain,
@@ -132,7 +133,12 @@ func (alg *Algorand) SortitionSeed(round uint64) []byte {
 		realR -= mod
 	}
 
-	return alg.chain.GetByRound(realR).Seed
+	// 种子在区块头里面, 轻节点不需要获取区块体
+	header, err := alg.chain.GetHeaderByHash(alg.chain.GetHashByRound(realR))
+	if err != nil {
+		log.Panic(err)
+	}
+	return header.Seed
 }
 
 func (alg *Algorand) Address() common.Address {
@@ -161,7 +167,8 @@ func (alg *Algorand) Address() common.Address {
 //}
 
 // ProcessMain 执行algorand算法的主要处理。
-func (alg *Algorand) ProcessMain(transactions []*blockchain_data.Transaction, round uint64, vrf, proof []byte, subusers int) *blockchain_data.Block {
+// tableID, tableHash 为检查交易的写入权限时表区块链的位置, 写入提议的区块头
+func (alg *Algorand) ProcessMain(transactions []*blockchain_data.Transaction, tableID uint64, tableHash []byte, round uint64, vrf, proof []byte, subusers int) *blockchain_data.Block {
 	// 获取当前时间并加0.5
 	//begin := time.Now().Add(time.Millisecond * 500).Unix()
 	// 广播开始时间
@@ -173,7 +180,7 @@ func (alg *Algorand) ProcessMain(transactions []*blockchain_data.Transaction, ro
 	//}
 	currRound := alg.Round() + 1
 	// 1. 区块提议
-	block := alg.blockProposal(transactions, round, vrf, proof, subusers)
+	block := alg.blockProposal(transactions, tableID, tableHash, round, vrf, proof, subusers)
 	log.Printf("node %s init BA with block #%d %x, is empty? %v\n", alg.id, block.Round, block.CurrentBlockHash[:], block.Signature == nil)
 
 	//a := len(alg.peer.blocks[currRound])
@@ -208,7 +215,8 @@ func (alg *Algorand) ProcessMain(transactions []*blockchain_data.Transaction, ro
 }
 
 // proposeBlock 提出一个新的块
-func (alg *Algorand) proposeBlock(transactions []*blockchain_data.Transaction) *blockchain_data.Block {
+// sortVRF, sortProof 为提议者抽签的结果, 写入区块头, 只同步区块头的轻节点用它校验提议者
+func (alg *Algorand) proposeBlock(transactions []*blockchain_data.Transaction, tableID uint64, tableHash []byte, sortVRF, sortProof []byte) *blockchain_data.Block {
 	currRound := alg.Round() + 1
 
 	seed, proof, err := alg.vrfSeed(currRound)
@@ -220,7 +228,15 @@ func (alg *Algorand) proposeBlock(transactions []*blockchain_data.Transaction) *
 	block.Round = currRound
 	block.Seed = seed
 	block.Author = alg.Pubkey.Address()
+	block.AuthorVRF = sortVRF
+	block.AuthorProof = sortProof
 	block.Proof = proof
+	block.TableID = tableID
+	block.TableHash = tableHash
+	// 提交时无效的交易也在区块头里面, 本地没有世界状态时为空
+	block.Invalid, _ = alg.chain.ComputeInvalid(&block)
+	// Round, Seed, Author, 抽签证明, Proof, Invalid, 表区块链的位置 都在区块头里面, 重新计算区块的 HASH 再签名
+	block.SetBlockHash()
 	sign, _ := alg.privkey.Sign(block.CurrentBlockHash)
 	block.Signature = sign
 	log.Printf("node %s propose a new block #%d %x\n", alg.id, block.Round, block.CurrentBlockHash)
@@ -228,7 +244,7 @@ func (alg *Algorand) proposeBlock(transactions []*blockchain_data.Transaction) *
 }
 
 // blockProposal 执行块提议过程。
-func (alg *Algorand) blockProposal(transactions []*blockchain_data.Transaction, round uint64, vrf, proof []byte, subusers int) *blockchain_data.Block {
+func (alg *Algorand) blockProposal(transactions []*blockchain_data.Transaction, tableID uint64, tableHash []byte, round uint64, vrf, proof []byte, subusers int) *blockchain_data.Block {
 	//round := alg.Round() + 1
 	//vrf, proof, subusers := alg.Sortition(alg.SortitionSeed(round), Role(util.Proposer, round, util.PROPOSE), util.ExpectedBlockProposers, alg.TokenOwn())
 	// have been selected.
@@ -238,7 +254,7 @@ func (alg *Algorand) blockProposal(transactions []*blockchain_data.Transaction,
 			proposalType int
 		)
 
-		newBlk = alg.proposeBlock(transactions)
+		newBlk = alg.proposeBlock(transactions, tableID, tableHash, vrf, proof)
 		proposalType = BlockProposal
 
 		proposal := &Proposal{
@@ -311,6 +327,24 @@ func (alg *Algorand) verifySort(vrf, proof, seed, role []byte, expectedNum int)
 	return subUsers(expectedNum, alg.TokenOwn(), vrf)
 }
 
+// VerifyProposer 校验区块头里面提议者的抽签, 和全节点校验区块提议 (Proposal.Verify) 的方法一样:
+// 抽签的 VRF 证明有效, 并且提议者被选中。 chain 里面要有区块之前的区块头 (轻节点只有区块头), 调用前先校验区块的签名
+func VerifyProposer(chain *blockchain_data.BlockChain, block *blockchain_data.Block) error {
+	if len(block.AuthorVRF) == 0 || len(block.AuthorProof) == 0 {
+		return errors.New("区块头没有提议者的抽签证明")
+	}
+	alg := &Algorand{chain: chain}
+	pubkey := util.RecoverPubkey(block.Signature)
+	m := constructSeed(alg.SortitionSeed(block.Round), Role(util.Proposer, block.Round, util.PROPOSE))
+	if err := pubkey.VerifyVRF(block.AuthorProof, m); err != nil {
+		return err
+	}
+	if subUsers(util.ExpectedBlockProposers, alg.weight(block.Author), block.AuthorVRF) == 0 {
+		return errors.New("提议者没有被抽签选中")
+	}
+	return nil
+}
+
 // committeeVote votes for `value`.
 func (alg *Algorand) committeeVote(round uint64, step int, expectedNum int, hash []byte) error {
 
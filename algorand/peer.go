@@ -59,7 +59,7 @@ func (p *Peer) Gossip(typ int, data []byte) {
 	//log.Println("gossip is Run")
 	// simulate gossiping
 	for _, peer := range Cluster.LocalNode.Node {
-		if peer.IP+port == ID() {
+		if peer.IP == util.LocalIP && peer.Port == util.LocalPort {
 			continue
 		}
 		//log.Println("ip,port:", fmt.Sprintf("%s:%d", peer.IP, peer.Port))
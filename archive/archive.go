@@ -0,0 +1,129 @@
+package archive
+
+import (
+	BcGrpc "alg_bcDB/Proto/blockchain"
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// 区块链归档文件, 用于导出本地的区块链, 并用来初始化新的节点。
+// 格式: magic + 版本(1 byte), 然后是若干记录。
+// 每个记录为 类型(1 byte) + 长度(uvarint) + protobuf 编码的内容。
+// 第一个记录是清单 ArchiveManifest, 然后是所有的表区块 TableBlock, 最后是所有的数据区块 DataBlock,
+// 区块都从创世区块开始按照 ID/Round 从小到大排列。
+
+const (
+	magic   = "bcDB-archive"
+	Version = 1
+
+	recordManifest   byte = 1
+	recordTableBlock byte = 2
+	recordDataBlock  byte = 3
+
+	// maxRecordSize 单个记录的最大长度, 防止读取损坏的文件时分配过大的内存
+	maxRecordSize = 64 << 20
+)
+
+type writer struct {
+	w *bufio.Writer
+}
+
+func newWriter(w io.Writer) (*writer, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(magic); err != nil {
+		return nil, err
+	}
+	if err := bw.WriteByte(Version); err != nil {
+		return nil, err
+	}
+	return &writer{w: bw}, nil
+}
+
+func (w *writer) writeRecord(kind byte, m proto.Message) error {
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := w.w.WriteByte(kind); err != nil {
+		return err
+	}
+	length := make([]byte, binary.MaxVarintLen64)
+	if _, err := w.w.Write(length[:binary.PutUvarint(length, uint64(len(data)))]); err != nil {
+		return err
+	}
+	_, err = w.w.Write(data)
+	return err
+}
+
+func (w *writer) flush() error {
+	return w.w.Flush()
+}
+
+type reader struct {
+	r *bufio.Reader
+}
+
+func newReader(r io.Reader) (*reader, error) {
+	br := bufio.NewReader(r)
+	head := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(br, head); err != nil {
+		return nil, errors.New("不是区块链归档文件")
+	}
+	if string(head[:len(magic)]) != magic {
+		return nil, errors.New("不是区块链归档文件")
+	}
+	if head[len(magic)] != Version {
+		return nil, fmt.Errorf("不支持的归档版本 %d", head[len(magic)])
+	}
+	return &reader{r: br}, nil
+}
+
+// next 读取下一个记录, 文件结束时返回 io.EOF
+func (r *reader) next() (kind byte, data []byte, err error) {
+	kind, err = r.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	if length > maxRecordSize {
+		return 0, nil, errors.New("记录过大")
+	}
+	data = make([]byte, length)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	return kind, data, nil
+}
+
+// readManifest 打开归档文件并读取清单
+func readManifest(path string) (*os.File, *reader, *BcGrpc.ArchiveManifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	r, err := newReader(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, nil, err
+	}
+	kind, data, err := r.next()
+	if err != nil || kind != recordManifest {
+		file.Close()
+		return nil, nil, nil, errors.New("归档文件没有清单")
+	}
+	manifest := &BcGrpc.ArchiveManifest{}
+	if err := proto.Unmarshal(data, manifest); err != nil {
+		file.Close()
+		return nil, nil, nil, err
+	}
+	return file, r, manifest, nil
+}
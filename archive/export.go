@@ -0,0 +1,92 @@
+package archive
+
+import (
+	"alg_bcDB/GRPC"
+	BcGrpc "alg_bcDB/Proto/blockchain"
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/blockchain/blockchain_table"
+	"errors"
+	"os"
+	"time"
+)
+
+// Export 把本地的表区块链和数据区块链导出到归档文件。
+// 先写入临时文件, 完成后再重命名, 不会留下不完整的归档文件
+func Export(path string, dataChain *blockchain_data.BlockChain, tableChain *blockchain_table.BlockChain) (*BcGrpc.ArchiveManifest, error) {
+	if dataChain.Light {
+		return nil, errors.New("轻节点没有区块体, 不能导出")
+	}
+	// 导出开始时的区块链, 之后新增的区块不导出
+	dataLast := dataChain.LastID
+	tableLast := tableChain.LastID
+	manifest := &BcGrpc.ArchiveManifest{
+		Version:          Version,
+		DataGenesisHash:  dataChain.GetHashByRound(0),
+		TableGenesisHash: tableChain.GetHashByID(1),
+		DataTailHash:     dataChain.GetHashByRound(dataLast - 1),
+		TableTailHash:    tableChain.GetHashByID(tableLast - 1),
+		DataBlocks:       dataLast,
+		TableBlocks:      uint64(tableLast - 1),
+		TimeStamp:        time.Now().Unix(),
+	}
+
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	err = writeArchive(file, manifest, dataChain, tableChain)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func writeArchive(file *os.File, manifest *BcGrpc.ArchiveManifest, dataChain *blockchain_data.BlockChain, tableChain *blockchain_table.BlockChain) error {
+	w, err := newWriter(file)
+	if err != nil {
+		return err
+	}
+	if err := w.writeRecord(recordManifest, manifest); err != nil {
+		return err
+	}
+	// 表区块, 创世区块的 ID 为 1
+	for id := 1; id <= int(manifest.TableBlocks); id++ {
+		hash := tableChain.GetHashByID(id)
+		if hash == nil {
+			return errors.New("表区块链的索引不完整")
+		}
+		block, err := tableChain.GetBlockByHash(hash)
+		if err != nil {
+			return err
+		}
+		if err := w.writeRecord(recordTableBlock, GRPC.BlockToGrpcTableBlock(block)); err != nil {
+			return err
+		}
+	}
+	// 数据区块, 创世区块的 Round 为 0
+	for round := uint64(0); round < manifest.DataBlocks; round++ {
+		hash := dataChain.GetHashByRound(round)
+		if hash == nil {
+			return errors.New("数据区块链的索引不完整")
+		}
+		block, err := dataChain.GetBlockByHash(hash)
+		if err != nil {
+			return err
+		}
+		if err := w.writeRecord(recordDataBlock, GRPC.BlockToGrpcDataBlock(block)); err != nil {
+			return err
+		}
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
@@ -0,0 +1,186 @@
+package archive
+
+import (
+	"alg_bcDB/GRPC"
+	BcGrpc "alg_bcDB/Proto/blockchain"
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/blockchain/blockchain_table"
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/cache"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Import 用归档文件初始化新的节点。
+// 本地的两条区块链只能有创世区块, 且创世区块要和归档文件里面的一致。
+// 先在内存里面的区块链上完整地重放一遍归档文件, 全部通过之后再写入本地区块链并更新 cache
+func Import(path string, dataChain *blockchain_data.BlockChain, tableChain *blockchain_table.BlockChain, c *cache.Cache) (*BcGrpc.ArchiveManifest, error) {
+	if dataChain.Light {
+		return nil, errors.New("轻节点不能导入归档文件")
+	}
+	if dataChain.LastID != 1 || tableChain.LastID != 2 {
+		return nil, errors.New("本地区块链不为空, 只能在新的节点导入")
+	}
+	scratchData, scratchTable, scratchCache := scratch()
+	if _, err := replay(path, scratchData, scratchTable, scratchCache); err != nil {
+		return nil, err
+	}
+	return replay(path, dataChain, tableChain, c)
+}
+
+// scratch 和新的节点一样只有创世区块的内存区块链和 cache, 用于第一遍校验。
+// 数据区块的写入权限 (CheckBlockWrites) 要按照前面的区块更新之后的权限表和数据校验, 所以第一遍校验也要写入区块。
+// 初始化区块链和 cache 时会替换全局的 LocalDataBlockChain 等, 初始化之后恢复
+func scratch() (*blockchain_data.BlockChain, *blockchain_table.BlockChain, *cache.Cache) {
+	localData, localTable, localCache := blockchain_data.LocalDataBlockChain, blockchain_table.LocalTableBlockChain, cache.LocalCache
+	defer func() {
+		blockchain_data.LocalDataBlockChain, blockchain_table.LocalTableBlockChain, cache.LocalCache = localData, localTable, localCache
+	}()
+	dataChain := new(blockchain_data.BlockChain)
+	dataChain.InitWithStore(blockstore.NewMemory())
+	tableChain := new(blockchain_table.BlockChain)
+	tableChain.InitWithStore(blockstore.NewMemory())
+	c := new(cache.Cache)
+	c.Init(dataChain, tableChain)
+	return dataChain, tableChain, c
+}
+
+// replay 按顺序读取并校验归档文件里面的所有区块, 把校验通过的区块写入区块链并更新 cache。
+// 表交易的权限和数据区块的写入权限都按照 cache 里面前面的区块更新之后的状态校验
+func replay(path string, dataChain *blockchain_data.BlockChain, tableChain *blockchain_table.BlockChain, c *cache.Cache) (*BcGrpc.ArchiveManifest, error) {
+	file, r, manifest, err := readManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if manifest.Version != Version {
+		return nil, fmt.Errorf("不支持的归档版本 %d", manifest.Version)
+	}
+	if !bytes.Equal(manifest.DataGenesisHash, dataChain.GetHashByRound(0)) {
+		return nil, errors.New("数据区块链的创世区块和本地不一致")
+	}
+	if !bytes.Equal(manifest.TableGenesisHash, tableChain.GetHashByID(1)) {
+		return nil, errors.New("表区块链的创世区块和本地不一致")
+	}
+
+	var tableBlocks, dataBlocks uint64
+	var tablePrev, dataPrev []byte
+	for {
+		kind, data, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case recordTableBlock:
+			// 表区块都在数据区块之前
+			if dataBlocks != 0 {
+				return nil, errors.New("表区块出现在数据区块之后")
+			}
+			grpcBlock := &BcGrpc.TableBlock{}
+			if err := proto.Unmarshal(data, grpcBlock); err != nil {
+				return nil, err
+			}
+			block := GRPC.GrpcTableBlockToBlock(grpcBlock)
+			id := int(tableBlocks) + 1
+			if err := checkTableBlock(tableChain, block, id, tablePrev, c.Permissions); err != nil {
+				return nil, fmt.Errorf("表区块 %d: %v", id, err)
+			}
+			if id != 1 {
+				tableChain.AddBlockToChain(*block)
+				c.UpdateByTableBlock(*block)
+			}
+			tablePrev = block.CurrentBlockHash
+			tableBlocks++
+		case recordDataBlock:
+			grpcBlock := &BcGrpc.DataBlock{}
+			if err := proto.Unmarshal(data, grpcBlock); err != nil {
+				return nil, err
+			}
+			block := GRPC.GrpcDataBlockToBlock(grpcBlock)
+			round := dataBlocks
+			if err := checkDataBlock(dataChain, block, round, dataPrev); err != nil {
+				return nil, fmt.Errorf("数据区块 %d: %v", round, err)
+			}
+			if round != 0 {
+				// 没有写入权限的写入不能上链, 和接收分发的区块一样
+				if err := c.CheckBlockWrites(block); err != nil {
+					return nil, fmt.Errorf("数据区块 %d: %v", round, err)
+				}
+				dataChain.AddBlockToChain(*block)
+				c.UpdateByDataBlock(*block)
+			}
+			dataPrev = block.CurrentBlockHash
+			dataBlocks++
+		default:
+			return nil, fmt.Errorf("未知的记录类型 %d", kind)
+		}
+	}
+
+	if tableBlocks != manifest.TableBlocks || dataBlocks != manifest.DataBlocks {
+		return nil, fmt.Errorf("区块数量和清单不一致 (表区块 %d/%d, 数据区块 %d/%d)",
+			tableBlocks, manifest.TableBlocks, dataBlocks, manifest.DataBlocks)
+	}
+	if !bytes.Equal(tablePrev, manifest.TableTailHash) || !bytes.Equal(dataPrev, manifest.DataTailHash) {
+		return nil, errors.New("链尾区块和清单不一致")
+	}
+	return manifest, nil
+}
+
+// checkTableBlock 校验归档里面的表区块, 创世区块只需要和本地一致
+func checkTableBlock(tableChain *blockchain_table.BlockChain, block *blockchain_table.Block, id int, prevHash []byte,
+	permissions func(table string) (blockchain_table.Permissions, error)) error {
+	if block.ID != id {
+		return fmt.Errorf("区块 ID 为 %d", block.ID)
+	}
+	if id == 1 {
+		if !bytes.Equal(block.CurrentBlockHash, tableChain.GetHashByID(1)) {
+			return errors.New("创世区块和本地不一致")
+		}
+		return nil
+	}
+	if !bytes.Equal(block.PreviousBlockHash, prevHash) {
+		return errors.New("前一个区块HASH错误")
+	}
+	// CheckTableBlock 在默克尔根错误时会 panic, 先检查默克尔根
+	if !bytes.Equal(block.ComputeMerkleRoot(), block.MerKelRoot) {
+		return errors.New("默克尔根错误")
+	}
+	if !tableChain.CheckTableBlock(block, permissions) {
+		return errors.New("区块校验失败")
+	}
+	return nil
+}
+
+// checkDataBlock 校验归档里面的数据区块, 创世区块只需要和本地一致
+func checkDataBlock(dataChain *blockchain_data.BlockChain, block *blockchain_data.Block, round uint64, prevHash []byte) error {
+	if block.Round != round {
+		return fmt.Errorf("区块 Round 为 %d", block.Round)
+	}
+	if round == 0 {
+		if !bytes.Equal(block.CurrentBlockHash, dataChain.GetHashByRound(0)) {
+			return errors.New("创世区块和本地不一致")
+		}
+		return nil
+	}
+	if !bytes.Equal(block.PreviousBlockHash, prevHash) {
+		return errors.New("前一个区块HASH错误")
+	}
+	if !bytes.Equal(block.ComputeMerkleRoot(), block.MerKelRoot) {
+		return errors.New("默克尔根错误")
+	}
+	if !dataChain.CheckDataBlock(block) {
+		return errors.New("区块校验失败")
+	}
+	if err := block.VerifySignature(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/common"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 批量交易: 一个交易里面有多个写入, 可以写入多个表, 只有一个 TxID 和一个签名。
+// 批量交易作为一个整体打包进一个区块, 要么所有写入都生效, 要么都不生效。
+// 批量交易的 Table, Key, Value, DataID 为空, 写入在 Writes 里面
+
+// 批量交易里面写入的编码字段, tagWriteType 及以后的字段是可选字段
+const (
+	tagWriteTable byte = iota + 1
+	tagWriteKey
+	tagWriteValue
+	tagWriteOp
+	tagWriteType
+	tagWriteSchemaVersion
+)
+
+// MaxBatchWrites 一个批量交易最多的写入个数
+const MaxBatchWrites = 1024
+
+// Write 批量交易里面的一个写入
+type Write struct {
+	Table string
+	Key   string
+	Value string
+	Type  ValueType // 值的类型
+	Op    DataOp    // 写入或者删除
+	// 写入时检查的表的 schema 的版本
+	SchemaVersion uint64
+}
+
+// DataID 写入的数据ID, 和单个交易的 DataID 相同
+func (w *Write) DataID() []byte {
+	return []byte(w.Table + "-QAQ-" + w.Key)
+}
+
+func (w *Write) encode() []byte {
+	return common.NewEncoder().
+		PutString(tagWriteTable, w.Table).
+		PutString(tagWriteKey, w.Key).
+		PutString(tagWriteValue, w.Value).
+		PutBytes(tagWriteOp, []byte{byte(w.Op)}).
+		PutOptionalUint64(tagWriteType, uint64(w.Type)).
+		PutOptionalUint64(tagWriteSchemaVersion, w.SchemaVersion).
+		Encoded()
+}
+
+func decodeWrite(data []byte) (Write, error) {
+	d := common.NewDecoder(data)
+	w := Write{
+		Table: d.String(tagWriteTable),
+		Key:   d.String(tagWriteKey),
+		Value: d.String(tagWriteValue),
+	}
+	op := d.Bytes(tagWriteOp)
+	typ := d.OptionalUint64(tagWriteType)
+	w.SchemaVersion = d.OptionalUint64(tagWriteSchemaVersion)
+	if err := d.Finish(); err != nil {
+		return w, err
+	}
+	if len(op) != 1 || typ > 0xff {
+		return w, errors.New("bad data op")
+	}
+	w.Op = DataOp(op[0])
+	w.Type = ValueType(typ)
+	return w, nil
+}
+
+func encodeWrites(writes []Write) []string {
+	var encoded []string
+	for i := range writes {
+		encoded = append(encoded, string(writes[i].encode()))
+	}
+	return encoded
+}
+
+func decodeWrites(encoded []string) ([]Write, error) {
+	var writes []Write
+	for _, data := range encoded {
+		w, err := decodeWrite([]byte(data))
+		if err != nil {
+			return nil, err
+		}
+		writes = append(writes, w)
+	}
+	return writes, nil
+}
+
+// IsBatch 交易是不是批量交易
+func (tx *Transaction) IsBatch() bool {
+	return len(tx.Writes) != 0
+}
+
+// InitBatch 批量交易, 所有写入一起签名
+func (tx *Transaction) InitBatch(writes []Write, possessor string, publicKey []byte, privateKey ecdsa.PrivateKey) {
+	tx.Writes = writes
+	tx.Possessor = possessor
+	tx.TimeStamp = time.Now().Unix()
+	tx.PublicKey = publicKey
+
+	tx.SetTxID()
+	tx.Sign(&privateKey)
+}
+
+// CheckBatch 检查批量交易的写入: 不超过 MaxBatchWrites 个, 表和 key 不为空, 操作正确, 同一个数据只能写入一次。
+// 不是批量交易时返回 nil
+func (tx *Transaction) CheckBatch() error {
+	if !tx.IsBatch() {
+		return nil
+	}
+	if len(tx.DataID) != 0 || tx.Table != "" || tx.Key != "" || tx.Value != "" || tx.Op != OpPut || tx.SchemaVersion != 0 {
+		return errors.New("批量交易的数据信息必须为空")
+	}
+	if len(tx.Writes) > MaxBatchWrites {
+		return fmt.Errorf("批量交易最多 %d 个写入", MaxBatchWrites)
+	}
+	seen := make(map[string]bool)
+	for i, w := range tx.Writes {
+		if w.Table == "" || w.Key == "" {
+			return fmt.Errorf("第 %d 个写入的表或者 key 为空", i)
+		}
+		if w.Op > OpDelete {
+			return fmt.Errorf("第 %d 个写入的操作错误", i)
+		}
+		if w.Op == OpDelete && w.Value != "" {
+			return fmt.Errorf("第 %d 个写入是删除, 不能有值", i)
+		}
+		dataID := string(w.DataID())
+		if seen[dataID] {
+			return fmt.Errorf("key %s 在表 %s 里面写入了多次", w.Key, w.Table)
+		}
+		seen[dataID] = true
+	}
+	return nil
+}
+
+// Expand 交易的每个写入。 普通交易返回它自己;
+// 批量交易的每个写入展开成一个交易, 使用批量交易的 TxID, 所有者, 时间戳, 公钥和签名 (签名只能用批量交易校验)
+func (tx *Transaction) Expand() []*Transaction {
+	if !tx.IsBatch() {
+		return []*Transaction{tx}
+	}
+	expanded := make([]*Transaction, 0, len(tx.Writes))
+	for _, w := range tx.Writes {
+		expanded = append(expanded, &Transaction{
+			TxID:          tx.TxID,
+			DataID:        w.DataID(),
+			Table:         w.Table,
+			Key:           w.Key,
+			Value:         w.Value,
+			Type:          w.Type,
+			Op:            w.Op,
+			SchemaVersion: w.SchemaVersion,
+			Possessor:     tx.Possessor,
+			TimeStamp:     tx.TimeStamp,
+			PublicKey:     tx.PublicKey,
+			Signature:     tx.Signature,
+		})
+	}
+	return expanded
+}
+
+// Lookup 交易里面对数据 dataID 的写入
+func (tx *Transaction) Lookup(dataID string) (*Transaction, bool) {
+	for _, t := range tx.Expand() {
+		if string(t.DataID) == dataID {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Writes 区块里面所有有效交易的写入, 批量交易按顺序展开。
+// 数据索引和世界状态里面的 TxIndex 是写入在这里的序号, 区块里面没有批量交易和无效交易时和交易的序号相同
+func (block *Block) Writes() []*Transaction {
+	var writes []*Transaction
+	for i, tx := range block.Transactions {
+		if !block.IsValid(i) {
+			continue
+		}
+		writes = append(writes, tx.Expand()...)
+	}
+	return writes
+}
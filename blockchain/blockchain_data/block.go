@@ -3,7 +3,6 @@ package blockchain_data
 import (
 	"alg_bcDB/MerkleTree"
 	"alg_bcDB/common"
-	"alg_bcDB/util"
 	"bytes"
 	"crypto/sha256"
 	"encoding/gob"
@@ -20,15 +19,20 @@ type Block struct {
 	Transactions      []*Transaction // Transactions 区块的所有交易
 	TimeStamp         uint64         // TimeStamp 时间戳
 
-	Author common.Address // 区块提议者的地址
-	//AuthorVRF   []byte
-	//AuthorProof []byte
-	Seed  []byte
-	Proof []byte
+	Author      common.Address // 区块提议者的地址
+	AuthorVRF   []byte         // 提议者抽签的 VRF 输出
+	AuthorProof []byte         // 提议者抽签的 VRF 证明, 只同步区块头的轻节点用它校验提议者 (见 algorand.VerifyProposer)
+	Seed        []byte
+	Proof       []byte
 
 	Type      int8   // 区块的类型
 	Signature []byte // 区块的签名
 
+	Invalid []InvalidTx // 提交时校验失败的交易, 提议者按照世界状态计算, 参与区块的 HASH (见 ComputeInvalid)
+
+	// 提议者检查写入权限和 schema 时表区块链的链尾区块, 所有节点都按照这个区块时的权限表和 schema 校验写入 (见 cache.CheckBlockWrites)
+	TableID   uint64
+	TableHash []byte
 }
 
 // NewBlock _NewBlock
@@ -41,17 +45,12 @@ func (block *Block) InitBlock(transactions []*Transaction, previousBlockHash []b
 	block.Round = ID
 	block.PreviousBlockHash = previousBlockHash
 	block.Transactions = transactions
-	var MerKelRootData [][]byte
-	// 类型转换
-	for i := 0; i < len(transactions); i++ {
-		MerKelRootData = append(MerKelRootData, transactionsToBytes(*transactions[i]))
-	}
-	block.MerKelRoot = MerkleTree.GetMerkleRoot(MerKelRootData).Hash
+	block.MerKelRoot = block.ComputeMerkleRoot()
 	block.TimeStamp = uint64(time.Now().Unix())
-	block.SetBlockHash()
 	emptyHash := sha256.Sum256([]byte{})
 	block.Seed = emptyHash[:]
 	block.Author = common.HashToAddr(emptyHash)
+	block.SetBlockHash()
 }
 
 // InitGenesisBlock  _InitGenesisBlock
@@ -59,43 +58,41 @@ func (block *Block) InitGenesisBlock(transactions []*Transaction, previousBlockH
 	block.Round = ID
 	block.PreviousBlockHash = previousBlockHash
 	block.Transactions = transactions
-	var MerKelRootData [][]byte
-	// 类型转换
-	for i := 0; i < len(transactions); i++ {
-		MerKelRootData = append(MerKelRootData, transactionsToBytes(*transactions[i]))
-	}
-	block.MerKelRoot = MerkleTree.GetMerkleRoot(MerKelRootData).Hash
+	block.MerKelRoot = block.ComputeMerkleRoot()
 	block.TimeStamp = uint64(1234567800)
 	block.SetBlockHash()
 }
 
-// TransactionsToBytes 交易的转换为byte
-func transactionsToBytes(transaction Transaction) []byte {
-	var data []byte
-	data = append(data, transaction.TxID...)
-	data = append(data, transaction.DataID...)
-	data = append(data, transaction.Table...)
-	data = append(data, transaction.Key...)
-	data = append(data, transaction.Value...)
-	data = append(data, transaction.Possessor...)
-	data = append(data, util.Int64ToBytes(transaction.TimeStamp)...)
-	data = append(data, transaction.PublicKey...)
-	data = append(data, transaction.Signature...)
-	return data
+// ComputeMerkleRoot 计算区块的默克尔根, 叶子节点为交易的规范编码。 没有交易的区块没有默克尔根
+func (block *Block) ComputeMerkleRoot() []byte {
+	if len(block.Transactions) == 0 {
+		return nil
+	}
+	return block.merkleTree().Hash
 }
 
-// SetBlockHash 计算区块的 HASH
-func (block *Block) SetBlockHash() {
+func (block *Block) merkleTree() *MerkleTree.MerkleTree {
+	var MerKelRootData [][]byte
+	for i := 0; i < len(block.Transactions); i++ {
+		MerKelRootData = append(MerKelRootData, block.Transactions[i].Encode())
+	}
+	return MerkleTree.GetMerkleRoot(MerKelRootData)
+}
 
-	var buffer bytes.Buffer
-	encoder := gob.NewEncoder(&buffer)
+// MerkleProof 第 index 个交易的默克尔证明, 用 MerkleTree.VerifyProof(MerKelRoot, 交易的规范编码, proof) 校验
+func (block *Block) MerkleProof(index int) (*MerkleTree.Proof, error) {
+	return block.merkleTree().GetProof(index)
+}
 
-	err := encoder.Encode(block)
-	if err != nil {
-		log.Panic(err)
-	}
-	blockHash := sha256.Sum256(buffer.Bytes())
-	block.CurrentBlockHash = blockHash[:]
+// ComputeHash 计算区块的 HASH, 即区块头规范编码的 sha256
+func (block *Block) ComputeHash() []byte {
+	blockHash := sha256.Sum256(block.EncodeHeader())
+	return blockHash[:]
+}
+
+// SetBlockHash 计算区块的 HASH。 修改区块头的字段(Round, Seed, Author ...)之后要重新计算
+func (block *Block) SetBlockHash() {
+	block.CurrentBlockHash = block.ComputeHash()
 }
 
 // Serialize 序列化, 将区块转换成字节流
@@ -129,6 +126,36 @@ func Deserialize(data []byte) Block {
 	return block
 }
 
+// Header 区块头, 即不包含交易的区块
+func (block *Block) Header() Block {
+	header := *block
+	header.Transactions = nil
+	return header
+}
+
+// SerializeTransactions 序列化区块体(区块的所有交易)
+func SerializeTransactions(transactions []*Transaction) []byte {
+	var buffer bytes.Buffer
+	encoder := gob.NewEncoder(&buffer)
+
+	err := encoder.Encode(transactions)
+	if err != nil {
+		log.Panic(err)
+	}
+	return buffer.Bytes()
+}
+
+// DeserializeTransactions 反序列化区块体
+func DeserializeTransactions(data []byte) ([]*Transaction, error) {
+	var transactions []*Transaction
+	decoder := gob.NewDecoder(bytes.NewReader(data))
+	err := decoder.Decode(&transactions)
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
 // IsGenesisBlock 判断是否为创世区块
 func (block *Block) IsGenesisBlock() bool {
 	if block.PreviousBlockHash == nil {
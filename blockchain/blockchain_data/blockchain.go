@@ -1,144 +1,355 @@
 package blockchain_data
 
 import (
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/config"
+	"alg_bcDB/genesis"
 	"alg_bcDB/util"
+	"bytes"
+	"encoding/gob"
+	"errors"
 	"fmt"
-	"github.com/boltdb/bolt"
 	"log"
-	"os"
 )
 
 var LocalDataBlockChain *BlockChain
 
 type BlockChain struct {
-	Db       *bolt.DB // Db 本地的boltDB 连接
-	TailHash []byte   // TailHash 区块链最后一个区块的 HASH
+	Store    blockstore.BlockStore // Store 区块链的存储 (boltDB 或者内存)
+	TailHash []byte                // TailHash 区块链最后一个区块的 HASH
 
 	BlockChainFile string
-	BlockBucket    string
-	LastHashKey    string
+	IndexBucket    string // IndexBucket Round -> 区块 HASH 的索引
+	MetaBucket     string // MetaBucket 链的元数据, 和 8 字节 key 的索引分开保存
+	LastIDKey      string // LastIDKey 在 MetaBucket 里面保存 LastID
 	LastID         uint64
 	GenesisBlock   *Block
+
+	Light       bool                                      // Light 轻节点, 只保存区块头, 只能在启动时由 InitLight 设置
+	BodyFetcher func(hash []byte) ([]*Transaction, error) // BodyFetcher 轻节点按需获取区块体
 }
 
+// LightKey 在 MetaBucket 里面标记轻节点的存储 (只保存区块头)
+const LightKey = "lightKey"
+
 // InitBlockChain 如果本地存在区块区块链文件，就更新数据
 // 如果本地不存在区块区块链文件，创建新的区块链。加入genesisBlock.
 func (blockChain *BlockChain) InitBlockChain() {
-	blockChain.BlockChainFile = "dataBlockChain.db"
-	blockChain.BlockBucket = "blockBucket"
-	blockChain.LastHashKey = "lastHashKey"
+	blockChain.BlockChainFile = config.LocalConfig.Path("dataBlockChain.db")
+	if util.IsExistFile(blockChain.BlockChainFile) {
+		fmt.Println("加载区块链文件 ing .")
+	} else {
+		fmt.Println("本地不存在数据区块链文件, 创建区块链文件 ing .")
+	}
+	store, err := blockstore.OpenBolt(blockChain.BlockChainFile)
+	if err != nil {
+		log.Panic(err)
+	}
+	blockChain.InitWithStore(store)
+}
+
+// InitWithStore 使用指定的存储初始化区块链, 存储为空时创建genesisBlock.
+// 单元测试里面可以使用 blockstore.NewMemory(), 不会生成 .db 文件
+func (blockChain *BlockChain) InitWithStore(store blockstore.BlockStore) {
+	blockChain.Store = store
+	blockChain.IndexBucket = "indexBucket"
+	blockChain.MetaBucket = "metaBucket"
+	blockChain.LastIDKey = "lastIDKey"
 	blockChain.LastID = 0
 
-	if !util.IsExistFile(blockChain.BlockChainFile) {
-		fmt.Println("本地不存在数据区块链文件, 创建区块链文件 ing .")
-		// 创建 boltDB
-		db, err := bolt.Open(blockChain.BlockChainFile, 0600, nil)
+	err := store.Update(func(tx blockstore.Tx) error {
+		if tail := tx.Tail(); tail != nil {
+			blockChain.TailHash = append([]byte{}, tail...)
+			return nil
+		}
+		// 创建genesisBlock
+		genesisBlock := NewGenesisBlock()
+		blockChain.GenesisBlock = &genesisBlock
+		// 更新区块链
+		err := putBlock(tx, &genesisBlock)
 		if err != nil {
-			log.Panic(err)
+			return err
 		}
-		// 创建 boltDB.bucket
-		db.Update(func(tx *bolt.Tx) error {
-			bucket, err := tx.CreateBucket([]byte(blockChain.BlockBucket))
-			if err != nil {
-				log.Panic(err)
-			}
-			// 创建genesisBlock
-			// "welcome to 407" 创世区块的HASH
-			genesisBlock := NewBlock()
-			transaction := Transaction{Table: "sample table", Key: "sample key", Value: "sample value"}
-			genesisBlock.InitGenesisBlock([]*Transaction{&transaction}, []byte("welcome to 407"), blockChain.LastID)
-			blockChain.GenesisBlock = &genesisBlock
-			blockChain.LastID++
-			// 更新区块链
-			err = bucket.Put(genesisBlock.CurrentBlockHash, genesisBlock.Serialize())
-			if err != nil {
-				log.Panic(err)
-			}
-			err = bucket.Put([]byte(blockChain.LastHashKey), genesisBlock.CurrentBlockHash)
-			if err != nil {
-				log.Panic(err)
-			}
-
-			// Init blockChain
-			blockChain.Db = db
-			blockChain.TailHash = genesisBlock.CurrentBlockHash
-			return nil
-		})
-	} else {
-		fmt.Println("加载区块链文件 ing .")
-		// 链接 boltDB
-		db, err := bolt.Open(blockChain.BlockChainFile, 0600, nil)
+		err = tx.SetTail(genesisBlock.CurrentBlockHash)
 		if err != nil {
-			log.Panic(err)
-		}
-		// 链接 boltDB.bucket
-		db.View(func(tx *bolt.Tx) error {
-			bucket := tx.Bucket([]byte(blockChain.BlockBucket))
-			if bucket == nil {
-				fmt.Println("BlockBucket is nil")
-				os.Exit(1)
-			}
-
-			// Init blockchain
-			blockChain.Db = db
-			blockChain.TailHash = bucket.Get([]byte(blockChain.LastHashKey))
-			return nil
-		})
+			return err
+		}
+		// 创建索引并写入创世区块
+		if _, err = tx.CreateBucketIfNotExists(blockChain.IndexBucket); err != nil {
+			return err
+		}
+		if _, err = tx.CreateBucketIfNotExists(blockChain.MetaBucket); err != nil {
+			return err
+		}
+		if err = blockChain.putIndex(tx, &genesisBlock); err != nil {
+			return err
+		}
+		if _, err = tx.CreateBucketIfNotExists(KeyIndexBucket); err != nil {
+			return err
+		}
+		// 世界状态从创世区块开始
+		stateBucket, err := tx.CreateBucketIfNotExists(StateBucket)
+		if err != nil {
+			return err
+		}
+		if err = applyState(stateBucket, &genesisBlock); err != nil {
+			return err
+		}
+		blockChain.TailHash = genesisBlock.CurrentBlockHash
+		return nil
+	})
+	if err != nil {
+		log.Panic(err)
 	}
+	// 加载索引, 旧的区块链文件没有索引时重建索引
+	blockChain.loadIndex()
+	blockChain.loadKeyIndex()
+	blockChain.loadState()
+	// 本地的区块链要和创世文件一致, 不同网络的区块链不能混用
+	if genesis.LocalGenesis != nil {
+		genesisBlock := NewGenesisBlock()
+		if !bytes.Equal(blockChain.GetHashByRound(0), genesisBlock.CurrentBlockHash) {
+			log.Panic("本地数据区块链的创世区块和创世文件不一致")
+		}
+	}
+
 	LocalDataBlockChain = blockChain
 	fmt.Println("区块链初始化完成.")
 }
 
-// AddBlockToChain 添加区块到区块链
+// NewGenesisBlock 生成创世区块。
+// 有创世文件时, 创世区块记录网络的 ID, Seed 为创世文件的 HASH; 没有时使用原来的创世区块
+func NewGenesisBlock() Block {
+	// "welcome to 407" 创世区块的前一个区块HASH
+	genesisBlock := NewBlock()
+	g := genesis.LocalGenesis
+	if g == nil {
+		transaction := Transaction{Table: "sample table", Key: "sample key", Value: "sample value"}
+		genesisBlock.InitGenesisBlock([]*Transaction{&transaction}, []byte("welcome to 407"), 0)
+		return genesisBlock
+	}
+	transaction := Transaction{Table: "genesis", Key: "chain_id", Value: g.ChainID, TimeStamp: int64(g.TimeStamp)}
+	genesisBlock.InitGenesisBlock([]*Transaction{&transaction}, []byte("welcome to 407"), 0)
+	genesisBlock.TimeStamp = g.TimeStamp
+	genesisBlock.Seed = g.Hash()
+	genesisBlock.SetBlockHash()
+	return genesisBlock
+}
+
+// AddBlockToChain 添加区块到区块链, 区块没有交易时(轻节点同步的区块头)只保存区块头
 func (blockChain *BlockChain) AddBlockToChain(block Block) {
-	blockChain.Db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(blockChain.BlockBucket))
-		if bucket == nil {
-			fmt.Println("BlockBucket is nil")
-			os.Exit(1)
+	err := blockChain.Store.Update(func(tx blockstore.Tx) error {
+		// 记录区块头里面的无效交易
+		err := validateBlock(tx, &block)
+		if err != nil {
+			return err
 		}
 		// 添加区块并更新信息
-		err := bucket.Put(block.CurrentBlockHash, block.Serialize())
+		err = putBlock(tx, &block)
 		if err != nil {
-			log.Panic(err)
+			return err
 		}
-		err = bucket.Put([]byte(blockChain.LastHashKey), block.CurrentBlockHash)
+		err = tx.SetTail(block.CurrentBlockHash)
 		if err != nil {
-			log.Panic(err)
+			return err
+		}
+		// 在同一个事务里面更新索引
+		err = blockChain.putIndex(tx, &block)
+		if err != nil {
+			return err
+		}
+		// 数据索引也在同一个事务里面更新
+		err = updateKeyIndex(tx, &block)
+		if err != nil {
+			return err
+		}
+		// 世界状态和区块在同一个事务里面提交
+		err = updateState(tx, &block)
+		if err != nil {
+			return err
 		}
 
 		blockChain.TailHash = block.CurrentBlockHash
 		return nil
 	})
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// putBlock 分别保存区块头和区块体
+func putBlock(tx blockstore.Tx, block *Block) error {
+	header := block.Header()
+	err := tx.PutBlock(block.CurrentBlockHash, header.Serialize())
+	if err != nil {
+		return err
+	}
+	if len(block.Transactions) == 0 {
+		return nil
+	}
+	return tx.PutBody(block.CurrentBlockHash, SerializeTransactions(block.Transactions))
+}
+
+// getHeader 读取区块头。 旧的区块链文件区块头和区块体保存在一起, 返回的区块会带有交易
+func getHeader(tx blockstore.Tx, hash []byte) (block Block, err error) {
+	data := tx.GetBlock(hash)
+	if len(data) == 0 {
+		return block, errors.New("not the key")
+	}
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&block)
+	return block, err
+}
+
+// getBlock 读取区块头和区块体, 区块体不存在时返回的区块没有交易
+func getBlock(tx blockstore.Tx, hash []byte) (block Block, hasBody bool, err error) {
+	block, err = getHeader(tx, hash)
+	if err != nil {
+		return block, false, err
+	}
+	if len(block.Transactions) != 0 {
+		return block, true, nil
+	}
+	body := tx.GetBody(hash)
+	if len(body) == 0 {
+		return block, false, nil
+	}
+	block.Transactions, err = DeserializeTransactions(body)
+	if err != nil {
+		return block, false, err
+	}
+	return block, true, nil
+}
+
+// putIndex 写入 Round -> 区块 HASH 的索引, 并更新 MetaBucket 里面持久化的 LastID
+// LastID 始终为最新区块的 Round + 1, 只在这里修改
+func (blockChain *BlockChain) putIndex(tx blockstore.Tx, block *Block) error {
+	indexBucket, metaBucket := tx.Bucket(blockChain.IndexBucket), tx.Bucket(blockChain.MetaBucket)
+	if indexBucket == nil || metaBucket == nil {
+		return errors.New("IndexBucket is nil")
+	}
+	err := indexBucket.Put(util.Uint64ToBytes(block.Round), block.CurrentBlockHash)
+	if err != nil {
+		return err
+	}
+	if block.Round+1 > blockChain.LastID {
+		blockChain.LastID = block.Round + 1
+	}
+	return metaBucket.Put([]byte(blockChain.LastIDKey), util.Uint64ToBytes(blockChain.LastID))
+}
+
+// loadIndex 读取 MetaBucket 里面的 LastID, 如果索引不存在, 从链尾向前遍历一次重建索引。
+// 旧的区块链文件把 LastID 保存在 IndexBucket 里面, 加载时移动到 MetaBucket
+func (blockChain *BlockChain) loadIndex() {
+	err := blockChain.Store.Update(func(tx blockstore.Tx) error {
+		metaBucket, err := tx.CreateBucketIfNotExists(blockChain.MetaBucket)
+		if err != nil {
+			return err
+		}
+		indexBucket := tx.Bucket(blockChain.IndexBucket)
+		if indexBucket != nil {
+			if old := indexBucket.Get([]byte(blockChain.LastIDKey)); old != nil {
+				if err = metaBucket.Put([]byte(blockChain.LastIDKey), old); err != nil {
+					return err
+				}
+				if err = indexBucket.Delete([]byte(blockChain.LastIDKey)); err != nil {
+					return err
+				}
+			}
+			if value := metaBucket.Get([]byte(blockChain.LastIDKey)); value != nil {
+				blockChain.LastID = util.BytesToUint64(value)
+			}
+			return nil
+		}
+
+		fmt.Println("区块链文件没有索引, 重建索引 ing .")
+		if _, err = tx.CreateBucketIfNotExists(blockChain.IndexBucket); err != nil {
+			return err
+		}
+		currentHash := blockChain.TailHash
+		for {
+			bytesBlock := tx.GetBlock(currentHash)
+			if len(bytesBlock) == 0 {
+				break
+			}
+			block := Deserialize(bytesBlock)
+			if err = blockChain.putIndex(tx, &block); err != nil {
+				return err
+			}
+			if bytes.Equal(block.PreviousBlockHash, []byte("welcome to 407")) {
+				break
+			}
+			currentHash = block.PreviousBlockHash
+		}
+		return nil
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// InitLight 以轻节点模式使用区块链, 在启动时由配置决定, 运行时不能切换。
+// 只能使用新的存储 (只有创世区块) 或者已经是轻节点的存储, 全节点的存储不能变成轻节点
+func (blockChain *BlockChain) InitLight(fetcher func(hash []byte) ([]*Transaction, error)) error {
+	err := blockChain.Store.Update(func(tx blockstore.Tx) error {
+		metaBucket := tx.Bucket(blockChain.MetaBucket)
+		if metaBucket == nil {
+			return errors.New("MetaBucket is nil")
+		}
+		if metaBucket.Get([]byte(LightKey)) != nil {
+			return nil
+		}
+		if blockChain.LastID != 1 {
+			return errors.New("全节点的区块链不能切换为轻节点, 轻节点要使用新的数据目录")
+		}
+		return metaBucket.Put([]byte(LightKey), []byte{1})
+	})
+	if err != nil {
+		return err
+	}
+	blockChain.Light = true
+	blockChain.BodyFetcher = fetcher
+	return nil
+}
+
+// IsLightStore 存储是不是轻节点的存储, 轻节点的存储没有区块体, 不能作为全节点使用
+func (blockChain *BlockChain) IsLightStore() bool {
+	light := false
+	blockChain.Store.View(func(tx blockstore.Tx) error {
+		if metaBucket := tx.Bucket(blockChain.MetaBucket); metaBucket != nil {
+			light = metaBucket.Get([]byte(LightKey)) != nil
+		}
+		return nil
+	})
+	return light
 }
 
 // Iterator 区块链迭代器
 type Iterator struct {
-	db          *bolt.DB
-	BlockBucket string
+	chain       *BlockChain
 	CurrentHash []byte
 }
 
 func (blockChain *BlockChain) CreateIterator() Iterator {
-	return Iterator{db: blockChain.Db, CurrentHash: blockChain.TailHash, BlockBucket: blockChain.BlockBucket}
+	return Iterator{chain: blockChain, CurrentHash: blockChain.TailHash}
 }
 
+// Next 返回完整的区块, 轻节点会按需获取区块体
 func (iterator *Iterator) Next() Block {
-	var block Block
-	iterator.db.View(func(tx *bolt.Tx) error {
-		//fmt.Println("迭代器hash", string(iterator.currentHash))
-
-		bucket := tx.Bucket([]byte(iterator.BlockBucket))
-		if bucket == nil {
-			fmt.Println("BlockBucket is nil")
-			os.Exit(1)
-		}
-		// 在boltDB里面得到区块
-		bytesBlock := bucket.Get(iterator.CurrentHash)
-		block = Deserialize(bytesBlock)
-		iterator.CurrentHash = block.PreviousBlockHash
+	//fmt.Println("迭代器hash", string(iterator.currentHash))
+	block, err := iterator.chain.GetBlockByHash(iterator.CurrentHash)
+	if err != nil {
+		log.Panic(err)
+	}
+	iterator.CurrentHash = block.PreviousBlockHash
+	return *block
+}
 
-		return nil
-	})
-	return block
+// NextHeader 只返回区块头, 不读取区块体
+func (iterator *Iterator) NextHeader() Block {
+	block, err := iterator.chain.GetHeaderByHash(iterator.CurrentHash)
+	if err != nil {
+		log.Panic(err)
+	}
+	iterator.CurrentHash = block.PreviousBlockHash
+	return *block
 }
@@ -0,0 +1,79 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/util"
+	"testing"
+)
+
+// indexKeys 索引里面的 key 都是 8 字节的 Round
+func indexKeys(t *testing.T, chain *BlockChain) int {
+	n := 0
+	chain.Store.View(func(tx blockstore.Tx) error {
+		return tx.Bucket(chain.IndexBucket).ForEach(func(k, v []byte) error {
+			if len(k) != 8 {
+				t.Errorf("索引里面有不是 Round 的 key %q", k)
+			}
+			n++
+			return nil
+		})
+	})
+	return n
+}
+
+func TestLastIDInMetaBucket(t *testing.T) {
+	store := blockstore.NewMemory()
+	chain := new(BlockChain)
+	chain.InitWithStore(store)
+	block := NewBlock()
+	block.InitBlock(nil, chain.TailHash, chain.LastID)
+	block.SetBlockHash()
+	chain.AddBlockToChain(block)
+	if chain.LastID != 2 || indexKeys(t, chain) != 2 {
+		t.Fatalf("LastID = %d", chain.LastID)
+	}
+
+	// 旧的区块链文件: LastID 在 IndexBucket 里面, 没有 MetaBucket
+	store.Update(func(tx blockstore.Tx) error {
+		tx.Bucket(chain.IndexBucket).Put([]byte(chain.LastIDKey), util.Uint64ToBytes(2))
+		return tx.DeleteBucket(chain.MetaBucket)
+	})
+	reopened := new(BlockChain)
+	reopened.InitWithStore(store)
+	if reopened.LastID != 2 || indexKeys(t, reopened) != 2 {
+		t.Fatalf("加载旧的区块链文件以后 LastID = %d", reopened.LastID)
+	}
+}
+
+func TestInitLight(t *testing.T) {
+	fetcher := func(hash []byte) ([]*Transaction, error) { return nil, nil }
+
+	// 新的存储可以作为轻节点, 重新打开以后仍然是轻节点
+	store := blockstore.NewMemory()
+	chain := new(BlockChain)
+	chain.InitWithStore(store)
+	if chain.IsLightStore() {
+		t.Fatal("新的存储不是轻节点的存储")
+	}
+	if err := chain.InitLight(fetcher); err != nil || !chain.Light {
+		t.Fatalf("新的存储设置轻节点失败; %v", err)
+	}
+	reopened := new(BlockChain)
+	reopened.InitWithStore(store)
+	if !reopened.IsLightStore() {
+		t.Fatal("重新打开以后不是轻节点的存储")
+	}
+	if err := reopened.InitLight(fetcher); err != nil {
+		t.Fatalf("重新打开轻节点的存储失败; %v", err)
+	}
+
+	// 已经有区块的全节点存储不能变成轻节点
+	full := new(BlockChain)
+	full.InitWithStore(blockstore.NewMemory())
+	block := NewBlock()
+	block.InitBlock(nil, full.TailHash, full.LastID)
+	full.AddBlockToChain(block)
+	if err := full.InitLight(fetcher); err == nil || full.Light || full.IsLightStore() {
+		t.Fatal("全节点的存储变成了轻节点")
+	}
+}
@@ -0,0 +1,96 @@
+package blockchain_data
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 条件写入 (乐观并发控制): 交易带着对 key 当前版本的期望,
+// 区块提交时按照区块里面交易的顺序检查, 条件不成立的交易和读取集过期的交易一样被标记为无效 (见 validateReads),
+// 写入不生效, 不影响区块里面的其他交易。
+// key 的版本是写入它当前的值的交易的 TxID (批量交易是整个批量交易的 TxID)
+
+// Condition 写入的条件
+type Condition uint8
+
+const (
+	CondNone    Condition = iota // 没有条件, 直接覆盖
+	CondVersion                  // key 当前的版本必须是 Expect
+	CondAbsent                   // key 必须不存在 (没有写入过或者已经被删除)
+)
+
+var condNames = []string{"none", "version", "absent"}
+
+func (c Condition) String() string {
+	if int(c) < len(condNames) {
+		return condNames[c]
+	}
+	return fmt.Sprintf("cond(%d)", uint8(c))
+}
+
+// InitConditional 带条件的写入或者删除
+func (tx *Transaction) InitConditional(table, key, value string, op DataOp, cond Condition, expect []byte, possessor string, publicKey []byte, privateKey ecdsa.PrivateKey) {
+	tx.Table = table
+	tx.Key = key
+	tx.Value = value
+	tx.Op = op
+	tx.Cond = cond
+	tx.Expect = expect
+	tx.Possessor = possessor
+	tx.TimeStamp = time.Now().Unix()
+	tx.PublicKey = publicKey
+
+	tx.SetDataID()
+	tx.SetTxID()
+	tx.Sign(&privateKey)
+}
+
+// CheckCondition 检查条件的格式: CondVersion 要有期望的版本, 其他条件没有; 批量交易不能带条件
+func (tx *Transaction) CheckCondition() error {
+	switch tx.Cond {
+	case CondNone, CondAbsent:
+		if len(tx.Expect) != 0 {
+			return fmt.Errorf("条件 %s 不需要期望的版本", tx.Cond)
+		}
+	case CondVersion:
+		if len(tx.Expect) != sha256.Size {
+			return errors.New("期望的版本错误")
+		}
+	default:
+		return errors.New("错误的写入条件")
+	}
+	if tx.Cond != CondNone && tx.IsBatch() {
+		return errors.New("批量交易不能带条件")
+	}
+	return nil
+}
+
+// Holds 条件对 key 当前的值 current 是否成立, key 不存在 (或者已经被删除) 时 current 为 nil
+func (tx *Transaction) Holds(current *Transaction) bool {
+	if current == nil {
+		return tx.HoldsVersion(nil)
+	}
+	return tx.HoldsVersion(current.TxID)
+}
+
+// HoldsVersion 条件对 key 当前的版本 version 是否成立, key 不存在时 version 为 nil
+func (tx *Transaction) HoldsVersion(version []byte) bool {
+	switch tx.Cond {
+	case CondVersion:
+		return version != nil && string(version) == string(tx.Expect)
+	case CondAbsent:
+		return version == nil
+	}
+	return true
+}
+
+// ConflictReason 条件不成立的原因, current 为 key 当前的版本, 不存在时为 nil
+func ConflictReason(table, key string, current []byte) string {
+	if current == nil {
+		return fmt.Sprintf("写入冲突: 表 %s 里面的 key %s 不存在", table, key)
+	}
+	return fmt.Sprintf("写入冲突: 表 %s 里面的 key %s 当前的版本是 %x", table, key, current)
+}
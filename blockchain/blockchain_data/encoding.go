@@ -0,0 +1,178 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/common"
+	"errors"
+)
+
+// 交易编码的字段, tagOp 及以后的字段是后来加入的可选字段 (见 common.Encoder)
+const (
+	tagTxID byte = iota + 1
+	tagDataID
+	tagTable
+	tagKey
+	tagValue
+	tagPossessor
+	tagTxTimeStamp
+	tagPublicKey
+	tagSignature
+	tagOp
+	tagWrites
+	tagCond
+	tagExpect
+	tagReads
+	tagValueType
+	tagSchemaVersion
+)
+
+// 区块头编码的字段
+const (
+	tagRound byte = iota + 1
+	tagPreviousBlockHash
+	tagMerKelRoot
+	tagBlockTimeStamp
+	tagSeed
+	tagAuthor
+	tagProof
+	tagType
+	tagInvalid // 可选字段
+	tagAuthorVRF
+	tagAuthorProof
+	tagTableID
+	tagTableHash
+)
+
+// SigningBytes 交易被签名的内容, 不包含 TxID 和 Signature。 TxID = sha256(SigningBytes)
+func (tx *Transaction) SigningBytes() []byte {
+	e := common.NewEncoder().
+		PutBytes(tagDataID, tx.DataID).
+		PutString(tagTable, tx.Table).
+		PutString(tagKey, tx.Key).
+		PutString(tagValue, tx.Value).
+		PutString(tagPossessor, tx.Possessor).
+		PutInt64(tagTxTimeStamp, tx.TimeStamp).
+		PutBytes(tagPublicKey, tx.PublicKey)
+	return tx.putOptional(e).Encoded()
+}
+
+// Encode 交易的完整编码, 作为默克尔树的叶子节点, 并在 GRPC 里面原样传输
+func (tx *Transaction) Encode() []byte {
+	e := common.NewEncoder().
+		PutBytes(tagTxID, tx.TxID).
+		PutBytes(tagDataID, tx.DataID).
+		PutString(tagTable, tx.Table).
+		PutString(tagKey, tx.Key).
+		PutString(tagValue, tx.Value).
+		PutString(tagPossessor, tx.Possessor).
+		PutInt64(tagTxTimeStamp, tx.TimeStamp).
+		PutBytes(tagPublicKey, tx.PublicKey).
+		PutBytes(tagSignature, tx.Signature)
+	return tx.putOptional(e).Encoded()
+}
+
+// putOptional 写入后来加入的字段, 零值不写入, 普通的写入交易和原来的编码相同
+func (tx *Transaction) putOptional(e *common.Encoder) *common.Encoder {
+	return e.
+		PutOptionalUint64(tagOp, uint64(tx.Op)).
+		PutOptionalStrings(tagWrites, encodeWrites(tx.Writes)).
+		PutOptionalUint64(tagCond, uint64(tx.Cond)).
+		PutOptionalBytes(tagExpect, tx.Expect).
+		PutOptionalStrings(tagReads, encodeReads(tx.Reads)).
+		PutOptionalUint64(tagValueType, uint64(tx.Type)).
+		PutOptionalUint64(tagSchemaVersion, tx.SchemaVersion)
+}
+
+// DecodeTransaction 从 Encode 的结果还原交易, 没有可选字段的旧的编码也可以解码
+func DecodeTransaction(data []byte) (*Transaction, error) {
+	d := common.NewDecoder(data)
+	tx := &Transaction{
+		TxID:      d.Bytes(tagTxID),
+		DataID:    d.Bytes(tagDataID),
+		Table:     d.String(tagTable),
+		Key:       d.String(tagKey),
+		Value:     d.String(tagValue),
+		Possessor: d.String(tagPossessor),
+		TimeStamp: d.Int64(tagTxTimeStamp),
+		PublicKey: d.Bytes(tagPublicKey),
+		Signature: d.Bytes(tagSignature),
+	}
+	op := d.OptionalUint64(tagOp)
+	writes := d.OptionalStrings(tagWrites)
+	cond := d.OptionalUint64(tagCond)
+	tx.Expect = d.OptionalBytes(tagExpect)
+	reads := d.OptionalStrings(tagReads)
+	typ := d.OptionalUint64(tagValueType)
+	tx.SchemaVersion = d.OptionalUint64(tagSchemaVersion)
+	if err := d.Finish(); err != nil {
+		return nil, err
+	}
+	if op > 0xff || cond > 0xff || typ > 0xff {
+		return nil, errors.New("bad data op")
+	}
+	tx.Op = DataOp(op)
+	tx.Type = ValueType(typ)
+	tx.Cond = Condition(cond)
+	var err error
+	if tx.Writes, err = decodeWrites(writes); err != nil {
+		return nil, err
+	}
+	if tx.Reads, err = decodeReads(reads); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// EncodeHeader 区块头的编码, 区块的 HASH = sha256(EncodeHeader)。
+// 交易通过默克尔根包含在区块头里面, 提交时无效的交易, 提议者的抽签证明和表区块链的位置也在区块头里面 (没有时和原来的编码相同),
+// 提议者的签名是对区块 HASH 的签名, 不在区块头里面
+func (block *Block) EncodeHeader() []byte {
+	return common.NewEncoder().
+		PutUint64(tagRound, block.Round).
+		PutBytes(tagPreviousBlockHash, block.PreviousBlockHash).
+		PutBytes(tagMerKelRoot, block.MerKelRoot).
+		PutUint64(tagBlockTimeStamp, block.TimeStamp).
+		PutBytes(tagSeed, block.Seed).
+		PutBytes(tagAuthor, block.Author[:]).
+		PutBytes(tagProof, block.Proof).
+		PutBytes(tagType, []byte{byte(block.Type)}).
+		PutOptionalStrings(tagInvalid, encodeInvalid(block.Invalid)).
+		PutOptionalBytes(tagAuthorVRF, block.AuthorVRF).
+		PutOptionalBytes(tagAuthorProof, block.AuthorProof).
+		PutOptionalUint64(tagTableID, block.TableID).
+		PutOptionalBytes(tagTableHash, block.TableHash).
+		Encoded()
+}
+
+// DecodeHeader 从 EncodeHeader 的结果还原区块头, 区块的 HASH 由区块头重新计算
+func DecodeHeader(data []byte) (*Block, error) {
+	d := common.NewDecoder(data)
+	block := &Block{
+		Round:             d.Uint64(tagRound),
+		PreviousBlockHash: d.Bytes(tagPreviousBlockHash),
+		MerKelRoot:        d.Bytes(tagMerKelRoot),
+		TimeStamp:         d.Uint64(tagBlockTimeStamp),
+		Seed:              d.Bytes(tagSeed),
+	}
+	author := d.Bytes(tagAuthor)
+	block.Proof = d.Bytes(tagProof)
+	typ := d.Bytes(tagType)
+	invalid := d.OptionalStrings(tagInvalid)
+	block.AuthorVRF = d.OptionalBytes(tagAuthorVRF)
+	block.AuthorProof = d.OptionalBytes(tagAuthorProof)
+	block.TableID = d.OptionalUint64(tagTableID)
+	block.TableHash = d.OptionalBytes(tagTableHash)
+	if err := d.Finish(); err != nil {
+		return nil, err
+	}
+	if len(author) != common.AddressLength || len(typ) != 1 {
+		return nil, errors.New("bad block header")
+	}
+	var err error
+	if block.Invalid, err = decodeInvalid(invalid); err != nil {
+		return nil, err
+	}
+	block.Author = common.BytesToAddress(author)
+	block.Type = int8(typ[0])
+	block.SetBlockHash()
+	return block, nil
+}
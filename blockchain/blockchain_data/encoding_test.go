@@ -0,0 +1,130 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/common"
+	"bytes"
+	"crypto/sha256"
+	"reflect"
+	"testing"
+)
+
+// v1Encoding 第一个版本的交易编码, 只有必选字段
+func v1Encoding(tx *Transaction) []byte {
+	return common.NewEncoder().
+		PutBytes(tagTxID, tx.TxID).
+		PutBytes(tagDataID, tx.DataID).
+		PutString(tagTable, tx.Table).
+		PutString(tagKey, tx.Key).
+		PutString(tagValue, tx.Value).
+		PutString(tagPossessor, tx.Possessor).
+		PutInt64(tagTxTimeStamp, tx.TimeStamp).
+		PutBytes(tagPublicKey, tx.PublicKey).
+		PutBytes(tagSignature, tx.Signature).
+		Encoded()
+}
+
+func TestDecodeV1Transaction(t *testing.T) {
+	tx := &Transaction{TxID: []byte{1}, DataID: []byte("t-QAQ-k"), Table: "t", Key: "k", Value: "v",
+		Possessor: "u", TimeStamp: 100, PublicKey: []byte{2}, Signature: []byte{3}}
+	old := v1Encoding(tx)
+	if !bytes.Equal(tx.Encode(), old) {
+		t.Fatal("普通的写入交易的编码和第一个版本不同")
+	}
+	decoded, err := DecodeTransaction(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, tx) {
+		t.Fatalf("decoded = %+v", decoded)
+	}
+}
+
+func TestTransactionRoundTrip(t *testing.T) {
+	version := sha256.Sum256([]byte("v"))
+	txs := []*Transaction{
+		{Table: "t", Key: "k", Op: OpDelete, Cond: CondVersion, Expect: version[:]},
+		{Writes: []Write{{Table: "a", Key: "1", Value: "{}", Type: TypeJSON, SchemaVersion: 2}, {Table: "b", Key: "2", Op: OpDelete}}},
+		{Table: "t", Key: "k", Reads: []Read{{Table: "a", Key: "1", Version: version[:]}, {Table: "b", Key: "2"}}, SchemaVersion: 1},
+	}
+	for i, tx := range txs {
+		decoded, err := DecodeTransaction(tx.Encode())
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if !bytes.Equal(decoded.Encode(), tx.Encode()) || !reflect.DeepEqual(decoded.Writes, tx.Writes) || !reflect.DeepEqual(decoded.Reads, tx.Reads) {
+			t.Errorf("%d: decoded = %+v", i, decoded)
+		}
+	}
+}
+
+func TestDecodeRejectsNonCanonical(t *testing.T) {
+	tx := &Transaction{Table: "t", Key: "k", Op: OpDelete, SchemaVersion: 1}
+	tests := map[string][]byte{
+		// 可选字段写入了零值
+		"零值可选字段": common.NewEncoder().PutBytes(tagTxID, nil).PutBytes(tagDataID, nil).PutString(tagTable, "t").
+			PutString(tagKey, "k").PutString(tagValue, "").PutString(tagPossessor, "").PutInt64(tagTxTimeStamp, 0).
+			PutBytes(tagPublicKey, nil).PutBytes(tagSignature, nil).PutUint64(tagOp, 0).Encoded(),
+		// 可选字段的顺序颠倒
+		"可选字段顺序": append(v1Encoding(tx), append(
+			common.NewEncoder().PutUint64(tagSchemaVersion, 1).Encoded()[1:],
+			common.NewEncoder().PutUint64(tagOp, uint64(OpDelete)).Encoded()[1:]...)...),
+	}
+	for name, data := range tests {
+		if _, err := DecodeTransaction(data); err == nil {
+			t.Errorf("%s: 没有拒绝", name)
+		}
+	}
+}
+
+func TestHeaderCommitsInvalid(t *testing.T) {
+	block := &Block{Round: 3, PreviousBlockHash: []byte{1}, MerKelRoot: []byte{2}, TimeStamp: 100}
+	plain := block.ComputeHash()
+	block.Invalid = []InvalidTx{{Index: 0, Reason: "a"}, {Index: 2, Reason: "b"}}
+	if bytes.Equal(block.ComputeHash(), plain) {
+		t.Fatal("无效交易没有参与区块的 HASH")
+	}
+	header, err := DecodeHeader(block.EncodeHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(header.Invalid, block.Invalid) || header.IsValid(2) || !header.IsValid(1) {
+		t.Fatalf("Invalid = %v", header.Invalid)
+	}
+	// 序号必须从小到大
+	block.Invalid = []InvalidTx{{Index: 2}, {Index: 0}}
+	if _, err = DecodeHeader(block.EncodeHeader()); err == nil {
+		t.Fatal("没有拒绝顺序错误的无效交易")
+	}
+}
+
+func TestHeaderCommitsAuthorProof(t *testing.T) {
+	block := &Block{Round: 3, PreviousBlockHash: []byte{1}, MerKelRoot: []byte{2}, TimeStamp: 100}
+	plain := block.ComputeHash()
+	block.AuthorVRF, block.AuthorProof = []byte{3}, []byte{4, 5}
+	if bytes.Equal(block.ComputeHash(), plain) {
+		t.Fatal("提议者的抽签证明没有参与区块的 HASH")
+	}
+	header, err := DecodeHeader(block.EncodeHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(header.AuthorVRF, block.AuthorVRF) || !bytes.Equal(header.AuthorProof, block.AuthorProof) {
+		t.Fatalf("AuthorVRF = %x, AuthorProof = %x", header.AuthorVRF, header.AuthorProof)
+	}
+}
+
+func TestHeaderCommitsTablePosition(t *testing.T) {
+	block := &Block{Round: 3, PreviousBlockHash: []byte{1}, MerKelRoot: []byte{2}, TimeStamp: 100}
+	plain := block.ComputeHash()
+	block.TableID, block.TableHash = 5, []byte{6}
+	if bytes.Equal(block.ComputeHash(), plain) {
+		t.Fatal("表区块链的位置没有参与区块的 HASH")
+	}
+	header, err := DecodeHeader(block.EncodeHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.TableID != 5 || !bytes.Equal(header.TableHash, block.TableHash) {
+		t.Fatalf("TableID = %d, TableHash = %x", header.TableID, header.TableHash)
+	}
+}
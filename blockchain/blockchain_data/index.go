@@ -0,0 +1,310 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/util"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// 二级索引: 表的 json 值里面声明的字段的值 -> key。
+// 声明的索引来自表区块链上面的索引交易, 由 cache 通过 SetIndexes 同步到 IndexMetaBucket;
+// 索引的内容只由世界状态和声明决定, 数据区块提交时和世界状态在同一个事务里面更新, 可以随时由世界状态重建
+
+const (
+	IndexMetaBucket      = "indexMetaBucket"      // IndexMetaBucket 声明的索引: 表, 字段 -> 1
+	SecondaryIndexBucket = "secondaryIndexBucket" // SecondaryIndexBucket 二级索引: 表, 字段, 字段的值, key -> 1
+)
+
+// ErrNoIndex 表的这个字段没有声明索引
+var ErrNoIndex = errors.New("没有这个索引")
+
+// appendPart 加上长度前缀, 表名, 字段和值里面可以有任意字节
+func appendPart(b []byte, s string) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(s)))
+	return append(append(b, buf[:n]...), s...)
+}
+
+// readPart 读取一个有长度前缀的部分, 返回剩下的字节
+func readPart(b []byte) (string, []byte, bool) {
+	length, n := binary.Uvarint(b)
+	if n <= 0 || length > uint64(len(b)-n) {
+		return "", nil, false
+	}
+	return string(b[n : n+int(length)]), b[n+int(length):], true
+}
+
+func metaKey(table, field string) []byte {
+	return appendPart(appendPart(nil, table), field)
+}
+
+// indexPrefix 表的字段等于 value 的所有索引项的前缀, 后面是 key
+func indexPrefix(table, field, value string) []byte {
+	return appendPart(metaKey(table, field), value)
+}
+
+// indexedValue 数据在索引字段上面的值, 只索引 json 类型的值
+func indexedValue(tx *Transaction, field string) (string, bool) {
+	if tx.Type != TypeJSON || tx.IsDelete() {
+		return "", false
+	}
+	return util.IndexValue([]byte(tx.Value), field)
+}
+
+// metaFields 表声明的索引字段, 按照字段排序
+func metaFields(meta blockstore.Bucket, table string) []string {
+	var fields []string
+	prefix := appendPart(nil, table)
+	c := meta.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		field, rest, ok := readPart(k[len(prefix):])
+		if ok && len(rest) == 0 {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// reindex 数据的值从 old 变为 current 时更新索引, old 和 current 可以为 nil
+func reindex(index blockstore.Bucket, table string, fields []string, old, current *Transaction) error {
+	for _, field := range fields {
+		if old != nil {
+			if v, ok := indexedValue(old, field); ok {
+				if err := index.Delete(append(indexPrefix(table, field, v), old.Key...)); err != nil {
+					return err
+				}
+			}
+		}
+		if current != nil {
+			if v, ok := indexedValue(current, field); ok {
+				if err := index.Put(append(indexPrefix(table, field, v), current.Key...), []byte{1}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// updateIndexes 区块的写入更新二级索引, 在 applyState 之前调用 (世界状态里面还是原来的值)
+func updateIndexes(tx blockstore.Tx, stateBucket blockstore.Bucket, block *Block) error {
+	meta, index := tx.Bucket(IndexMetaBucket), tx.Bucket(SecondaryIndexBucket)
+	if meta == nil || index == nil {
+		return nil
+	}
+	writes := block.Writes()
+	fields := make(map[string][]string)
+	for dataID, i := range latestWrites(writes) {
+		w := writes[i]
+		fs, has := fields[w.Table]
+		if !has {
+			fs = metaFields(meta, w.Table)
+			fields[w.Table] = fs
+		}
+		if len(fs) == 0 {
+			continue
+		}
+		var old *Transaction
+		if data := stateBucket.Get([]byte(dataID)); data != nil {
+			e, err := decodeStateEntry(data)
+			if err != nil {
+				return err
+			}
+			old = &e.Transaction
+		}
+		if err := reindex(index, w.Table, fs, old, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildIndex 由世界状态重新生成表的所有索引项
+func buildIndex(stateBucket, index blockstore.Bucket, table string, fields []string) error {
+	prefix := appendPart(nil, table)
+	var stale [][]byte
+	c := index.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		stale = append(stale, append([]byte{}, k...))
+	}
+	for _, k := range stale {
+		if err := index.Delete(k); err != nil {
+			return err
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	tablePrefix := []byte(table + "-QAQ-")
+	c = stateBucket.Cursor()
+	for k, v := c.Seek(tablePrefix); k != nil && bytes.HasPrefix(k, tablePrefix); k, v = c.Next() {
+		e, err := decodeStateEntry(v)
+		if err != nil {
+			return err
+		}
+		// 前缀下面其他表 (比如 a-QAQ-b) 的数据
+		if e.Table != table {
+			continue
+		}
+		if err = reindex(index, table, fields, nil, &e.Transaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildIndexes 由世界状态重新生成所有声明的索引。 没有世界状态时 (轻节点) 不使用二级索引
+func rebuildIndexes(tx blockstore.Tx) error {
+	if tx.Bucket(SecondaryIndexBucket) != nil {
+		if err := tx.DeleteBucket(SecondaryIndexBucket); err != nil {
+			return err
+		}
+	}
+	stateBucket, meta := tx.Bucket(StateBucket), tx.Bucket(IndexMetaBucket)
+	if stateBucket == nil || meta == nil {
+		return nil
+	}
+	index, err := tx.CreateBucketIfNotExists(SecondaryIndexBucket)
+	if err != nil {
+		return err
+	}
+	tables := make(map[string]bool)
+	err = meta.ForEach(func(k, v []byte) error {
+		if table, _, ok := readPart(k); ok {
+			tables[table] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for table := range tables {
+		if err = buildIndex(stateBucket, index, table, metaFields(meta, table)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetIndexes 设置表声明的索引字段 (替换原来的), 声明改变时由世界状态生成表的索引
+func (blockChain *BlockChain) SetIndexes(table string, fields []string) error {
+	sorted := append([]string{}, fields...)
+	sort.Strings(sorted)
+	return blockChain.Store.Update(func(tx blockstore.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(IndexMetaBucket)
+		if err != nil {
+			return err
+		}
+		current := metaFields(meta, table)
+		if equalStrings(current, sorted) && (len(sorted) == 0 || tx.Bucket(SecondaryIndexBucket) != nil) {
+			return nil
+		}
+		for _, field := range current {
+			if err = meta.Delete(metaKey(table, field)); err != nil {
+				return err
+			}
+		}
+		for _, field := range sorted {
+			if err = meta.Put(metaKey(table, field), []byte{1}); err != nil {
+				return err
+			}
+		}
+		stateBucket := tx.Bucket(StateBucket)
+		if stateBucket == nil {
+			return nil
+		}
+		index, err := tx.CreateBucketIfNotExists(SecondaryIndexBucket)
+		if err != nil {
+			return err
+		}
+		return buildIndex(stateBucket, index, table, sorted)
+	})
+}
+
+// IndexedTables 所有声明了索引的表和它们的索引字段
+func (blockChain *BlockChain) IndexedTables() map[string][]string {
+	tables := make(map[string][]string)
+	blockChain.Store.View(func(tx blockstore.Tx) error {
+		meta := tx.Bucket(IndexMetaBucket)
+		if meta == nil {
+			return nil
+		}
+		return meta.ForEach(func(k, v []byte) error {
+			if table, _, ok := readPart(k); ok && tables[table] == nil {
+				tables[table] = metaFields(meta, table)
+			}
+			return nil
+		})
+	})
+	return tables
+}
+
+// RebuildIndexes 由世界状态重新生成所有的二级索引, 返回索引项的个数
+func (blockChain *BlockChain) RebuildIndexes() (int, error) {
+	count := 0
+	err := blockChain.Store.Update(func(tx blockstore.Tx) error {
+		if tx.Bucket(StateBucket) == nil {
+			return ErrNoState
+		}
+		if err := rebuildIndexes(tx); err != nil {
+			return err
+		}
+		if index := tx.Bucket(SecondaryIndexBucket); index != nil {
+			return index.ForEach(func(k, v []byte) error {
+				count++
+				return nil
+			})
+		}
+		return nil
+	})
+	return count, err
+}
+
+// FindByIndex 通过二级索引查找表里面字段等于 value 的数据, 按照 key 的顺序, limit 小于 0 时返回所有的数据。
+// 世界状态或者索引不可用时返回 ErrNoState, 字段没有声明索引时返回 ErrNoIndex
+func (blockChain *BlockChain) FindByIndex(table, field, value string, limit int) ([]*StateEntry, error) {
+	var entries []*StateEntry
+	err := blockChain.Store.View(func(tx blockstore.Tx) error {
+		stateBucket, index, meta := tx.Bucket(StateBucket), tx.Bucket(SecondaryIndexBucket), tx.Bucket(IndexMetaBucket)
+		if stateBucket == nil || index == nil {
+			return ErrNoState
+		}
+		if meta == nil || meta.Get(metaKey(table, field)) == nil {
+			return ErrNoIndex
+		}
+		prefix := indexPrefix(table, field, value)
+		c := index.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			if limit >= 0 && len(entries) >= limit {
+				break
+			}
+			data := stateBucket.Get([]byte(table + "-QAQ-" + string(k[len(prefix):])))
+			if data == nil {
+				continue
+			}
+			e, err := decodeStateEntry(data)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
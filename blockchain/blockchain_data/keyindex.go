@@ -0,0 +1,209 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/util"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// KeyIndexBucket 数据ID(表+key) -> 最新交易位置 的索引。
+// 添加区块时和区块在同一个事务里面更新, 缓存没有命中时读一次索引, 读一个区块就可以得到数据
+const KeyIndexBucket = "keyIndexBucket"
+
+// KeyLocation 数据最新的交易在区块链里面的位置
+type KeyLocation struct {
+	BlockHash []byte // 交易所在区块的 HASH
+	TxIndex   uint32 // 写入在区块里面的序号 (Block.Writes)
+	Round     uint64 // 交易所在区块的 Round
+}
+
+// encode Round(8) | TxIndex(4) | BlockHash
+func (loc *KeyLocation) encode() []byte {
+	data := make([]byte, 12, 12+len(loc.BlockHash))
+	binary.BigEndian.PutUint64(data[:8], loc.Round)
+	binary.BigEndian.PutUint32(data[8:12], loc.TxIndex)
+	return append(data, loc.BlockHash...)
+}
+
+func decodeKeyLocation(data []byte) (*KeyLocation, error) {
+	if len(data) <= 12 {
+		return nil, errors.New("索引数据错误")
+	}
+	return &KeyLocation{
+		Round:     binary.BigEndian.Uint64(data[:8]),
+		TxIndex:   binary.BigEndian.Uint32(data[8:12]),
+		BlockHash: append([]byte{}, data[12:]...),
+	}, nil
+}
+
+// putKeyIndex 把区块里面每个数据最新的交易写入索引。
+// 同一个区块里面有同一个数据的多个交易时, 取区块里面靠后的 (见 latestWrites)
+func putKeyIndex(bucket blockstore.Bucket, block *Block) error {
+	for dataID, i := range latestWrites(block.Writes()) {
+		loc := KeyLocation{BlockHash: block.CurrentBlockHash, TxIndex: uint32(i), Round: block.Round}
+		if err := bucket.Put([]byte(dataID), loc.encode()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errNoBody 重建数据索引时本地缺少区块体
+var errNoBody = errors.New("本地没有区块体")
+
+// updateKeyIndex 添加区块时更新数据索引。
+// 只有区块头的区块(轻节点同步的)没办法索引, 这时删除数据索引, 之后的查询不再使用数据索引
+func updateKeyIndex(tx blockstore.Tx, block *Block) error {
+	keyBucket := tx.Bucket(KeyIndexBucket)
+	if keyBucket == nil {
+		return nil
+	}
+	if len(block.Transactions) == 0 && block.MerKelRoot != nil {
+		return tx.DeleteBucket(KeyIndexBucket)
+	}
+	return putKeyIndex(keyBucket, block)
+}
+
+// loadKeyIndex 旧的区块链文件没有数据索引时, 按照 Round 从小到大遍历一次重建索引。
+// 本地缺少区块体时(轻节点)不建立数据索引
+func (blockChain *BlockChain) loadKeyIndex() {
+	err := blockChain.Store.Update(func(tx blockstore.Tx) error {
+		if tx.Bucket(KeyIndexBucket) != nil {
+			return nil
+		}
+		fmt.Println("区块链文件没有数据索引, 重建数据索引 ing .")
+		keyBucket, err := tx.CreateBucketIfNotExists(KeyIndexBucket)
+		if err != nil {
+			return err
+		}
+		indexBucket := tx.Bucket(blockChain.IndexBucket)
+		if indexBucket == nil {
+			return errors.New("IndexBucket is nil")
+		}
+		for round := uint64(0); round < blockChain.LastID; round++ {
+			hash := indexBucket.Get(util.Uint64ToBytes(round))
+			if hash == nil {
+				continue
+			}
+			block, hasBody, err := getBlock(tx, hash)
+			if err != nil {
+				return err
+			}
+			if !hasBody && block.MerKelRoot != nil {
+				return errNoBody
+			}
+			if err = putKeyIndex(keyBucket, &block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == errNoBody {
+		fmt.Println("本地缺少区块体, 不使用数据索引")
+		return
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// LocateKey 在数据索引里面查找数据最新的交易的位置, 不存在时返回 err
+func (blockChain *BlockChain) LocateKey(dataID string) (*KeyLocation, error) {
+	var loc *KeyLocation
+	err := blockChain.Store.View(func(tx blockstore.Tx) error {
+		bucket := tx.Bucket(KeyIndexBucket)
+		if bucket == nil {
+			return errors.New("null")
+		}
+		data := bucket.Get([]byte(dataID))
+		if data == nil {
+			return errors.New("null")
+		}
+		var err error
+		loc, err = decodeKeyLocation(data)
+		return err
+	})
+	return loc, err
+}
+
+// GetTransactionByLocation 通过数据索引得到交易, 只读取一个区块
+func (blockChain *BlockChain) GetTransactionByLocation(loc *KeyLocation) (*Transaction, error) {
+	block, err := blockChain.GetBlockByHash(loc.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	writes := block.Writes()
+	if int(loc.TxIndex) >= len(writes) {
+		return nil, errors.New("索引指向的交易不存在")
+	}
+	return writes[loc.TxIndex], nil
+}
+
+// GetLatestTransaction 通过数据索引得到数据最新的交易, 同时返回交易的位置
+func (blockChain *BlockChain) GetLatestTransaction(dataID string) (*Transaction, *KeyLocation, error) {
+	loc, err := blockChain.LocateKey(dataID)
+	if err != nil {
+		return nil, nil, err
+	}
+	tx, err := blockChain.GetTransactionByLocation(loc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(tx.DataID, []byte(dataID)) {
+		return nil, nil, errors.New("索引指向的交易不一致")
+	}
+	return tx, loc, nil
+}
+
+// KeyEntry 范围查询得到的 key 和它最新的交易的位置
+type KeyEntry struct {
+	Key      string
+	Location *KeyLocation
+}
+
+// ScanKeys 在数据索引里面按照字节序遍历表的 key。
+// 返回 [from, to) 里面有前缀 prefix 的 key, to 为空时到表的末尾。
+// 最多返回 limit 个, 后面还有 key 时返回下一个 key, 作为下一次查询的 from。
+// 表 a 的前缀下面也有表 a-QAQ-b 的数据, key 里面有 -QAQ- 的不是这个表的数据 (见 CheckDataKey)
+func (blockChain *BlockChain) ScanKeys(table, from, to, prefix string, limit int) ([]KeyEntry, string, error) {
+	tablePrefix := []byte(table + "-QAQ-")
+	keyPrefix := append(append([]byte{}, tablePrefix...), prefix...)
+	start := append(append([]byte{}, tablePrefix...), from...)
+	if bytes.Compare(keyPrefix, start) > 0 {
+		start = keyPrefix
+	}
+	var entries []KeyEntry
+	next := ""
+	err := blockChain.Store.View(func(tx blockstore.Tx) error {
+		bucket := tx.Bucket(KeyIndexBucket)
+		if bucket == nil {
+			return errors.New("null")
+		}
+		c := bucket.Cursor()
+		for k, v := c.Seek(start); k != nil && bytes.HasPrefix(k, keyPrefix); k, v = c.Next() {
+			key := string(k[len(tablePrefix):])
+			if to != "" && key >= to {
+				break
+			}
+			if strings.Contains(key, "-QAQ-") {
+				continue
+			}
+			if len(entries) >= limit {
+				next = key
+				break
+			}
+			loc, err := decodeKeyLocation(v)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, KeyEntry{Key: key, Location: loc})
+		}
+		return nil
+	})
+	return entries, next, err
+}
@@ -0,0 +1,101 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/MerkleTree"
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// TxProof 交易的存在性证明: 交易, 包含交易的区块头, 交易在区块里面的默克尔证明。
+// 客户端不需要信任返回证明的节点, 但是要从可信的来源知道区块的 HASH (比如轻节点同步并校验过的区块头),
+// 用 VerifyTrusted 校验交易确实写入了这个区块
+type TxProof struct {
+	Transaction *Transaction
+	Header      *Block // 区块头, 没有交易, 有提议者的签名
+	Proof       *MerkleTree.Proof
+	Write       *Transaction // 查询的数据在交易里面的写入, 批量交易时是其中一个写入, 由 Transaction 得到, 不参与校验
+}
+
+// ProveTransaction 生成区块里面指定交易的存在性证明
+func (blockChain *BlockChain) ProveTransaction(blockHash, txID []byte) (*TxProof, error) {
+	block, err := blockChain.GetBlockByHash(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	for i, tx := range block.Transactions {
+		if !bytes.Equal(tx.TxID, txID) {
+			continue
+		}
+		proof, err := block.MerkleProof(i)
+		if err != nil {
+			return nil, err
+		}
+		header := block.Header()
+		return &TxProof{Transaction: tx, Header: &header, Proof: proof}, nil
+	}
+	return nil, errors.New("区块里面没有这个交易")
+}
+
+// Verify 校验交易的存在性证明:
+// 区块头的 HASH, 提议者的签名, 交易在默克尔根下的证明, 交易的签名, 交易在提交时有效 (不在区块头的无效交易里面)。
+// 只说明证明自身是一致的: 签名只说明区块头是 Author 签名的, 任何节点都可以自己生成区块并签名,
+// 没有和可信的区块 HASH 比较的证明不可信 (见 VerifyTrusted)
+func (p *TxProof) Verify() error {
+	if p.Transaction == nil || p.Header == nil || p.Proof == nil {
+		return errors.New("证明不完整")
+	}
+	if !bytes.Equal(p.Header.ComputeHash(), p.Header.CurrentBlockHash) {
+		return errors.New("区块头的HASH错误")
+	}
+	// 创世区块没有提议者
+	if p.Header.Round != 0 {
+		if err := p.Header.VerifySignature(); err != nil {
+			return err
+		}
+	}
+	if !MerkleTree.VerifyProof(p.Header.MerKelRoot, p.Transaction.Encode(), p.Proof) {
+		return errors.New("默克尔证明错误")
+	}
+	if p.Header.Round != 0 && !VerifyTransaction(*p.Transaction) {
+		return fmt.Errorf("交易 %x 签名错误", p.Transaction.TxID)
+	}
+	if !p.Header.IsValid(int(p.Proof.Index)) {
+		return fmt.Errorf("交易 %x 在提交时被标记为无效", p.Transaction.TxID)
+	}
+	return nil
+}
+
+// VerifyTrusted 校验交易的存在性证明, 并且证明里面的区块头就是可信的区块 trustedHash
+func (p *TxProof) VerifyTrusted(trustedHash []byte) error {
+	if len(trustedHash) == 0 {
+		return errors.New("没有可信的区块HASH, 证明不可信")
+	}
+	if err := p.Verify(); err != nil {
+		return err
+	}
+	if !bytes.Equal(p.Header.CurrentBlockHash, trustedHash) {
+		return errors.New("区块头和可信的区块HASH不一致")
+	}
+	return nil
+}
+
+// VerifyTxProof 由规范编码校验交易的存在性证明, 客户端收到 GRPC/HTTP 的结果后使用。
+// trustedHash 为客户端从可信的来源得到的区块 HASH (比如轻节点同步的区块头), 不能使用证明里面的 HASH。
+// 返回还原的交易和区块头
+func VerifyTxProof(trustedHash, encodedTx, encodedHeader, signature []byte, proof *MerkleTree.Proof) (*Transaction, *Block, error) {
+	tx, err := DecodeTransaction(encodedTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	header, err := DecodeHeader(encodedHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+	header.Signature = signature
+	p := &TxProof{Transaction: tx, Header: header, Proof: proof}
+	if err := p.VerifyTrusted(trustedHash); err != nil {
+		return nil, nil, err
+	}
+	return tx, header, nil
+}
@@ -0,0 +1,38 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/blockchain/blockstore"
+	"testing"
+)
+
+func TestVerifyTrusted(t *testing.T) {
+	chain := new(BlockChain)
+	chain.InitWithStore(blockstore.NewMemory())
+	txs := []*Transaction{{TxID: []byte{1}, Table: "t", Key: "a"}, {TxID: []byte{2}, Table: "t", Key: "b"}}
+	for _, tx := range txs {
+		tx.SetDataID()
+	}
+	// Round 0 的区块没有提议者和交易签名, 只校验 HASH 和默克尔证明
+	block := NewBlock()
+	block.InitBlock(txs, []byte("welcome to 407"), 0)
+	proof, err := block.MerkleProof(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := block.Header()
+	p := &TxProof{Transaction: txs[1], Header: &header, Proof: proof}
+
+	if err := p.VerifyTrusted(block.CurrentBlockHash); err != nil {
+		t.Fatalf("可信的区块HASH校验失败; %v", err)
+	}
+	// 自身一致的证明, 没有可信的区块HASH, 或者不是可信的区块
+	if err := p.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.VerifyTrusted(nil); err == nil {
+		t.Fatal("没有可信的区块HASH的证明通过了校验")
+	}
+	if err := p.VerifyTrusted(chain.TailHash); err == nil {
+		t.Fatal("其他区块的证明通过了校验")
+	}
+}
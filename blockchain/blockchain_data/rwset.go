@@ -0,0 +1,330 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/common"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// 读写集交易 (MVCC): 交易记录它读取的每个 key 的版本, 和它写入的 key。
+// 区块提交时按照区块里面交易的顺序校验读取的版本, 有一个读取在之前的区块或者同一个区块前面的交易里面被修改了,
+// 交易就是无效的。无效的交易留在区块里面 (参与默克尔根), 但是不写入数据索引, 世界状态和缓存。
+// 提议者用自己的世界状态计算无效的交易, 写入区块头 (参与区块的 HASH); 全节点校验区块时重新计算并比较,
+// 轻节点和证明的校验者直接由区块头得到交易是否有效
+
+// InvalidTxBucket 无效交易的记录: TxID -> 交易所在的区块和无效的原因
+const InvalidTxBucket = "invalidTxBucket"
+
+// 读取的编码字段
+const (
+	tagReadTable byte = iota + 1
+	tagReadKey
+	tagReadVersion
+)
+
+// 区块头里面无效交易的编码字段
+const (
+	tagInvalidTxIndex byte = iota + 1
+	tagInvalidTxReason
+)
+
+// 无效交易记录的编码字段
+const (
+	tagInvalidRound byte = iota + 1
+	tagInvalidBlockHash
+	tagInvalidIndex
+	tagInvalidReason
+)
+
+// MaxReads 一个交易最多读取的 key 的个数
+const MaxReads = 1024
+
+// Read 交易读取的 key 和读取时的版本 (写入它的交易的 TxID), 读取时 key 不存在则 Version 为空
+type Read struct {
+	Table   string
+	Key     string
+	Version []byte
+}
+
+// DataID 读取的数据ID
+func (r *Read) DataID() []byte {
+	return []byte(r.Table + "-QAQ-" + r.Key)
+}
+
+func (r *Read) encode() []byte {
+	return common.NewEncoder().
+		PutString(tagReadTable, r.Table).
+		PutString(tagReadKey, r.Key).
+		PutBytes(tagReadVersion, r.Version).
+		Encoded()
+}
+
+func encodeReads(reads []Read) []string {
+	var encoded []string
+	for i := range reads {
+		encoded = append(encoded, string(reads[i].encode()))
+	}
+	return encoded
+}
+
+func decodeReads(encoded []string) ([]Read, error) {
+	var reads []Read
+	for _, data := range encoded {
+		d := common.NewDecoder([]byte(data))
+		r := Read{
+			Table:   d.String(tagReadTable),
+			Key:     d.String(tagReadKey),
+			Version: d.Bytes(tagReadVersion),
+		}
+		if err := d.Finish(); err != nil {
+			return nil, err
+		}
+		reads = append(reads, r)
+	}
+	return reads, nil
+}
+
+// InitReadWrite 读写集交易: 读取的 key 的版本和所有写入一起签名
+func (tx *Transaction) InitReadWrite(reads []Read, writes []Write, possessor string, publicKey []byte, privateKey ecdsa.PrivateKey) {
+	tx.Reads = reads
+	tx.Writes = writes
+	tx.Possessor = possessor
+	tx.TimeStamp = time.Now().Unix()
+	tx.PublicKey = publicKey
+
+	tx.SetTxID()
+	tx.Sign(&privateKey)
+}
+
+// CheckReads 检查读取集的格式: 不超过 MaxReads 个, 表和 key 不为空, 版本为空或者是 TxID, 同一个 key 只读取一次
+func (tx *Transaction) CheckReads() error {
+	if len(tx.Reads) > MaxReads {
+		return fmt.Errorf("交易最多读取 %d 个 key", MaxReads)
+	}
+	seen := make(map[string]bool)
+	for i, r := range tx.Reads {
+		if r.Table == "" || r.Key == "" {
+			return fmt.Errorf("第 %d 个读取的表或者 key 为空", i)
+		}
+		if len(r.Version) != 0 && len(r.Version) != sha256.Size {
+			return fmt.Errorf("第 %d 个读取的版本错误", i)
+		}
+		dataID := string(r.DataID())
+		if seen[dataID] {
+			return fmt.Errorf("key %s 在表 %s 里面读取了多次", r.Key, r.Table)
+		}
+		seen[dataID] = true
+	}
+	return nil
+}
+
+// StaleRead 交易的读取集里面第一个版本和 current 不一致的读取, 都一致时返回 nil。
+// current 返回 key 当前的版本, key 不存在时返回 nil
+func (tx *Transaction) StaleRead(current func(dataID string) []byte) *Read {
+	for i := range tx.Reads {
+		if !bytes.Equal(current(string(tx.Reads[i].DataID())), tx.Reads[i].Version) {
+			return &tx.Reads[i]
+		}
+	}
+	return nil
+}
+
+// InvalidTx 提交时校验失败的交易在区块里面的序号和原因
+type InvalidTx struct {
+	Index  uint32
+	Reason string
+}
+
+func encodeInvalid(invalid []InvalidTx) []string {
+	var encoded []string
+	for _, tx := range invalid {
+		encoded = append(encoded, string(common.NewEncoder().
+			PutUint64(tagInvalidTxIndex, uint64(tx.Index)).
+			PutString(tagInvalidTxReason, tx.Reason).
+			Encoded()))
+	}
+	return encoded
+}
+
+// decodeInvalid 还原区块头里面的无效交易, 序号必须从小到大并且不重复
+func decodeInvalid(encoded []string) ([]InvalidTx, error) {
+	var invalid []InvalidTx
+	for _, data := range encoded {
+		d := common.NewDecoder([]byte(data))
+		index := d.Uint64(tagInvalidTxIndex)
+		reason := d.String(tagInvalidTxReason)
+		if err := d.Finish(); err != nil {
+			return nil, err
+		}
+		if index > math.MaxUint32 || (len(invalid) != 0 && uint64(invalid[len(invalid)-1].Index) >= index) {
+			return nil, errors.New("bad invalid tx index")
+		}
+		invalid = append(invalid, InvalidTx{Index: uint32(index), Reason: reason})
+	}
+	return invalid, nil
+}
+
+// IsValid 区块里面第 i 个交易是否有效
+func (block *Block) IsValid(i int) bool {
+	for _, invalid := range block.Invalid {
+		if int(invalid.Index) == i {
+			return false
+		}
+	}
+	return true
+}
+
+// validateReads 使用世界状态 stateBucket 校验区块里面每个交易的读取集和写入条件, 返回无效的交易。
+// 有效交易的写入对同一个区块里面后面的交易可见, 无效交易的写入不可见
+func validateReads(stateBucket blockstore.Bucket, block *Block) []InvalidTx {
+	// 数据ID -> 同一个区块里面前面的有效交易写入后的版本, 删除时为 nil
+	written := make(map[string][]byte)
+	current := func(dataID string) []byte {
+		if version, has := written[dataID]; has {
+			return version
+		}
+		data := stateBucket.Get([]byte(dataID))
+		if data == nil {
+			return nil
+		}
+		e, err := decodeStateEntry(data)
+		if err != nil {
+			return nil
+		}
+		return e.TxID
+	}
+	var invalid []InvalidTx
+	for i, tx := range block.Transactions {
+		if r := tx.StaleRead(current); r != nil {
+			invalid = append(invalid, InvalidTx{Index: uint32(i), Reason: fmt.Sprintf("表 %s 里面的 key %s 读取的版本过期", r.Table, r.Key)})
+			continue
+		}
+		// 条件写入, 批量交易没有条件
+		if version := current(string(tx.DataID)); !tx.HoldsVersion(version) {
+			invalid = append(invalid, InvalidTx{Index: uint32(i), Reason: ConflictReason(tx.Table, tx.Key, version)})
+			continue
+		}
+		for _, w := range tx.Expand() {
+			if w.IsDelete() {
+				written[string(w.DataID)] = nil
+			} else {
+				written[string(w.DataID)] = tx.TxID
+			}
+		}
+	}
+	return invalid
+}
+
+// InvalidRecord 无效交易的记录
+type InvalidRecord struct {
+	Round     uint64
+	BlockHash []byte
+	Index     uint32
+	Reason    string
+}
+
+func (r *InvalidRecord) encode() []byte {
+	return common.NewEncoder().
+		PutUint64(tagInvalidRound, r.Round).
+		PutBytes(tagInvalidBlockHash, r.BlockHash).
+		PutUint64(tagInvalidIndex, uint64(r.Index)).
+		PutString(tagInvalidReason, r.Reason).
+		Encoded()
+}
+
+func decodeInvalidRecord(data []byte) (*InvalidRecord, error) {
+	d := common.NewDecoder(data)
+	r := &InvalidRecord{
+		Round:     d.Uint64(tagInvalidRound),
+		BlockHash: d.Bytes(tagInvalidBlockHash),
+		Index:     uint32(d.Uint64(tagInvalidIndex)),
+		Reason:    d.String(tagInvalidReason),
+	}
+	return r, d.Finish()
+}
+
+// ComputeInvalid 提议区块时使用本地的世界状态计算区块里面无效的交易, 区块要接在本地的链尾。
+// 本地没有世界状态时返回 ErrNoState
+func (blockChain *BlockChain) ComputeInvalid(block *Block) ([]InvalidTx, error) {
+	var invalid []InvalidTx
+	err := blockChain.Store.View(func(tx blockstore.Tx) error {
+		stateBucket := tx.Bucket(StateBucket)
+		if stateBucket == nil {
+			return ErrNoState
+		}
+		invalid = validateReads(stateBucket, block)
+		return nil
+	})
+	return invalid, err
+}
+
+// CheckInvalid 校验区块头里面的无效交易: 序号在区块的交易里面;
+// 区块接在本地的链尾并且有世界状态时, 要和本地重新计算的结果相同
+func (blockChain *BlockChain) CheckInvalid(block *Block) error {
+	for _, invalid := range block.Invalid {
+		if int(invalid.Index) >= len(block.Transactions) {
+			return fmt.Errorf("无效交易的序号 %d 错误", invalid.Index)
+		}
+	}
+	if !bytes.Equal(block.PreviousBlockHash, blockChain.TailHash) {
+		return nil
+	}
+	invalid, err := blockChain.ComputeInvalid(block)
+	if err == ErrNoState {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(encodeInvalid(invalid), encodeInvalid(block.Invalid)) {
+		return errors.New("无效交易和本地计算的不一致")
+	}
+	return nil
+}
+
+// validateBlock 添加区块时记录区块头里面的无效交易 (区块校验时已经检查过)。
+// 只有区块头的区块不记录
+func validateBlock(tx blockstore.Tx, block *Block) error {
+	if len(block.Invalid) == 0 || len(block.Transactions) == 0 {
+		return nil
+	}
+	bucket, err := tx.CreateBucketIfNotExists(InvalidTxBucket)
+	if err != nil {
+		return err
+	}
+	for _, invalid := range block.Invalid {
+		txID := block.Transactions[invalid.Index].TxID
+		fmt.Printf("交易 %x 无效: %s\n", txID, invalid.Reason)
+		r := InvalidRecord{Round: block.Round, BlockHash: block.CurrentBlockHash, Index: invalid.Index, Reason: invalid.Reason}
+		if err = bucket.Put(txID, r.encode()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetInvalidRecord 查询交易是否在提交时被标记为无效, 没有记录时返回 err
+func (blockChain *BlockChain) GetInvalidRecord(txID []byte) (*InvalidRecord, error) {
+	var r *InvalidRecord
+	err := blockChain.Store.View(func(tx blockstore.Tx) error {
+		bucket := tx.Bucket(InvalidTxBucket)
+		if bucket == nil {
+			return errors.New("null")
+		}
+		data := bucket.Get(txID)
+		if data == nil {
+			return errors.New("null")
+		}
+		var err error
+		r, err = decodeInvalidRecord(data)
+		return err
+	})
+	return r, err
+}
@@ -0,0 +1,50 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/blockchain/blockstore"
+	"testing"
+)
+
+func TestValidateReadsConditions(t *testing.T) {
+	store := blockstore.NewMemory()
+	err := store.Update(func(tx blockstore.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists("state")
+		if err != nil {
+			return err
+		}
+		e := &StateEntry{Transaction: Transaction{TxID: []byte("v1"), DataID: []byte("t-QAQ-a"), Table: "t", Key: "a"}}
+		return bucket.Put([]byte("t-QAQ-a"), e.encode())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := &Block{Transactions: []*Transaction{
+		{TxID: []byte("v2"), DataID: []byte("t-QAQ-a"), Table: "t", Key: "a", Cond: CondVersion, Expect: []byte("v1")},
+		// 版本已经被前面的交易修改
+		{TxID: []byte("v3"), DataID: []byte("t-QAQ-a"), Table: "t", Key: "a", Cond: CondVersion, Expect: []byte("v1")},
+		// key 已经存在
+		{TxID: []byte("v4"), DataID: []byte("t-QAQ-a"), Table: "t", Key: "a", Cond: CondAbsent},
+		{TxID: []byte("v5"), DataID: []byte("t-QAQ-b"), Table: "t", Key: "b", Cond: CondAbsent},
+		// 前面的交易创建了 key
+		{TxID: []byte("v6"), DataID: []byte("t-QAQ-b"), Table: "t", Key: "b", Cond: CondAbsent},
+		{TxID: []byte("v7"), DataID: []byte("t-QAQ-b"), Table: "t", Key: "b", Cond: CondVersion, Expect: []byte("v5")},
+	}}
+	var invalid []InvalidTx
+	err = store.View(func(tx blockstore.Tx) error {
+		invalid = validateReads(tx.Bucket("state"), block)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint32{1, 2, 4}
+	if len(invalid) != len(want) {
+		t.Fatalf("invalid = %v", invalid)
+	}
+	for i, index := range want {
+		if invalid[i].Index != index || invalid[i].Reason == "" {
+			t.Errorf("invalid[%d] = %v, 期望序号 %d", i, invalid[i], index)
+		}
+	}
+}
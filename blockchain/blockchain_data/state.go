@@ -0,0 +1,337 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/common"
+	"alg_bcDB/util"
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// StateBucket 世界状态: 数据ID(表+key) -> 数据当前的值。
+// 每个数据区块提交时在同一个事务里面按确定的顺序更新, 区块链只作为审计日志。
+// 数据ID 以表名开头, 同一个表的数据在 bucket 里面是连续的, 按 key 的字节序排列
+const StateBucket = "stateBucket"
+
+// 世界状态编码的字段
+const (
+	tagStateRound byte = iota + 1
+	tagStateTxIndex
+	tagStateBlockHash
+	tagStateTx
+)
+
+// StateEntry 数据当前的值, 以及写入这个值的交易和区块 (版本)
+type StateEntry struct {
+	Transaction
+	Round     uint64 // 版本: 交易所在区块的 Round
+	TxIndex   uint32 // 写入在区块里面的序号 (Block.Writes)
+	BlockHash []byte // 交易所在区块的 HASH
+}
+
+// Location 值所在的位置, 可以用来生成证明
+func (e *StateEntry) Location() *KeyLocation {
+	return &KeyLocation{BlockHash: e.BlockHash, TxIndex: e.TxIndex, Round: e.Round}
+}
+
+func (e *StateEntry) encode() []byte {
+	return common.NewEncoder().
+		PutUint64(tagStateRound, e.Round).
+		PutUint64(tagStateTxIndex, uint64(e.TxIndex)).
+		PutBytes(tagStateBlockHash, e.BlockHash).
+		PutBytes(tagStateTx, e.Transaction.Encode()).
+		Encoded()
+}
+
+func decodeStateEntry(data []byte) (*StateEntry, error) {
+	d := common.NewDecoder(data)
+	e := &StateEntry{
+		Round:     d.Uint64(tagStateRound),
+		TxIndex:   uint32(d.Uint64(tagStateTxIndex)),
+		BlockHash: d.Bytes(tagStateBlockHash),
+	}
+	txData := d.Bytes(tagStateTx)
+	if err := d.Finish(); err != nil {
+		return nil, err
+	}
+	tx, err := DecodeTransaction(txData)
+	if err != nil {
+		return nil, err
+	}
+	e.Transaction = *tx
+	return e, nil
+}
+
+// applyState 把区块里面每个数据最新的交易写入世界状态 (最新的交易是墓碑时删除), 规则和数据索引相同:
+// 同一个区块里面有同一个数据的多个交易时, 取区块里面靠后的 (和提交时校验读取集的顺序一致)
+func applyState(bucket blockstore.Bucket, block *Block) error {
+	writes := block.Writes()
+	for dataID, i := range latestWrites(writes) {
+		// 墓碑: 删除以后世界状态里面没有这个 key
+		if writes[i].IsDelete() {
+			if err := bucket.Delete([]byte(dataID)); err != nil {
+				return err
+			}
+			continue
+		}
+		e := StateEntry{Transaction: *writes[i], Round: block.Round, TxIndex: uint32(i), BlockHash: block.CurrentBlockHash}
+		if err := bucket.Put([]byte(dataID), e.encode()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// latestWrites 每个数据最新的写入在 writes 里面的序号, 按照区块里面的顺序, 靠后的是最新的。
+// 不比较交易的时间戳, 时间戳由客户端设置
+func latestWrites(writes []*Transaction) map[string]int {
+	latest := make(map[string]int)
+	for i, tx := range writes {
+		if len(tx.DataID) == 0 {
+			continue
+		}
+		latest[string(tx.DataID)] = i
+	}
+	return latest
+}
+
+// updateState 添加区块时更新世界状态和二级索引。
+// 只有区块头的区块(轻节点同步的)没办法更新, 这时删除世界状态, 之后的查询使用原来的方式
+func updateState(tx blockstore.Tx, block *Block) error {
+	stateBucket := tx.Bucket(StateBucket)
+	if stateBucket == nil {
+		return nil
+	}
+	if len(block.Transactions) == 0 && block.MerKelRoot != nil {
+		if tx.Bucket(SecondaryIndexBucket) != nil {
+			if err := tx.DeleteBucket(SecondaryIndexBucket); err != nil {
+				return err
+			}
+		}
+		return tx.DeleteBucket(StateBucket)
+	}
+	if err := updateIndexes(tx, stateBucket, block); err != nil {
+		return err
+	}
+	return applyState(stateBucket, block)
+}
+
+// replayState 从创世区块开始按照 Round 重放所有区块, 返回 数据ID -> 世界状态的编码
+func (blockChain *BlockChain) replayState(tx blockstore.Tx) (map[string][]byte, error) {
+	state := blockstore.NewMemory()
+	err := state.Update(func(stx blockstore.Tx) error {
+		bucket, err := stx.CreateBucketIfNotExists(StateBucket)
+		if err != nil {
+			return err
+		}
+		indexBucket := tx.Bucket(blockChain.IndexBucket)
+		if indexBucket == nil {
+			return errors.New("IndexBucket is nil")
+		}
+		for round := uint64(0); round < blockChain.LastID; round++ {
+			hash := indexBucket.Get(util.Uint64ToBytes(round))
+			if hash == nil {
+				continue
+			}
+			block, hasBody, err := getBlock(tx, hash)
+			if err != nil {
+				return err
+			}
+			if !hasBody && block.MerKelRoot != nil {
+				return errNoBody
+			}
+			// 无效的交易在区块头里面
+			if err = applyState(bucket, &block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string][]byte)
+	err = state.View(func(stx blockstore.Tx) error {
+		return stx.Bucket(StateBucket).ForEach(func(k, v []byte) error {
+			entries[string(k)] = append([]byte{}, v...)
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// writeState 用 entries 替换世界状态
+func writeState(tx blockstore.Tx, entries map[string][]byte) error {
+	if tx.Bucket(StateBucket) != nil {
+		if err := tx.DeleteBucket(StateBucket); err != nil {
+			return err
+		}
+	}
+	bucket, err := tx.CreateBucketIfNotExists(StateBucket)
+	if err != nil {
+		return err
+	}
+	for dataID, data := range entries {
+		if err = bucket.Put([]byte(dataID), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadState 旧的区块链文件没有世界状态时, 重放一次区块链生成世界状态, 二级索引也一起生成。
+// 本地缺少区块体时(轻节点)不使用世界状态
+func (blockChain *BlockChain) loadState() {
+	err := blockChain.Store.Update(func(tx blockstore.Tx) error {
+		if tx.Bucket(StateBucket) != nil {
+			if tx.Bucket(IndexMetaBucket) != nil && tx.Bucket(SecondaryIndexBucket) == nil {
+				return rebuildIndexes(tx)
+			}
+			return nil
+		}
+		fmt.Println("区块链文件没有世界状态, 重建世界状态 ing .")
+		entries, err := blockChain.replayState(tx)
+		if err != nil {
+			return err
+		}
+		if err = writeState(tx, entries); err != nil {
+			return err
+		}
+		return rebuildIndexes(tx)
+	})
+	if err == errNoBody {
+		fmt.Println("本地缺少区块体, 不使用世界状态")
+		return
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// ErrNoState 本地没有世界状态 (轻节点), 需要使用原来的方式查询
+var ErrNoState = errors.New("世界状态不可用")
+
+// StateDiff 重建的世界状态和原来的世界状态的差别, 都是数据ID
+type StateDiff struct {
+	Entries int      // 重建后的数据个数
+	Missing []string // 原来没有的数据
+	Extra   []string // 原来多出来的数据
+	Changed []string // 值或者版本不一致的数据
+}
+
+// Consistent 原来的世界状态和重建的一致
+func (d *StateDiff) Consistent() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 && len(d.Changed) == 0
+}
+
+// RebuildState 从创世区块开始重放区块链重新生成世界状态, 和原来的世界状态比较后替换, 并重新生成二级索引。
+// 原来没有世界状态时(轻节点后来补齐了区块体)所有数据都算作 Missing
+func (blockChain *BlockChain) RebuildState() (*StateDiff, error) {
+	diff := &StateDiff{}
+	err := blockChain.Store.Update(func(tx blockstore.Tx) error {
+		entries, err := blockChain.replayState(tx)
+		if err != nil {
+			return err
+		}
+		diff.Entries = len(entries)
+		seen := make(map[string]bool)
+		if bucket := tx.Bucket(StateBucket); bucket != nil {
+			err = bucket.ForEach(func(k, v []byte) error {
+				dataID := string(k)
+				seen[dataID] = true
+				data, has := entries[dataID]
+				if !has {
+					diff.Extra = append(diff.Extra, dataID)
+				} else if !bytes.Equal(data, v) {
+					diff.Changed = append(diff.Changed, dataID)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for dataID := range entries {
+			if !seen[dataID] {
+				diff.Missing = append(diff.Missing, dataID)
+			}
+		}
+		sort.Strings(diff.Missing)
+		if err = writeState(tx, entries); err != nil {
+			return err
+		}
+		// 二级索引由新的世界状态重新生成
+		return rebuildIndexes(tx)
+	})
+	if err == errNoBody {
+		return nil, errors.New("本地缺少区块体, 不能重建世界状态")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// GetState 在世界状态里面查找数据当前的值。
+// 世界状态不可用时返回 ErrNoState, 数据不存在时返回其他 err
+func (blockChain *BlockChain) GetState(dataID string) (*StateEntry, error) {
+	var e *StateEntry
+	err := blockChain.Store.View(func(tx blockstore.Tx) error {
+		bucket := tx.Bucket(StateBucket)
+		if bucket == nil {
+			return ErrNoState
+		}
+		data := bucket.Get([]byte(dataID))
+		if data == nil {
+			return errors.New("null")
+		}
+		var err error
+		e, err = decodeStateEntry(data)
+		return err
+	})
+	return e, err
+}
+
+// ScanState 在世界状态里面按照字节序遍历表的数据, 参数和返回值的含义与 ScanKeys 相同。
+// limit 小于 0 时返回表里面所有的数据。 世界状态不可用时返回 ErrNoState。
+// 表 a 的前缀下面也有表 a-QAQ-b 的数据, 只返回交易的表是 table 的数据
+func (blockChain *BlockChain) ScanState(table, from, to, prefix string, limit int) ([]*StateEntry, string, error) {
+	tablePrefix := []byte(table + "-QAQ-")
+	keyPrefix := append(append([]byte{}, tablePrefix...), prefix...)
+	start := append(append([]byte{}, tablePrefix...), from...)
+	if bytes.Compare(keyPrefix, start) > 0 {
+		start = keyPrefix
+	}
+	var entries []*StateEntry
+	next := ""
+	err := blockChain.Store.View(func(tx blockstore.Tx) error {
+		bucket := tx.Bucket(StateBucket)
+		if bucket == nil {
+			return ErrNoState
+		}
+		c := bucket.Cursor()
+		for k, v := c.Seek(start); k != nil && bytes.HasPrefix(k, keyPrefix); k, v = c.Next() {
+			key := string(k[len(tablePrefix):])
+			if to != "" && key >= to {
+				break
+			}
+			e, err := decodeStateEntry(v)
+			if err != nil {
+				return err
+			}
+			if e.Table != table {
+				continue
+			}
+			if limit >= 0 && len(entries) >= limit {
+				next = key
+				break
+			}
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	return entries, next, err
+}
@@ -0,0 +1,45 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/blockchain/blockstore"
+	"testing"
+)
+
+func TestLatestWritesBlockOrder(t *testing.T) {
+	// 后面的交易的时间戳更早, 仍然是最新的写入
+	writes := []*Transaction{
+		{DataID: []byte("t-QAQ-a"), TimeStamp: 200},
+		{DataID: []byte("t-QAQ-b"), TimeStamp: 100},
+		{DataID: []byte("t-QAQ-a"), TimeStamp: 100},
+		{TimeStamp: 300},
+	}
+	latest := latestWrites(writes)
+	if len(latest) != 2 || latest["t-QAQ-a"] != 2 || latest["t-QAQ-b"] != 1 {
+		t.Fatalf("latestWrites = %v", latest)
+	}
+}
+
+func TestScanSkipsOtherTables(t *testing.T) {
+	chain := new(BlockChain)
+	chain.InitWithStore(blockstore.NewMemory())
+	// 检查 -QAQ- 之前写入的表 a-QAQ-b 的数据, 数据ID 的前缀和表 a 相同
+	txs := []*Transaction{{TxID: []byte{1}, Table: "a", Key: "k"}, {TxID: []byte{2}, Table: "a-QAQ-b", Key: "k"}}
+	for _, tx := range txs {
+		tx.SetDataID()
+	}
+	block := NewBlock()
+	block.InitBlock(txs, chain.TailHash, chain.LastID)
+	chain.AddBlockToChain(block)
+
+	entries, _, err := chain.ScanState("a", "", "", "", -1)
+	if err != nil || len(entries) != 1 || entries[0].Key != "k" {
+		t.Fatalf("ScanState = %v %v", entries, err)
+	}
+	keys, next, err := chain.ScanKeys("a", "", "", "", 10)
+	if err != nil || len(keys) != 1 || keys[0].Key != "k" || next != "" {
+		t.Fatalf("ScanKeys = %v %q %v", keys, next, err)
+	}
+	if err = txs[1].CheckDataID(); err == nil {
+		t.Fatal("表名里面有 -QAQ- 的交易通过了检查")
+	}
+}
@@ -6,9 +6,10 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
-	"encoding/gob"
+	"fmt"
 	"log"
 	"math/big"
+	"strings"
 	"time"
 )
 
@@ -20,16 +21,37 @@ type Transaction struct {
 	TxID   []byte // 简化签名，没其他作用捏
 	DataID []byte
 	// 数据信息
-	Table     string
-	Key       string
-	Value     string
-	Possessor string
-	TimeStamp int64 // 交易在本地生成的时间戳. 是在区块链中的生效日期。
+	Table  string
+	Key    string
+	Value  string
+	Type   ValueType // 值的类型
+	Op     DataOp    // 写入或者删除
+	Writes []Write   // 批量交易的所有写入, 普通交易为空
+	Cond   Condition // 写入的条件
+	Expect []byte    // 条件为 CondVersion 时期望的 key 当前的版本
+	Reads  []Read    // 读取集: 交易读取的 key 和读取时的版本
+	// 写入时检查的表的 schema 的版本, 表没有声明过 schema 时为 0 (见 blockchain_table.Schema)
+	SchemaVersion uint64
+	Possessor     string
+	TimeStamp     int64 // 交易在本地生成的时间戳. 是在区块链中的生效日期。
 	// 验证信息
 	PublicKey []byte // 交易所有者的公钥
 	Signature []byte // 交易所有者的签名
 }
 
+// DataOp 数据交易的操作
+type DataOp uint8
+
+const (
+	OpPut    DataOp = iota // 写入 Value
+	OpDelete               // 删除 key (墓碑), Value 为空。 删除以后当前的查询里面没有这个 key, 历史里面还有
+)
+
+// IsDelete 交易是不是删除 key 的墓碑
+func (tx *Transaction) IsDelete() bool {
+	return tx.Op == OpDelete
+}
+
 func (tx *Transaction) Init(table, key, value, possessor string, publicKey []byte, privateKey ecdsa.PrivateKey) {
 	// init
 	tx.Table = table
@@ -44,6 +66,20 @@ func (tx *Transaction) Init(table, key, value, possessor string, publicKey []byt
 	tx.Sign(&privateKey) // nil
 }
 
+// InitDelete 删除 key 的交易 (墓碑)
+func (tx *Transaction) InitDelete(table, key, possessor string, publicKey []byte, privateKey ecdsa.PrivateKey) {
+	tx.Table = table
+	tx.Key = key
+	tx.Op = OpDelete
+	tx.Possessor = possessor
+	tx.TimeStamp = time.Now().Unix()
+	tx.PublicKey = publicKey
+
+	tx.SetDataID()
+	tx.SetTxID()
+	tx.Sign(&privateKey)
+}
+
 // SetDataID 数据的标识ID, 表明同表下同key的数据的唯一。
 // 就不hash了直接用 table+ -QAQ- + key
 func (tx *Transaction) SetDataID() {
@@ -54,15 +90,38 @@ func (tx *Transaction) SetDataID() {
 	tx.DataID = ID
 }
 
-// SetTxID 交易的标识ID，表明这笔交易的唯一性。
-func (tx *Transaction) SetTxID() {
-	var buffer bytes.Buffer
-	encoder := gob.NewEncoder(&buffer)
-	err := encoder.Encode(transactionsToBytes(*tx))
-	if err != nil {
-		log.Panic(err)
+// CheckDataKey 表名和 key 不能包含数据ID 的分隔符 -QAQ-,
+// 否则不同的表和 key 可能得到同样的数据ID, 按照表的前缀扫描时也会读到其他表的数据
+func CheckDataKey(table, key string) error {
+	if strings.Contains(table, "-QAQ-") || strings.Contains(key, "-QAQ-") {
+		return fmt.Errorf("表 %s 或者 key %s 里面有 -QAQ-", table, key)
+	}
+	return nil
+}
+
+// CheckDataID 检查数据ID 和表, key 一致, 表和 key 要通过 CheckDataKey。 权限按照表检查, 数据按照数据ID 写入,
+// 不一致时可以用一个表的权限写入其他表的数据。 批量交易的数据ID 为空, 写入的数据ID 由表和 key 得到
+func (tx *Transaction) CheckDataID() error {
+	if tx.IsBatch() {
+		for i, w := range tx.Writes {
+			if err := CheckDataKey(w.Table, w.Key); err != nil {
+				return fmt.Errorf("第 %d 个写入: %v", i, err)
+			}
+		}
+		return nil
+	}
+	if err := CheckDataKey(tx.Table, tx.Key); err != nil {
+		return err
 	}
-	txHash := sha256.Sum256(buffer.Bytes())
+	if string(tx.DataID) != tx.Table+"-QAQ-"+tx.Key {
+		return fmt.Errorf("数据ID %q 和表 %s, key %s 不一致", tx.DataID, tx.Table, tx.Key)
+	}
+	return nil
+}
+
+// SetTxID 交易的标识ID，表明这笔交易的唯一性。 TxID = sha256(交易的签名内容)
+func (tx *Transaction) SetTxID() {
+	txHash := sha256.Sum256(tx.SigningBytes())
 
 	tx.TxID = txHash[:]
 }
@@ -89,10 +148,12 @@ func VerifyTransaction(txVerify Transaction) bool {
 	x.SetBytes(xData)
 	y.SetBytes(yData)
 
-	// 得到待验证的hash
-	txVerify.Signature = nil
-	txVerify.TxID = nil
+	// 得到待验证的hash, 和交易里面的 TxID 要一致
+	txID := txVerify.TxID
 	txVerify.SetTxID()
+	if !bytes.Equal(txID, txVerify.TxID) {
+		return false
+	}
 
 	curve := elliptic.P256()
 	ecdsaPublicKey := ecdsa.PublicKey{Curve: curve, X: &x, Y: &y}
@@ -1,11 +1,11 @@
 package blockchain_data
 
 import (
-	"alg_bcDB/MerkleTree"
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/util"
 	"bytes"
 	"errors"
 	"fmt"
-	"github.com/boltdb/bolt"
 	"log"
 )
 
@@ -21,67 +21,158 @@ func (blockChain *BlockChain) CheckDataBlock(block *Block) bool {
 			fmt.Println("数据区块验证:  交易检验错误")
 			return false
 		}
+		if block.Transactions[i].Op > OpDelete {
+			fmt.Println("数据区块验证:  错误的数据操作")
+			return false
+		}
+		if err := block.Transactions[i].CheckBatch(); err != nil {
+			fmt.Println("数据区块验证:  批量交易错误", err)
+			return false
+		}
+		if err := block.Transactions[i].CheckDataID(); err != nil {
+			fmt.Println("数据区块验证:  数据ID错误", err)
+			return false
+		}
+		if err := block.Transactions[i].CheckCondition(); err != nil {
+			fmt.Println("数据区块验证:  写入条件错误", err)
+			return false
+		}
+		if err := block.Transactions[i].CheckReads(); err != nil {
+			fmt.Println("数据区块验证:  读取集错误", err)
+			return false
+		}
+		if err := block.Transactions[i].CheckValues(); err != nil {
+			fmt.Println("数据区块验证:  值错误", err)
+			return false
+		}
 	}
-	// 校验默克尔根
-	var MerKelRootData [][]byte
-	// 类型转换
-	for i := 0; i < len(block.Transactions); i++ {
-		MerKelRootData = append(MerKelRootData, transactionsToBytes(*block.Transactions[i]))
+	// 校验区块头里面的无效交易
+	if err := blockChain.CheckInvalid(block); err != nil {
+		fmt.Println("数据区块验证: ", err)
+		return false
 	}
-	MerKelRoot := MerkleTree.GetMerkleRoot(MerKelRootData).Hash
+	// 校验默克尔根
+	MerKelRoot := block.ComputeMerkleRoot()
 	if !bytes.Equal(MerKelRoot, block.MerKelRoot) {
 		fmt.Println("数据区块验证:  默克尔根错误")
 		log.Panic("数据区块验证:  默克尔根错误")
 		return false
 	}
+	// 校验区块的 HASH
+	if !bytes.Equal(block.ComputeHash(), block.CurrentBlockHash) {
+		fmt.Println("数据区块验证:  区块HASH错误")
+		return false
+	}
 	return true
 }
 
 // GetBlockByHash 通过hash得到区块
+// 本地没有区块体时(轻节点), 通过 BodyFetcher 获取区块体并用区块头里面的默克尔根校验
 func (blockChain *BlockChain) GetBlockByHash(hash []byte) (*Block, error) {
 	block := Block{}
+	hasBody := false
 	// 直接在数据库中查找
-	err := blockChain.Db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(blockChain.BlockBucket))
-		if bucket == nil {
-			return errors.New("not found bucket")
-		}
-		value := bucket.Get(hash)
-		if len(value) == 0 {
-			return errors.New(fmt.Sprintf(" not the key"))
-		}
-		block = Deserialize(value)
-		return nil
+	err := blockChain.Store.View(func(tx blockstore.Tx) error {
+		var err error
+		block, hasBody, err = getBlock(tx, hash)
+		return err
 	})
 	if err != nil {
 		log.Panic(err)
 	}
+	if hasBody || blockChain.BodyFetcher == nil {
+		return &block, nil
+	}
+	transactions, err := blockChain.BodyFetcher(hash)
+	if err != nil {
+		return nil, err
+	}
+	block.Transactions = transactions
+	if !bytes.Equal(block.ComputeMerkleRoot(), block.MerKelRoot) {
+		return nil, errors.New("区块体和区块头的默克尔根不一致")
+	}
 	return &block, nil
 }
 
-// GetByRound 通过hash得到区块
+// GetHeaderByHash 通过hash得到区块头, 不读取区块体
+func (blockChain *BlockChain) GetHeaderByHash(hash []byte) (*Block, error) {
+	block := Block{}
+	err := blockChain.Store.View(func(tx blockstore.Tx) error {
+		var err error
+		block, err = getHeader(tx, hash)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	header := block.Header()
+	return &header, nil
+}
+
+// GetByRound 通过 Round 得到区块, 直接读取 Round -> 区块 HASH 的索引
 func (blockChain *BlockChain) GetByRound(round uint64) *Block {
-	lastBlock, err := blockChain.GetBlockByHash(blockChain.TailHash)
+	hash := blockChain.GetHashByRound(round)
+	if hash == nil {
+		return nil
+	}
+	block, err := blockChain.GetBlockByHash(hash)
 	if err != nil {
-		log.Println("GetBlockById 获取区块失败")
+		log.Println("GetByRound 获取区块失败")
+		return nil
 	}
-	if lastBlock.Round < round {
+	return block
+}
+
+// GetHashByRound 通过 Round 得到区块的 HASH, 不存在时返回 nil
+func (blockChain *BlockChain) GetHashByRound(round uint64) []byte {
+	var hash []byte
+	err := blockChain.Store.View(func(tx blockstore.Tx) error {
+		bucket := tx.Bucket(blockChain.IndexBucket)
+		if bucket == nil {
+			return errors.New("not found bucket")
+		}
+		if value := bucket.Get(util.Uint64ToBytes(round)); value != nil {
+			hash = append([]byte{}, value...)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println(err)
 		return nil
 	}
-	var block *Block
-	block = lastBlock
-	for {
-		if block.Round == round {
-			return block
+	return hash
+}
+
+// RoundAtTime 得到时间 timeStamp (秒) 时区块链最新的区块的 Round, 即区块时间戳不大于 timeStamp 的最大的 Round。
+// 区块的时间戳由提议者写入区块头, 每个节点都一样, 按照 Round 递增二分查找
+func (blockChain *BlockChain) RoundAtTime(timeStamp uint64) (uint64, error) {
+	blockTime := func(round uint64) (uint64, error) {
+		header, err := blockChain.GetHeaderByHash(blockChain.GetHashByRound(round))
+		if err != nil {
+			return 0, err
 		}
-		block1, err := blockChain.GetBlockByHash(block.PreviousBlockHash)
+		return header.TimeStamp, nil
+	}
+	t, err := blockTime(0)
+	if err != nil {
+		return 0, err
+	}
+	if t > timeStamp {
+		return 0, errors.New("这个时间之前没有区块")
+	}
+	// 区块 lo 的时间戳 <= timeStamp, 区块 hi 的时间戳 > timeStamp (hi == LastID 表示链尾之后)
+	lo, hi := uint64(0), blockChain.LastID
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		t, err := blockTime(mid)
 		if err != nil {
-			return nil
+			return 0, err
 		}
-		block = block1
-		if block.Round == 1 {
-			break
+		if t <= timeStamp {
+			lo = mid
+		} else {
+			hi = mid
 		}
 	}
-	return nil
+	return lo, nil
 }
@@ -0,0 +1,76 @@
+package blockchain_data
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValueType 数据的值的类型。 Value 是 Go 的 string, 可以保存任意字节, 类型决定怎么解释和校验
+type ValueType uint8
+
+const (
+	TypeString ValueType = iota // 文本 (原来的交易都是这种)
+	TypeBytes                   // 任意字节
+	TypeJSON                    // JSON, 写入时校验, 查询时可以使用 JSON 路径
+)
+
+var typeNames = []string{"string", "bytes", "json"}
+
+func (t ValueType) String() string {
+	if int(t) < len(typeNames) {
+		return typeNames[t]
+	}
+	return fmt.Sprintf("type(%d)", uint8(t))
+}
+
+// ParseValueType 解析类型的名字, 空字符串为 string
+func ParseValueType(s string) (ValueType, error) {
+	if s == "" {
+		return TypeString, nil
+	}
+	for i, name := range typeNames {
+		if strings.EqualFold(s, name) {
+			return ValueType(i), nil
+		}
+	}
+	return TypeString, fmt.Errorf("错误的类型 %s, 可以是 string, bytes, json", s)
+}
+
+// CheckValue 检查值和类型是否一致: JSON 必须是合法的 JSON。 删除的交易没有值
+func CheckValue(typ ValueType, op DataOp, value string) error {
+	if typ > TypeJSON {
+		return errors.New("错误的值类型")
+	}
+	if op == OpDelete || typ != TypeJSON {
+		return nil
+	}
+	if !json.Valid([]byte(value)) {
+		return errors.New("值不是合法的 JSON")
+	}
+	return nil
+}
+
+// CheckValues 检查交易里面所有写入的值
+func (tx *Transaction) CheckValues() error {
+	if !tx.IsBatch() {
+		return CheckValue(tx.Type, tx.Op, tx.Value)
+	}
+	if tx.Type != TypeString {
+		return errors.New("批量交易的值类型在每个写入里面")
+	}
+	for i, w := range tx.Writes {
+		if err := CheckValue(w.Type, w.Op, w.Value); err != nil {
+			return fmt.Errorf("第 %d 个写入: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// InitTyped 指定类型的写入
+func (tx *Transaction) InitTyped(table, key string, value []byte, typ ValueType, possessor string, publicKey []byte, privateKey ecdsa.PrivateKey) {
+	tx.Type = typ
+	tx.Init(table, key, string(value), possessor, publicKey, privateKey)
+}
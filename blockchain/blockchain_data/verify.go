@@ -0,0 +1,105 @@
+package blockchain_data
+
+import (
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/common"
+	"alg_bcDB/util"
+	"bytes"
+	"fmt"
+	"golang.org/x/crypto/ed25519"
+)
+
+// VerifySignature 校验区块提议者的签名, 签名里面的公钥要和 Author 一致
+func (block *Block) VerifySignature() error {
+	if len(block.Signature) != ed25519.PublicKeySize+ed25519.SignatureSize {
+		return fmt.Errorf("区块没有提议者签名")
+	}
+	pubkey := util.RecoverPubkey(block.Signature)
+	if pubkey.Address() != block.Author {
+		return fmt.Errorf("签名的公钥和提议者 %x 不一致", block.Author)
+	}
+	return pubkey.VerifySign(block.CurrentBlockHash, block.Signature)
+}
+
+// VerifyChain 从创世区块到链尾审计本地的数据区块链。
+// 按照 Round 索引遍历, 检查 前一个区块的HASH, 区块HASH, 默克尔根, 所有交易的签名, 提议者的签名
+func (blockChain *BlockChain) VerifyChain() *common.ChainReport {
+	report := common.NewChainReport("data")
+	var prevHash []byte
+	blockChain.Store.View(func(tx blockstore.Tx) error {
+		indexBucket := tx.Bucket(blockChain.IndexBucket)
+		if indexBucket == nil {
+			report.AddIssue(0, nil, "index", "索引不存在")
+			return nil
+		}
+		for round := uint64(0); round < blockChain.LastID; round++ {
+			hash := indexBucket.Get(util.Uint64ToBytes(round))
+			if hash == nil {
+				report.AddIssue(round, nil, "index", "索引里面没有这个区块")
+				prevHash = nil
+				continue
+			}
+			if len(tx.GetBlock(hash)) == 0 {
+				report.AddIssue(round, hash, "index", "存储里面没有这个区块")
+				prevHash = nil
+				continue
+			}
+			block, hasBody, err := getBlock(tx, hash)
+			if err != nil {
+				report.AddIssue(round, hash, "hash", "解码区块失败: "+err.Error())
+				prevHash = nil
+				continue
+			}
+			report.Blocks++
+			blockChain.verifyBlock(report, &block, hasBody, round, hash, prevHash)
+			prevHash = hash
+		}
+		if tail := tx.Tail(); !bytes.Equal(tail, prevHash) {
+			report.AddIssue(blockChain.LastID-1, tail, "tail", "链尾区块和索引里面最新的区块不一致")
+		}
+		return nil
+	})
+	return report
+}
+
+// verifyBlock 审计单个区块, prevHash 为 nil 表示前一个区块已经出错, 不检查链接。
+// 轻节点没有区块体, 只检查区块头
+func (blockChain *BlockChain) verifyBlock(report *common.ChainReport, block *Block, hasBody bool, round uint64, hash, prevHash []byte) {
+	if !bytes.Equal(block.CurrentBlockHash, hash) || block.Round != round {
+		report.AddIssue(round, hash, "index", fmt.Sprintf("索引指向的区块不一致 (Round %d)", block.Round))
+	}
+	if round == 0 {
+		if !bytes.Equal(block.PreviousBlockHash, []byte("welcome to 407")) {
+			report.AddIssue(round, hash, "link", "创世区块的前一个区块HASH错误")
+		}
+	} else if prevHash != nil && !bytes.Equal(block.PreviousBlockHash, prevHash) {
+		report.AddIssue(round, hash, "link", fmt.Sprintf("前一个区块HASH %x, 应该是 %x", block.PreviousBlockHash, prevHash))
+	}
+	if !bytes.Equal(block.ComputeHash(), block.CurrentBlockHash) {
+		report.AddIssue(round, hash, "hash", "区块HASH错误")
+	}
+	// 创世区块没有提议者
+	if round != 0 {
+		if err := block.VerifySignature(); err != nil {
+			report.AddIssue(round, hash, "signature", err.Error())
+		}
+	}
+	if !hasBody {
+		if !blockChain.Light {
+			report.AddIssue(round, hash, "body", "区块体不存在")
+		}
+		return
+	}
+	if root := block.ComputeMerkleRoot(); root == nil || !bytes.Equal(root, block.MerKelRoot) {
+		report.AddIssue(round, hash, "merkle", "默克尔根错误")
+	}
+	// 创世区块的交易没有签名
+	if round == 0 {
+		return
+	}
+	for i, tx := range block.Transactions {
+		if !VerifyTransaction(*tx) {
+			report.AddIssue(round, hash, "transaction", fmt.Sprintf("第 %d 个交易 %x 签名错误", i, tx.TxID))
+		}
+	}
+}
@@ -2,7 +2,6 @@ package blockchain_table
 
 import (
 	"alg_bcDB/MerkleTree"
-	"alg_bcDB/util"
 	"bytes"
 	"crypto/sha256"
 	"encoding/gob"
@@ -30,12 +29,7 @@ func (block *Block) InitBlock(transactions []*Transaction, previousBlockHash []b
 	block.ID = ID
 	block.PreviousBlockHash = previousBlockHash
 	block.Transactions = transactions
-	var MerKelRootData [][]byte
-	// 类型转换
-	for i := 0; i < len(transactions); i++ {
-		MerKelRootData = append(MerKelRootData, transactionsToBytes(*transactions[i]))
-	}
-	block.MerKelRoot = MerkleTree.GetMerkleRoot(MerKelRootData).Hash
+	block.MerKelRoot = block.ComputeMerkleRoot()
 	block.TimeStamp = uint64(time.Now().Unix())
 	block.SetBlockHash()
 }
@@ -45,43 +39,41 @@ func (block *Block) InitGenesisBlock(transactions []*Transaction, previousBlockH
 	block.ID = ID
 	block.PreviousBlockHash = previousBlockHash
 	block.Transactions = transactions
-	var MerKelRootData [][]byte
-	// 类型转换
-	for i := 0; i < len(transactions); i++ {
-		MerKelRootData = append(MerKelRootData, transactionsToBytes(*transactions[i]))
-	}
-	block.MerKelRoot = MerkleTree.GetMerkleRoot(MerKelRootData).Hash
+	block.MerKelRoot = block.ComputeMerkleRoot()
 	block.TimeStamp = uint64(1234567801)
 	block.SetBlockHash()
 }
 
-// TransactionsToBytes 交易的转换为byte
-func transactionsToBytes(transaction Transaction) []byte {
-	var data []byte
-	data = append(data, transaction.TxID...)
-	data = append(data, transaction.Table...)
-	for _, v := range transaction.PermissionTable {
-		data = append(data, v...)
+// ComputeMerkleRoot 计算区块的默克尔根, 叶子节点为交易的规范编码。 没有交易的区块没有默克尔根
+func (block *Block) ComputeMerkleRoot() []byte {
+	if len(block.Transactions) == 0 {
+		return nil
 	}
-	data = append(data, transaction.Possessor...)
-	data = append(data, util.Int64ToBytes(transaction.TimeStamp)...)
-	data = append(data, transaction.PublicKey...)
-	data = append(data, transaction.Signature...)
-	return data
+	return block.merkleTree().Hash
 }
 
-// SetBlockHash 计算区块的 HASH
-func (block *Block) SetBlockHash() {
+func (block *Block) merkleTree() *MerkleTree.MerkleTree {
+	var MerKelRootData [][]byte
+	for i := 0; i < len(block.Transactions); i++ {
+		MerKelRootData = append(MerKelRootData, block.Transactions[i].Encode())
+	}
+	return MerkleTree.GetMerkleRoot(MerKelRootData)
+}
 
-	var buffer bytes.Buffer
-	encoder := gob.NewEncoder(&buffer)
+// MerkleProof 第 index 个交易的默克尔证明, 用 MerkleTree.VerifyProof(MerKelRoot, 交易的规范编码, proof) 校验
+func (block *Block) MerkleProof(index int) (*MerkleTree.Proof, error) {
+	return block.merkleTree().GetProof(index)
+}
 
-	err := encoder.Encode(block)
-	if err != nil {
-		log.Panic(err)
-	}
-	blockHash := sha256.Sum256(buffer.Bytes())
-	block.CurrentBlockHash = blockHash[:]
+// ComputeHash 计算区块的 HASH, 即区块头规范编码的 sha256
+func (block *Block) ComputeHash() []byte {
+	blockHash := sha256.Sum256(block.EncodeHeader())
+	return blockHash[:]
+}
+
+// SetBlockHash 计算区块的 HASH
+func (block *Block) SetBlockHash() {
+	block.CurrentBlockHash = block.ComputeHash()
 }
 
 // Serialize 序列化, 将区块转换成字节流
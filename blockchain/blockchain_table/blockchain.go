@@ -1,139 +1,243 @@
 package blockchain_table
 
 import (
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/config"
+	"alg_bcDB/genesis"
 	"alg_bcDB/util"
+	"bytes"
+	"errors"
 	"fmt"
-	"github.com/boltdb/bolt"
 	"log"
-	"os"
 )
 
 type BlockChain struct {
-	Db       *bolt.DB // Db 本地的boltDB 连接
-	TailHash []byte   // TailHash 区块链最后一个区块的 HASH
+	Store    blockstore.BlockStore // Store 区块链的存储 (boltDB 或者内存)
+	TailHash []byte                // TailHash 区块链最后一个区块的 HASH
 
 	BlockChainFile string
-	BlockBucket    string
-	LastHashKey    string
+	IndexBucket    string // IndexBucket ID -> 区块 HASH 的索引
+	MetaBucket     string // MetaBucket 链的元数据, 和 8 字节 key 的索引分开保存
+	LastIDKey      string // LastIDKey 在 MetaBucket 里面保存 LastID
 	LastID         int
 }
 
 var LocalTableBlockChain *BlockChain
 
+const (
+	IndexBucket = "indexBucket" // IndexBucket ID -> 区块 HASH 的索引
+	MetaBucket  = "metaBucket"  // MetaBucket 链的元数据
+)
+
+// reservedTables 表区块链的存储自己使用的 bucket。 共享表的相关链也是这个存储里面以表名命名的 bucket, 表名不能和它们相同
+var reservedTables = []string{blockstore.BlockBucket, blockstore.BodyBucket, IndexBucket, MetaBucket}
+
 // InitBlockChain 如果本地存在区块区块链文件，就更新数据
 // 如果本地不存在区块区块链文件，创建新的区块链。加入genesisBlock.
 func (blockChain *BlockChain) InitBlockChain() {
-	blockChain.BlockChainFile = "tableBlockChain.db"
-	blockChain.BlockBucket = "blockBucket"
-	blockChain.LastHashKey = "lastHashKey"
+	blockChain.BlockChainFile = config.LocalConfig.Path("tableBlockChain.db")
+	if util.IsExistFile(blockChain.BlockChainFile) {
+		fmt.Println("加载区块链文件 ing .")
+	} else {
+		fmt.Println("本地不存在表权限区块链文件, 创建区块链文件 ing .")
+	}
+	store, err := blockstore.OpenBolt(blockChain.BlockChainFile)
+	if err != nil {
+		log.Panic(err)
+	}
+	blockChain.InitWithStore(store)
+}
+
+// InitWithStore 使用指定的存储初始化区块链, 存储为空时创建genesisBlock.
+// 单元测试里面可以使用 blockstore.NewMemory(), 不会生成 .db 文件
+func (blockChain *BlockChain) InitWithStore(store blockstore.BlockStore) {
+	blockChain.Store = store
+	blockChain.IndexBucket = IndexBucket
+	blockChain.MetaBucket = MetaBucket
+	blockChain.LastIDKey = "lastIDKey"
 	blockChain.LastID = 1
 
-	if !util.IsExistFile(blockChain.BlockChainFile) {
-		fmt.Println("本地不存在表权限区块链文件, 创建区块链文件 ing .")
-		// 创建 boltDB
-		db, err := bolt.Open(blockChain.BlockChainFile, 0600, nil)
+	err := store.Update(func(tx blockstore.Tx) error {
+		if tail := tx.Tail(); tail != nil {
+			blockChain.TailHash = append([]byte{}, tail...)
+			return nil
+		}
+		// 创建genesisBlock
+		genesisBlock := NewGenesisBlock()
+		// 更新区块链
+		err := tx.PutBlock(genesisBlock.CurrentBlockHash, genesisBlock.Serialize())
 		if err != nil {
-			log.Panic(err)
+			return err
 		}
-		// 创建 boltDB.bucket
-		db.Update(func(tx *bolt.Tx) error {
-			bucket, err := tx.CreateBucket([]byte(blockChain.BlockBucket))
-			if err != nil {
-				log.Panic(err)
-			}
-			// 创建genesisBlock
-			// "welcome to 407" 创世区块的HASH
-			genesisBlock := NewBlock()
-			transaction := Transaction{Table: "sample table"}
-			genesisBlock.InitGenesisBlock([]*Transaction{&transaction}, []byte("welcome to 407"), blockChain.LastID)
-			blockChain.LastID++
-			// 更新区块链
-			err = bucket.Put(genesisBlock.CurrentBlockHash, genesisBlock.Serialize())
-			if err != nil {
-				log.Panic(err)
-			}
-			err = bucket.Put([]byte(blockChain.LastHashKey), genesisBlock.CurrentBlockHash)
-			if err != nil {
-				log.Panic(err)
-			}
-
-			// Init blockChain
-			blockChain.Db = db
-			blockChain.TailHash = genesisBlock.CurrentBlockHash
-			return nil
-		})
-	} else {
-		fmt.Println("加载区块链文件 ing .")
-		// 链接 boltDB
-		db, err := bolt.Open(blockChain.BlockChainFile, 0600, nil)
+		err = tx.SetTail(genesisBlock.CurrentBlockHash)
 		if err != nil {
-			log.Panic(err)
+			return err
+		}
+		// 创建索引并写入创世区块
+		if _, err = tx.CreateBucketIfNotExists(blockChain.IndexBucket); err != nil {
+			return err
+		}
+		if _, err = tx.CreateBucketIfNotExists(blockChain.MetaBucket); err != nil {
+			return err
+		}
+		if err = blockChain.putIndex(tx, &genesisBlock); err != nil {
+			return err
+		}
+		blockChain.TailHash = genesisBlock.CurrentBlockHash
+		return nil
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+	// 加载索引, 旧的区块链文件没有索引时重建索引
+	blockChain.loadIndex()
+	// 本地的区块链要和创世文件一致, 不同网络的区块链不能混用
+	if genesis.LocalGenesis != nil {
+		genesisBlock := NewGenesisBlock()
+		if !bytes.Equal(blockChain.GetHashByID(1), genesisBlock.CurrentBlockHash) {
+			log.Panic("本地表区块链的创世区块和创世文件不一致")
 		}
-		// 链接 boltDB.bucket
-		db.View(func(tx *bolt.Tx) error {
-			bucket := tx.Bucket([]byte(blockChain.BlockBucket))
-			if bucket == nil {
-				fmt.Println("BlockBucket is nil")
-				os.Exit(1)
-			}
-
-			// Init blockchain
-			blockChain.Db = db
-			blockChain.TailHash = bucket.Get([]byte(blockChain.LastHashKey))
-			return nil
-		})
 	}
+
 	LocalTableBlockChain = blockChain
 	fmt.Println("区块链初始化完成.")
 }
 
+// NewGenesisBlock 生成创世区块。 有创世文件时, 创世区块里面是初始的共享表; 没有时使用原来的创世区块
+func NewGenesisBlock() Block {
+	// "welcome to 407" 创世区块的前一个区块HASH
+	genesisBlock := NewBlock()
+	g := genesis.LocalGenesis
+	if g == nil {
+		transaction := Transaction{Table: "sample table"}
+		genesisBlock.InitGenesisBlock([]*Transaction{&transaction}, []byte("welcome to 407"), 1)
+		return genesisBlock
+	}
+	var transactions []*Transaction
+	for _, table := range g.Tables {
+		transactions = append(transactions, &Transaction{
+			Table:           table.Name,
+			PermissionTable: table.Permissions,
+			Possessor:       table.Possessor,
+			TimeStamp:       int64(g.TimeStamp),
+		})
+	}
+	genesisBlock.InitGenesisBlock(transactions, []byte("welcome to 407"), 1)
+	genesisBlock.TimeStamp = g.TimeStamp
+	genesisBlock.SetBlockHash()
+	return genesisBlock
+}
+
 // AddBlockToChain 添加区块到区块链
 func (blockChain *BlockChain) AddBlockToChain(block Block) {
-	blockChain.Db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(blockChain.BlockBucket))
-		if bucket == nil {
-			fmt.Println("BlockBucket is nil")
-			os.Exit(1)
-		}
+	err := blockChain.Store.Update(func(tx blockstore.Tx) error {
 		// 添加区块并更新信息
-		err := bucket.Put(block.CurrentBlockHash, block.Serialize())
+		err := tx.PutBlock(block.CurrentBlockHash, block.Serialize())
+		if err != nil {
+			return err
+		}
+		err = tx.SetTail(block.CurrentBlockHash)
 		if err != nil {
-			log.Panic(err)
+			return err
 		}
-		err = bucket.Put([]byte(blockChain.LastHashKey), block.CurrentBlockHash)
+		// 在同一个事务里面更新索引
+		err = blockChain.putIndex(tx, &block)
 		if err != nil {
-			log.Panic(err)
+			return err
 		}
 
 		blockChain.TailHash = block.CurrentBlockHash
 		return nil
 	})
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// putIndex 写入 ID -> 区块 HASH 的索引, 并更新 MetaBucket 里面持久化的 LastID
+// LastID 始终为最新区块的 ID + 1, 只在这里修改
+func (blockChain *BlockChain) putIndex(tx blockstore.Tx, block *Block) error {
+	indexBucket, metaBucket := tx.Bucket(blockChain.IndexBucket), tx.Bucket(blockChain.MetaBucket)
+	if indexBucket == nil || metaBucket == nil {
+		return errors.New("IndexBucket is nil")
+	}
+	err := indexBucket.Put(util.Uint64ToBytes(uint64(block.ID)), block.CurrentBlockHash)
+	if err != nil {
+		return err
+	}
+	if block.ID+1 > blockChain.LastID {
+		blockChain.LastID = block.ID + 1
+	}
+	return metaBucket.Put([]byte(blockChain.LastIDKey), util.Uint64ToBytes(uint64(blockChain.LastID)))
+}
+
+// loadIndex 读取 MetaBucket 里面的 LastID, 如果索引不存在, 从链尾向前遍历一次重建索引。
+// 旧的区块链文件把 LastID 保存在 IndexBucket 里面, 加载时移动到 MetaBucket
+func (blockChain *BlockChain) loadIndex() {
+	err := blockChain.Store.Update(func(tx blockstore.Tx) error {
+		metaBucket, err := tx.CreateBucketIfNotExists(blockChain.MetaBucket)
+		if err != nil {
+			return err
+		}
+		indexBucket := tx.Bucket(blockChain.IndexBucket)
+		if indexBucket != nil {
+			if old := indexBucket.Get([]byte(blockChain.LastIDKey)); old != nil {
+				if err = metaBucket.Put([]byte(blockChain.LastIDKey), old); err != nil {
+					return err
+				}
+				if err = indexBucket.Delete([]byte(blockChain.LastIDKey)); err != nil {
+					return err
+				}
+			}
+			if value := metaBucket.Get([]byte(blockChain.LastIDKey)); value != nil {
+				blockChain.LastID = int(util.BytesToUint64(value))
+			}
+			return nil
+		}
+
+		fmt.Println("区块链文件没有索引, 重建索引 ing .")
+		if _, err = tx.CreateBucketIfNotExists(blockChain.IndexBucket); err != nil {
+			return err
+		}
+		currentHash := blockChain.TailHash
+		for {
+			bytesBlock := tx.GetBlock(currentHash)
+			if len(bytesBlock) == 0 {
+				break
+			}
+			block := Deserialize(bytesBlock)
+			if err = blockChain.putIndex(tx, &block); err != nil {
+				return err
+			}
+			if bytes.Equal(block.PreviousBlockHash, []byte("welcome to 407")) {
+				break
+			}
+			currentHash = block.PreviousBlockHash
+		}
+		return nil
+	})
+	if err != nil {
+		log.Panic(err)
+	}
 }
 
 // Iterator 区块链迭代器
 type Iterator struct {
-	db          *bolt.DB
-	BlockBucket string
+	store       blockstore.BlockStore
 	CurrentHash []byte
 }
 
 func (blockChain *BlockChain) CreateIterator() Iterator {
-	return Iterator{db: blockChain.Db, CurrentHash: blockChain.TailHash, BlockBucket: blockChain.BlockBucket}
+	return Iterator{store: blockChain.Store, CurrentHash: blockChain.TailHash}
 }
 
 func (iterator *Iterator) Next() Block {
 	var block Block
-	iterator.db.View(func(tx *bolt.Tx) error {
+	iterator.store.View(func(tx blockstore.Tx) error {
 		//fmt.Println("迭代器hash", string(iterator.currentHash))
 
-		bucket := tx.Bucket([]byte(iterator.BlockBucket))
-		if bucket == nil {
-			fmt.Println("BlockBucket is nil")
-			os.Exit(1)
-		}
-		// 在boltDB里面得到区块
-		bytesBlock := bucket.Get(iterator.CurrentHash)
-
+		// 在存储里面得到区块
+		bytesBlock := tx.GetBlock(iterator.CurrentHash)
 		block = Deserialize(bytesBlock)
 		iterator.CurrentHash = block.PreviousBlockHash
 
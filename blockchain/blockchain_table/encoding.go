@@ -0,0 +1,112 @@
+package blockchain_table
+
+import (
+	"alg_bcDB/common"
+	"errors"
+)
+
+// 交易编码的字段, tagOp 及以后的字段是后来加入的可选字段 (见 common.Encoder)
+const (
+	tagTxID byte = iota + 1
+	tagTable
+	tagPermissionTable
+	tagPossessor
+	tagTxTimeStamp
+	tagPublicKey
+	tagSignature
+	tagOp
+	tagSchema
+	tagIndexes
+)
+
+// 区块头编码的字段
+const (
+	tagID byte = iota + 1
+	tagPreviousBlockHash
+	tagMerKelRoot
+	tagBlockTimeStamp
+)
+
+// SigningBytes 交易被签名的内容, 不包含 TxID 和 Signature。 TxID = sha256(SigningBytes)
+func (tx *Transaction) SigningBytes() []byte {
+	e := common.NewEncoder().
+		PutString(tagTable, tx.Table).
+		PutStrings(tagPermissionTable, tx.PermissionTable).
+		PutString(tagPossessor, tx.Possessor).
+		PutInt64(tagTxTimeStamp, tx.TimeStamp).
+		PutBytes(tagPublicKey, tx.PublicKey)
+	return tx.putOptional(e).Encoded()
+}
+
+// Encode 交易的完整编码, 作为默克尔树的叶子节点, 并在 GRPC 里面原样传输
+func (tx *Transaction) Encode() []byte {
+	e := common.NewEncoder().
+		PutBytes(tagTxID, tx.TxID).
+		PutString(tagTable, tx.Table).
+		PutStrings(tagPermissionTable, tx.PermissionTable).
+		PutString(tagPossessor, tx.Possessor).
+		PutInt64(tagTxTimeStamp, tx.TimeStamp).
+		PutBytes(tagPublicKey, tx.PublicKey).
+		PutBytes(tagSignature, tx.Signature)
+	return tx.putOptional(e).Encoded()
+}
+
+// putOptional 写入后来加入的字段, 零值不写入, grant 交易和原来的编码相同
+func (tx *Transaction) putOptional(e *common.Encoder) *common.Encoder {
+	return e.
+		PutOptionalUint64(tagOp, uint64(tx.Op)).
+		PutOptionalString(tagSchema, tx.Schema).
+		PutOptionalStrings(tagIndexes, tx.Indexes)
+}
+
+// DecodeTransaction 从 Encode 的结果还原交易, 没有可选字段的旧的编码也可以解码
+func DecodeTransaction(data []byte) (*Transaction, error) {
+	d := common.NewDecoder(data)
+	tx := &Transaction{
+		TxID:            d.Bytes(tagTxID),
+		Table:           d.String(tagTable),
+		PermissionTable: d.Strings(tagPermissionTable),
+		Possessor:       d.String(tagPossessor),
+		TimeStamp:       d.Int64(tagTxTimeStamp),
+		PublicKey:       d.Bytes(tagPublicKey),
+		Signature:       d.Bytes(tagSignature),
+	}
+	op := d.OptionalUint64(tagOp)
+	tx.Schema = d.OptionalString(tagSchema)
+	tx.Indexes = d.OptionalStrings(tagIndexes)
+	if err := d.Finish(); err != nil {
+		return nil, err
+	}
+	if op > 0xff {
+		return nil, errors.New("bad permission op")
+	}
+	tx.Op = PermissionOp(op)
+	return tx, nil
+}
+
+// EncodeHeader 区块头的编码, 区块的 HASH = sha256(EncodeHeader)。
+// 交易通过默克尔根包含在区块头里面
+func (block *Block) EncodeHeader() []byte {
+	return common.NewEncoder().
+		PutInt64(tagID, int64(block.ID)).
+		PutBytes(tagPreviousBlockHash, block.PreviousBlockHash).
+		PutBytes(tagMerKelRoot, block.MerKelRoot).
+		PutUint64(tagBlockTimeStamp, block.TimeStamp).
+		Encoded()
+}
+
+// DecodeHeader 从 EncodeHeader 的结果还原区块头, 区块的 HASH 由区块头重新计算
+func DecodeHeader(data []byte) (*Block, error) {
+	d := common.NewDecoder(data)
+	block := &Block{
+		ID:                int(d.Int64(tagID)),
+		PreviousBlockHash: d.Bytes(tagPreviousBlockHash),
+		MerKelRoot:        d.Bytes(tagMerKelRoot),
+		TimeStamp:         d.Uint64(tagBlockTimeStamp),
+	}
+	if err := d.Finish(); err != nil {
+		return nil, err
+	}
+	block.SetBlockHash()
+	return block, nil
+}
@@ -0,0 +1,51 @@
+package blockchain_table
+
+import (
+	"alg_bcDB/common"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeV1Transaction(t *testing.T) {
+	tx := &Transaction{TxID: []byte{1}, Table: "t", PermissionTable: []string{"addr4"}, Possessor: "u",
+		TimeStamp: 100, PublicKey: []byte{2}, Signature: []byte{3}}
+	// 第一个版本的编码, 只有必选字段
+	old := common.NewEncoder().
+		PutBytes(tagTxID, tx.TxID).
+		PutString(tagTable, tx.Table).
+		PutStrings(tagPermissionTable, tx.PermissionTable).
+		PutString(tagPossessor, tx.Possessor).
+		PutInt64(tagTxTimeStamp, tx.TimeStamp).
+		PutBytes(tagPublicKey, tx.PublicKey).
+		PutBytes(tagSignature, tx.Signature).
+		Encoded()
+	if !bytes.Equal(tx.Encode(), old) {
+		t.Fatal("grant 交易的编码和第一个版本不同")
+	}
+	decoded, err := DecodeTransaction(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, tx) {
+		t.Fatalf("decoded = %+v", decoded)
+	}
+}
+
+func TestTransactionRoundTrip(t *testing.T) {
+	txs := []*Transaction{
+		{Table: "t", Op: OpRevoke, PermissionTable: []string{"addr"}},
+		{Table: "t", Op: OpSchema, Schema: `{"fields":{}}`},
+		{Table: "t", Op: OpIndex, Indexes: []string{"a", "b.c"}},
+		{Table: "t", Op: OpIndex},
+	}
+	for i, tx := range txs {
+		decoded, err := DecodeTransaction(tx.Encode())
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if !reflect.DeepEqual(decoded, tx) {
+			t.Errorf("%d: decoded = %+v", i, decoded)
+		}
+	}
+}
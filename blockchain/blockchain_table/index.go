@@ -0,0 +1,73 @@
+package blockchain_table
+
+import (
+	"alg_bcDB/util"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 二级索引: 表的管理员用 OpIndex 的表交易声明表的索引字段, 交易的 Indexes 是所有索引字段的 JSON 路径 (替换原来的, 为空时去掉所有索引)。
+// 索引由世界状态得到 (见 blockchain_data.SetIndexes), 只索引 json 类型的值
+
+// MaxIndexes 一个表最多的索引字段个数
+const MaxIndexes = 16
+
+// NormalizeIndexes 检查并规范索引字段: 去掉开头的 $. , 路径的格式正确, 不重复
+func NormalizeIndexes(fields []string) ([]string, error) {
+	if len(fields) > MaxIndexes {
+		return nil, fmt.Errorf("一个表最多 %d 个索引字段", MaxIndexes)
+	}
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		field = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(field), "$"), ".")
+		if err := util.CheckJSONPath(field); err != nil {
+			return nil, err
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("索引字段 %s 重复", field)
+		}
+		seen[field] = true
+		normalized = append(normalized, field)
+	}
+	return normalized, nil
+}
+
+// InitIndexes 声明表的索引字段的交易, fields 为 NormalizeIndexes 的结果
+func (tx *Transaction) InitIndexes(table string, fields []string, possessor string, publicKey []byte, privateKey ecdsa.PrivateKey) {
+	tx.Table = table
+	tx.Op = OpIndex
+	tx.Indexes = fields
+	tx.Possessor = possessor
+	tx.TimeStamp = time.Now().Unix()
+	tx.PublicKey = publicKey
+
+	tx.SetTxID()
+	tx.Sign(&privateKey)
+}
+
+// CheckIndexes 检查交易的索引字段: OpIndex 的交易没有权限表, 索引字段是规范的; 其他交易没有索引字段
+func (tx *Transaction) CheckIndexes() error {
+	if tx.Op != OpIndex {
+		if len(tx.Indexes) != 0 {
+			return errors.New("只有索引交易可以有索引字段")
+		}
+		return nil
+	}
+	if len(tx.PermissionTable) != 0 {
+		return errors.New("索引交易不能修改权限表")
+	}
+	normalized, err := NormalizeIndexes(tx.Indexes)
+	if err != nil {
+		return err
+	}
+	for i := range normalized {
+		if normalized[i] != tx.Indexes[i] {
+			return fmt.Errorf("索引字段 %s 不是规范的", tx.Indexes[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package blockchain_table
+
+import (
+	"alg_bcDB/util"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Role 用户在共享表里面的角色, 高的角色包含低的角色的所有权限
+type Role uint8
+
+const (
+	RoleNone      Role = iota // 没有权限
+	RoleRead                  // 1.只读权限
+	RoleWrite                 // 2.读写权限
+	RoleOverwrite             // 3.覆盖写权限
+	RoleManage                // 4.表修改权限 (更新表的权限信息)
+)
+
+var roleNames = []string{"none", "read", "write", "overwrite", "manage"}
+
+func (r Role) String() string {
+	if int(r) < len(roleNames) {
+		return roleNames[r]
+	}
+	return fmt.Sprintf("role(%d)", uint8(r))
+}
+
+// ParseRole 解析角色, 可以是名字 (read, write, overwrite, manage) 或者原来的数字 (1-4)
+func ParseRole(s string) (Role, error) {
+	if len(s) == 1 && s[0] >= '1' && s[0] <= '4' {
+		return Role(s[0] - '0'), nil
+	}
+	for i := RoleRead; i <= RoleManage; i++ {
+		if strings.EqualFold(s, roleNames[i]) {
+			return i, nil
+		}
+	}
+	return RoleNone, fmt.Errorf("错误的角色 %s", s)
+}
+
+// Grant 一个用户的角色。
+// 在交易里面写作 地址+角色的数字 (比如 addr3), 和原来的权限表格式一致;
+// 输入时也可以写作 地址:角色 (比如 addr:overwrite)
+type Grant struct {
+	Address string
+	Role    Role
+}
+
+func (g Grant) String() string {
+	return g.Address + string('0'+byte(g.Role))
+}
+
+// ParseGrant 解析 地址:角色 或者 地址+角色的数字
+func ParseGrant(entry string) (Grant, error) {
+	address, role := "", ""
+	if i := strings.LastIndex(entry, ":"); i >= 0 {
+		address, role = entry[:i], entry[i+1:]
+	} else if len(entry) >= 2 {
+		address, role = entry[:len(entry)-1], entry[len(entry)-1:]
+	}
+	if address == "" {
+		return Grant{}, fmt.Errorf("错误的权限 %s", entry)
+	}
+	r, err := ParseRole(role)
+	if err != nil {
+		return Grant{}, fmt.Errorf("错误的权限 %s", entry)
+	}
+	return Grant{Address: address, Role: r}, nil
+}
+
+// PermissionOp 表交易对权限表的操作
+type PermissionOp uint8
+
+const (
+	OpGrant   PermissionOp = iota // 授予或者修改角色, 合并到原来的权限表 (原来的表交易都是这种)
+	OpRevoke                      // 移除用户, 权限表里面只有地址
+	OpReplace                     // 用交易里面的权限表替换原来的权限表
+	OpSchema                      // 声明或者修改表的 schema, 不修改权限表 (见 Schema)
+	OpIndex                       // 声明表的二级索引字段, 不修改权限表
+)
+
+var opNames = []string{"grant", "revoke", "replace", "schema", "index"}
+
+func (op PermissionOp) String() string {
+	if int(op) < len(opNames) {
+		return opNames[op]
+	}
+	return fmt.Sprintf("op(%d)", uint8(op))
+}
+
+// ParsePermissionOp 解析操作的名字, 空字符串为 grant
+func ParsePermissionOp(s string) (PermissionOp, error) {
+	if s == "" {
+		return OpGrant, nil
+	}
+	for i, name := range opNames {
+		if strings.EqualFold(s, name) {
+			return PermissionOp(i), nil
+		}
+	}
+	return OpGrant, fmt.Errorf("错误的操作 %s, 可以是 grant, revoke, replace, schema, index", s)
+}
+
+// revokeAddress 移除用户时的地址, 写成 地址:角色 时忽略角色
+func revokeAddress(entry string) string {
+	if i := strings.LastIndex(entry, ":"); i >= 0 {
+		return entry[:i]
+	}
+	return entry
+}
+
+// Permissions 一个共享表的权限表, 地址 -> 角色
+type Permissions map[string]Role
+
+// Apply 把交易的操作应用到权限表 p (可以为 nil), 返回新的权限表, 不修改 p。
+// 所有节点按照区块的顺序应用同样的交易, 得到同样的权限表; 解析错误的权限项被忽略
+func (p Permissions) Apply(tx *Transaction) Permissions {
+	next := make(Permissions)
+	if tx.Op != OpReplace {
+		for address, role := range p {
+			next[address] = role
+		}
+	}
+	if tx.Op == OpSchema || tx.Op == OpIndex {
+		return next
+	}
+	for _, entry := range tx.PermissionTable {
+		if tx.Op == OpRevoke {
+			delete(next, revokeAddress(entry))
+			continue
+		}
+		g, err := ParseGrant(entry)
+		if err != nil {
+			continue
+		}
+		next[g.Address] = g.Role
+	}
+	return next
+}
+
+// Managers 权限表里面管理员的数量
+func (p Permissions) Managers() int {
+	n := 0
+	for _, role := range p {
+		if role >= RoleManage {
+			n++
+		}
+	}
+	return n
+}
+
+// CheckTableName 检查新的表的名字, 不能为空, 也不能是表区块链的存储自己使用的 bucket。
+// 表名不能包含数据ID 的分隔符 -QAQ-, 否则表 a 的数据ID 前缀下面会有表 a-QAQ-b 的数据
+func CheckTableName(table string) error {
+	if table == "" {
+		return errors.New("表名不能为空")
+	}
+	if strings.Contains(table, "-QAQ-") {
+		return fmt.Errorf("表名 %s 里面不能有 -QAQ-", table)
+	}
+	for _, reserved := range reservedTables {
+		if table == reserved {
+			return fmt.Errorf("表名 %s 是保留的名字", table)
+		}
+	}
+	return nil
+}
+
+// CheckAuthority 检查表交易能否应用到表当前的权限表 current (表不存在时 exists 为 false), 返回应用以后的权限表。
+// 创建表的交易 (grant 或者 replace) 不需要权限, 表名要通过 CheckTableName, 修改已经存在的表 (包括 schema 和索引) 需要签名者有 manage 角色;
+// 修改以后表里面至少要有一个管理员
+func CheckAuthority(current Permissions, exists bool, tx *Transaction) (Permissions, error) {
+	if tx.Op > OpIndex {
+		return nil, errors.New("错误的权限操作")
+	}
+	if !exists {
+		if tx.Op != OpGrant && tx.Op != OpReplace {
+			return nil, fmt.Errorf("不存在这个表; %s", tx.Table)
+		}
+		if err := CheckTableName(tx.Table); err != nil {
+			return nil, err
+		}
+	} else if current[util.CalculateAddress(tx.PublicKey)] < RoleManage {
+		return nil, errors.New("没有对表的修改权限")
+	}
+	next := current.Apply(tx)
+	if next.Managers() == 0 {
+		return nil, errors.New("修改以后表里面没有管理员, 至少要保留一个 manage 角色")
+	}
+	return next, nil
+}
+
+// NormalizePermissions 检查并规范交易里面的权限表: grant 和 replace 写作 地址+角色的数字, revoke 只有地址
+func NormalizePermissions(op PermissionOp, entries []string) ([]string, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("权限表为空")
+	}
+	var normalized []string
+	for _, entry := range entries {
+		if op == OpRevoke {
+			address := revokeAddress(entry)
+			if address == "" {
+				return nil, fmt.Errorf("错误的地址 %s", entry)
+			}
+			normalized = append(normalized, address)
+			continue
+		}
+		g, err := ParseGrant(entry)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, g.String())
+	}
+	return normalized, nil
+}
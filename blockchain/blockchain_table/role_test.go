@@ -0,0 +1,65 @@
+package blockchain_table
+
+import (
+	"alg_bcDB/util"
+	"testing"
+)
+
+func TestCheckAuthority(t *testing.T) {
+	owner, other := []byte("owner-public-key"), []byte("other-public-key")
+	ownerAddress, otherAddress := util.CalculateAddress(owner), util.CalculateAddress(other)
+	current := Permissions{ownerAddress: RoleManage, otherAddress: RoleWrite}
+
+	tests := []struct {
+		name    string
+		current Permissions
+		exists  bool
+		signer  []byte
+		op      PermissionOp
+		entries []string
+		ok      bool
+	}{
+		{"创建表", nil, false, other, OpGrant, []string{otherAddress + "4"}, true},
+		{"创建没有管理员的表", nil, false, other, OpGrant, []string{otherAddress + "2"}, false},
+		{"移除不存在的表", nil, false, owner, OpRevoke, []string{otherAddress}, false},
+		{"管理员授予", current, true, owner, OpGrant, []string{"addr1"}, true},
+		{"非管理员授予", current, true, other, OpGrant, []string{otherAddress + "4"}, false},
+		{"非管理员移除管理员", current, true, other, OpRevoke, []string{ownerAddress}, false},
+		{"非管理员替换", current, true, other, OpReplace, []string{otherAddress + "4"}, false},
+		{"移除最后一个管理员", current, true, owner, OpRevoke, []string{ownerAddress}, false},
+		{"替换成没有管理员", current, true, owner, OpReplace, []string{otherAddress + "3"}, false},
+		{"替换管理员", current, true, owner, OpReplace, []string{otherAddress + "4"}, true},
+		{"管理员修改 schema", current, true, owner, OpSchema, nil, true},
+		{"非管理员修改 schema", current, true, other, OpSchema, nil, false},
+		{"不存在的表的 schema", nil, false, owner, OpSchema, nil, false},
+		{"管理员修改索引", current, true, owner, OpIndex, nil, true},
+		{"非管理员修改索引", current, true, other, OpIndex, nil, false},
+		{"不存在的表的索引", nil, false, owner, OpIndex, nil, false},
+	}
+	for _, test := range tests {
+		tx := &Transaction{Table: "t", Op: test.op, PermissionTable: test.entries, PublicKey: test.signer}
+		next, err := CheckAuthority(test.current, test.exists, tx)
+		if (err == nil) != test.ok {
+			t.Errorf("%s: err = %v", test.name, err)
+			continue
+		}
+		if err == nil && next.Managers() == 0 {
+			t.Errorf("%s: 应用以后没有管理员", test.name)
+		}
+	}
+}
+
+func TestCheckTableName(t *testing.T) {
+	owner := []byte("owner-public-key")
+	grant := []string{util.CalculateAddress(owner) + "4"}
+	for _, table := range []string{"", "indexBucket", "metaBucket", "blockBucket", "bodyBucket", "a-QAQ-b"} {
+		tx := &Transaction{Table: table, PermissionTable: grant, PublicKey: owner}
+		if _, err := CheckAuthority(nil, false, tx); err == nil {
+			t.Errorf("创建了表 %q", table)
+		}
+	}
+	tx := &Transaction{Table: "index", PermissionTable: grant, PublicKey: owner}
+	if _, err := CheckAuthority(nil, false, tx); err != nil {
+		t.Fatal(err)
+	}
+}
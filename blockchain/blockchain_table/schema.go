@@ -0,0 +1,224 @@
+package blockchain_table
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// 表的 schema: 由管理员用 OpSchema 的表交易声明, 交易的 Schema 是 schema 的 JSON, 为空时去掉表的 schema。
+// 表的每个 OpSchema 交易把 schema 的版本加一 (没有声明过 schema 的表版本为 0),
+// 数据交易记录写入时检查的版本, 提交时版本不是表当前的版本的写入被拒绝。
+// 有 schema 的表的值必须是 JSON 对象, 声明 schema 之前已经写入的值不再检查
+//
+// 例子: {"fields": {"temperature": {"type": "number", "required": true, "min": -20, "max": 60},
+//                  "mode": {"type": "string", "enum": ["cool", "heat"]},
+//                  "room": {"type": "string", "ref": "rooms"}}}
+
+// 字段的类型
+var fieldTypes = []string{"string", "number", "integer", "boolean", "object", "array"}
+
+// Schema 表的 schema, 字段名 -> 字段的约束。 没有声明的字段不检查
+type Schema struct {
+	Fields map[string]*Field `json:"fields"`
+}
+
+// Field 一个字段的约束
+type Field struct {
+	Type     string        `json:"type"`               // string, number, integer, boolean, object, array
+	Required bool          `json:"required,omitempty"` // 必须有这个字段 (null 和没有一样)
+	Enum     []interface{} `json:"enum,omitempty"`     // 可以取的值
+	Min      *float64      `json:"min,omitempty"`      // 数字的最小值, 字符串的最小长度
+	Max      *float64      `json:"max,omitempty"`      // 数字的最大值, 字符串的最大长度
+	Ref      string        `json:"ref,omitempty"`      // 值是这个表里面存在的 key, 字段的类型必须是 string
+}
+
+// ParseSchema 解析并检查 schema 的 JSON, 返回 schema 和规范的 JSON (所有节点保存同样的内容)。
+// 空字符串表示没有 schema, 返回 nil
+func ParseSchema(s string) (*Schema, string, error) {
+	if s == "" {
+		return nil, "", nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(s)))
+	decoder.DisallowUnknownFields()
+	var schema Schema
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, "", fmt.Errorf("错误的 schema: %v", err)
+	}
+	if len(schema.Fields) == 0 {
+		return nil, "", errors.New("schema 没有字段")
+	}
+	for name, f := range schema.Fields {
+		if err := f.check(); err != nil {
+			return nil, "", fmt.Errorf("字段 %s: %v", name, err)
+		}
+	}
+	canonical, err := json.Marshal(&schema)
+	if err != nil {
+		return nil, "", err
+	}
+	return &schema, string(canonical), nil
+}
+
+// check 检查字段的约束是否正确
+func (f *Field) check() error {
+	if f == nil {
+		return errors.New("没有约束")
+	}
+	known := false
+	for _, t := range fieldTypes {
+		known = known || f.Type == t
+	}
+	if !known {
+		return fmt.Errorf("错误的类型 %s, 可以是 string, number, integer, boolean, object, array", f.Type)
+	}
+	for _, v := range f.Enum {
+		if !f.hasType(v) {
+			return fmt.Errorf("enum 的值 %v 不是 %s", v, f.Type)
+		}
+	}
+	if (f.Min != nil || f.Max != nil) && f.Type != "string" && f.Type != "number" && f.Type != "integer" {
+		return errors.New("只有 string, number, integer 可以有范围")
+	}
+	if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+		return errors.New("min 大于 max")
+	}
+	if f.Ref != "" && f.Type != "string" {
+		return errors.New("引用其他表的字段的类型必须是 string")
+	}
+	return nil
+}
+
+// hasType 解码后的 JSON 值是否是字段的类型
+func (f *Field) hasType(v interface{}) bool {
+	switch f.Type {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	}
+	return false
+}
+
+// Validate 检查值是否符合 schema。 exists 查询引用的表里面 key 是否存在
+func (s *Schema) Validate(value []byte, exists func(table, key string) bool) error {
+	var object map[string]interface{}
+	if err := json.Unmarshal(value, &object); err != nil || object == nil {
+		return errors.New("值必须是 JSON 对象")
+	}
+	for name, f := range s.Fields {
+		v := object[name]
+		if v == nil {
+			if f.Required {
+				return fmt.Errorf("缺少字段 %s", name)
+			}
+			continue
+		}
+		if !f.hasType(v) {
+			return fmt.Errorf("字段 %s 的类型应该是 %s", name, f.Type)
+		}
+		if len(f.Enum) != 0 && !f.inEnum(v) {
+			return fmt.Errorf("字段 %s 的值不在 %v 里面", name, f.Enum)
+		}
+		if err := f.checkRange(name, v); err != nil {
+			return err
+		}
+		if f.Ref != "" && !exists(f.Ref, v.(string)) {
+			return fmt.Errorf("字段 %s 引用的 key %s 在表 %s 里面不存在", name, v, f.Ref)
+		}
+	}
+	return nil
+}
+
+func (f *Field) inEnum(v interface{}) bool {
+	for _, e := range f.Enum {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRange 数字的大小或者字符串的长度在 [Min, Max] 里面
+func (f *Field) checkRange(name string, v interface{}) error {
+	var n float64
+	switch v := v.(type) {
+	case float64:
+		n = v
+	case string:
+		n = float64(len([]rune(v)))
+	default:
+		return nil
+	}
+	if f.Min != nil && n < *f.Min {
+		return fmt.Errorf("字段 %s 小于最小值 %v", name, *f.Min)
+	}
+	if f.Max != nil && n > *f.Max {
+		return fmt.Errorf("字段 %s 大于最大值 %v", name, *f.Max)
+	}
+	return nil
+}
+
+// Refs schema 引用的表
+func (s *Schema) Refs() []string {
+	var refs []string
+	for _, f := range s.Fields {
+		if f.Ref != "" {
+			refs = append(refs, f.Ref)
+		}
+	}
+	return refs
+}
+
+// InitSchema 声明或者修改表的 schema 的交易, schema 为 ParseSchema 返回的规范的 JSON, 为空时去掉表的 schema
+func (tx *Transaction) InitSchema(table, schema string, possessor string, publicKey []byte, privateKey ecdsa.PrivateKey) {
+	tx.Table = table
+	tx.Op = OpSchema
+	tx.Schema = schema
+	tx.Possessor = possessor
+	tx.TimeStamp = time.Now().Unix()
+	tx.PublicKey = publicKey
+
+	tx.SetTxID()
+	tx.Sign(&privateKey)
+}
+
+// CheckSchema 检查交易的 schema: OpSchema 的交易没有权限表, schema 可以解析; 其他交易没有 schema
+func (tx *Transaction) CheckSchema() error {
+	if tx.Op != OpSchema {
+		if tx.Schema != "" {
+			return errors.New("只有 schema 交易可以有 schema")
+		}
+		return nil
+	}
+	if len(tx.PermissionTable) != 0 {
+		return errors.New("schema 交易不能修改权限表")
+	}
+	_, canonical, err := ParseSchema(tx.Schema)
+	if err != nil {
+		return err
+	}
+	if canonical != tx.Schema {
+		return errors.New("schema 不是规范的 JSON")
+	}
+	return nil
+}
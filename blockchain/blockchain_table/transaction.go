@@ -111,7 +111,6 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
-	"encoding/gob"
 	"log"
 	"math/big"
 	"time"
@@ -129,18 +128,23 @@ type Transaction struct {
 	// 2.读写权限 ReadWrite 2
 	// 3.覆盖写权限 Overwrite 3
 	// 4.表修改权限（更新表权限信息等） TableManger 4
+	// 见 Role, 权限项的格式见 Grant
 	PermissionTable []string
-	Possessor       string // 谁发布了这个表
-	TimeStamp       int64  // 交易在本地生成的时间戳. 是在区块链中的生效日期。
+	Op              PermissionOp // 对权限表的操作: 授予, 移除, 替换, 或者声明 schema, 索引
+	Schema          string       // OpSchema 交易的 schema 的 JSON (见 ParseSchema)
+	Indexes         []string     // OpIndex 交易的所有索引字段 (见 NormalizeIndexes)
+	Possessor       string       // 谁发布了这个表
+	TimeStamp       int64        // 交易在本地生成的时间戳. 是在区块链中的生效日期。
 	// 验证信息
 	PublicKey []byte // 交易所有者的公钥
 	Signature []byte // 交易所有者的签名
 }
 
-func (tx *Transaction) Init(table string, permissionTable []string,
+func (tx *Transaction) Init(table string, op PermissionOp, permissionTable []string,
 	possessor string, publicKey []byte, privateKey ecdsa.PrivateKey) {
 	// init
 	tx.Table = table
+	tx.Op = op
 	tx.PermissionTable = permissionTable
 	tx.Possessor = possessor // 这条数据的所有者。
 	tx.TimeStamp = time.Now().Unix()
@@ -150,15 +154,9 @@ func (tx *Transaction) Init(table string, permissionTable []string,
 	tx.Sign(&privateKey) // nil
 }
 
-// SetTxID 交易的标识ID，表明这笔交易的唯一性。
+// SetTxID 交易的标识ID，表明这笔交易的唯一性。 TxID = sha256(交易的签名内容)
 func (tx *Transaction) SetTxID() {
-	var buffer bytes.Buffer
-	encoder := gob.NewEncoder(&buffer)
-	err := encoder.Encode(transactionsToBytes(*tx))
-	if err != nil {
-		log.Panic(err)
-	}
-	txHash := sha256.Sum256(buffer.Bytes())
+	txHash := sha256.Sum256(tx.SigningBytes())
 
 	tx.TxID = txHash[:]
 }
@@ -185,10 +183,12 @@ func VerifyTransaction(txVerify Transaction) bool {
 	x.SetBytes(xData)
 	y.SetBytes(yData)
 
-	// 得到待验证的hash
-	txVerify.Signature = nil
-	txVerify.TxID = nil
+	// 得到待验证的hash, 和交易里面的 TxID 要一致
+	txID := txVerify.TxID
 	txVerify.SetTxID()
+	if !bytes.Equal(txID, txVerify.TxID) {
+		return false
+	}
 
 	curve := elliptic.P256()
 	ecdsaPublicKey := ecdsa.PublicKey{Curve: curve, X: &x, Y: &y}
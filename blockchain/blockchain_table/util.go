@@ -1,39 +1,67 @@
 package blockchain_table
 
 import (
-	"alg_bcDB/MerkleTree"
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/util"
 	"bytes"
 	"errors"
 	"fmt"
-	"github.com/boltdb/bolt"
 	"log"
 )
 
-func (blockChain *BlockChain) CheckTableBlock(block *Block) bool {
+// CheckTableBlock 校验表区块。 current 查询表当前的权限表 (表不存在时返回错误),
+// 区块里面的交易按照顺序检查签名者的权限 (见 CheckAuthority)
+func (blockChain *BlockChain) CheckTableBlock(block *Block, current func(table string) (Permissions, error)) bool {
 	//判断是否为创世区块
 	if bytes.Equal(block.PreviousBlockHash, []byte("welcome to 407")) {
 		fmt.Println("权限区块验证:  区块为创世区块")
 		return true
 	}
 	// 校验交易是否合法
+	applied := make(map[string]Permissions)
 	for i := 0; i < len(block.Transactions); i++ {
 		if !VerifyTransaction(*block.Transactions[i]) {
 			fmt.Println("权限区块验证:  交易检验错误")
 			return false
 		}
+		if block.Transactions[i].Op > OpIndex {
+			fmt.Println("权限区块验证:  错误的权限操作")
+			return false
+		}
+		if err := block.Transactions[i].CheckSchema(); err != nil {
+			fmt.Println("权限区块验证: ", err)
+			return false
+		}
+		if err := block.Transactions[i].CheckIndexes(); err != nil {
+			fmt.Println("权限区块验证: ", err)
+			return false
+		}
+		tx := block.Transactions[i]
+		p, exists := applied[tx.Table]
+		if !exists {
+			var err error
+			p, err = current(tx.Table)
+			exists = err == nil
+		}
+		next, err := CheckAuthority(p, exists, tx)
+		if err != nil {
+			fmt.Println("权限区块验证: ", err)
+			return false
+		}
+		applied[tx.Table] = next
 	}
 	// 校验默克尔根
-	var MerKelRootData [][]byte
-	// 类型转换
-	for i := 0; i < len(block.Transactions); i++ {
-		MerKelRootData = append(MerKelRootData, transactionsToBytes(*block.Transactions[i]))
-	}
-	MerKelRoot := MerkleTree.GetMerkleRoot(MerKelRootData).Hash
+	MerKelRoot := block.ComputeMerkleRoot()
 	if !bytes.Equal(MerKelRoot, block.MerKelRoot) {
 		fmt.Println("权限区块验证:  默克尔根错误")
 		log.Panic("权限区块验证:  默克尔根错误")
 		return false
 	}
+	// 校验区块的 HASH
+	if !bytes.Equal(block.ComputeHash(), block.CurrentBlockHash) {
+		fmt.Println("权限区块验证:  区块HASH错误")
+		return false
+	}
 	return true
 }
 
@@ -41,12 +69,8 @@ func (blockChain *BlockChain) CheckTableBlock(block *Block) bool {
 func (blockChain *BlockChain) GetBlockByHash(hash []byte) (*Block, error) {
 	block := Block{}
 	// 直接在数据库中查找
-	err := blockChain.Db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(blockChain.BlockBucket))
-		if bucket == nil {
-			return errors.New("not found bucket")
-		}
-		value := bucket.Get(hash)
+	err := blockChain.Store.View(func(tx blockstore.Tx) error {
+		value := tx.GetBlock(hash)
 		if len(value) == 0 {
 			return errors.New(fmt.Sprintf(" not the key"))
 		}
@@ -58,3 +82,37 @@ func (blockChain *BlockChain) GetBlockByHash(hash []byte) (*Block, error) {
 	}
 	return &block, nil
 }
+
+// GetByID 通过 ID 得到区块, 直接读取 ID -> 区块 HASH 的索引
+func (blockChain *BlockChain) GetByID(id int) *Block {
+	hash := blockChain.GetHashByID(id)
+	if hash == nil {
+		return nil
+	}
+	block, err := blockChain.GetBlockByHash(hash)
+	if err != nil {
+		log.Println("GetByID 获取区块失败")
+		return nil
+	}
+	return block
+}
+
+// GetHashByID 通过 ID 得到区块的 HASH, 不存在时返回 nil
+func (blockChain *BlockChain) GetHashByID(id int) []byte {
+	var hash []byte
+	err := blockChain.Store.View(func(tx blockstore.Tx) error {
+		bucket := tx.Bucket(blockChain.IndexBucket)
+		if bucket == nil {
+			return errors.New("not found bucket")
+		}
+		if value := bucket.Get(util.Uint64ToBytes(uint64(id))); value != nil {
+			hash = append([]byte{}, value...)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return hash
+}
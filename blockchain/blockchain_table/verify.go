@@ -0,0 +1,83 @@
+package blockchain_table
+
+import (
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/common"
+	"alg_bcDB/util"
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// VerifyChain 从创世区块到链尾审计本地的表区块链。
+// 按照 ID 索引遍历, 检查 前一个区块的HASH, 区块HASH, 默克尔根, 所有交易的签名
+func (blockChain *BlockChain) VerifyChain() *common.ChainReport {
+	report := common.NewChainReport("table")
+	var prevHash []byte
+	blockChain.Store.View(func(tx blockstore.Tx) error {
+		indexBucket := tx.Bucket(blockChain.IndexBucket)
+		if indexBucket == nil {
+			report.AddIssue(1, nil, "index", "索引不存在")
+			return nil
+		}
+		// 创世区块的 ID 为 1
+		for id := 1; id < blockChain.LastID; id++ {
+			hash := indexBucket.Get(util.Uint64ToBytes(uint64(id)))
+			if hash == nil {
+				report.AddIssue(uint64(id), nil, "index", "索引里面没有这个区块")
+				prevHash = nil
+				continue
+			}
+			data := tx.GetBlock(hash)
+			if len(data) == 0 {
+				report.AddIssue(uint64(id), hash, "index", "存储里面没有这个区块")
+				prevHash = nil
+				continue
+			}
+			var block Block
+			if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&block); err != nil {
+				report.AddIssue(uint64(id), hash, "hash", "解码区块失败: "+err.Error())
+				prevHash = nil
+				continue
+			}
+			report.Blocks++
+			blockChain.verifyBlock(report, &block, id, hash, prevHash)
+			prevHash = hash
+		}
+		if tail := tx.Tail(); !bytes.Equal(tail, prevHash) {
+			report.AddIssue(uint64(blockChain.LastID-1), tail, "tail", "链尾区块和索引里面最新的区块不一致")
+		}
+		return nil
+	})
+	return report
+}
+
+// verifyBlock 审计单个区块, prevHash 为 nil 表示前一个区块已经出错, 不检查链接
+func (blockChain *BlockChain) verifyBlock(report *common.ChainReport, block *Block, id int, hash, prevHash []byte) {
+	round := uint64(id)
+	if !bytes.Equal(block.CurrentBlockHash, hash) || block.ID != id {
+		report.AddIssue(round, hash, "index", fmt.Sprintf("索引指向的区块不一致 (ID %d)", block.ID))
+	}
+	if id == 1 {
+		if !bytes.Equal(block.PreviousBlockHash, []byte("welcome to 407")) {
+			report.AddIssue(round, hash, "link", "创世区块的前一个区块HASH错误")
+		}
+	} else if prevHash != nil && !bytes.Equal(block.PreviousBlockHash, prevHash) {
+		report.AddIssue(round, hash, "link", fmt.Sprintf("前一个区块HASH %x, 应该是 %x", block.PreviousBlockHash, prevHash))
+	}
+	if !bytes.Equal(block.ComputeHash(), block.CurrentBlockHash) {
+		report.AddIssue(round, hash, "hash", "区块HASH错误")
+	}
+	if !bytes.Equal(block.ComputeMerkleRoot(), block.MerKelRoot) {
+		report.AddIssue(round, hash, "merkle", "默克尔根错误")
+	}
+	// 创世区块的交易没有签名
+	if id == 1 {
+		return
+	}
+	for i, tx := range block.Transactions {
+		if !VerifyTransaction(*tx) {
+			report.AddIssue(round, hash, "transaction", fmt.Sprintf("第 %d 个交易 %x 签名错误", i, tx.TxID))
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package blockstore
+
+import (
+	"errors"
+	"github.com/boltdb/bolt"
+)
+
+// boltStore 基于 boltDB 文件的存储
+type boltStore struct {
+	db *bolt.DB
+}
+
+// OpenBolt 打开(不存在时创建) boltDB 文件作为存储
+func OpenBolt(path string) (BlockStore, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(BlockBucket))
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte(BodyBucket))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltStore{db: db}, nil
+}
+
+func (s *boltStore) View(fn func(tx Tx) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+func (s *boltStore) Update(fn func(tx Tx) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
+
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t *boltTx) GetBlock(hash []byte) []byte {
+	if len(hash) == 0 {
+		return nil
+	}
+	return t.tx.Bucket([]byte(BlockBucket)).Get(hash)
+}
+
+func (t *boltTx) PutBlock(hash, data []byte) error {
+	if len(hash) == 0 {
+		return errors.New("empty block hash")
+	}
+	return t.tx.Bucket([]byte(BlockBucket)).Put(hash, data)
+}
+
+func (t *boltTx) GetBody(hash []byte) []byte {
+	if len(hash) == 0 {
+		return nil
+	}
+	return t.tx.Bucket([]byte(BodyBucket)).Get(hash)
+}
+
+func (t *boltTx) PutBody(hash, data []byte) error {
+	if len(hash) == 0 {
+		return errors.New("empty block hash")
+	}
+	return t.tx.Bucket([]byte(BodyBucket)).Put(hash, data)
+}
+
+func (t *boltTx) Tail() []byte {
+	return t.tx.Bucket([]byte(BlockBucket)).Get([]byte(TailKey))
+}
+
+func (t *boltTx) SetTail(hash []byte) error {
+	return t.tx.Bucket([]byte(BlockBucket)).Put([]byte(TailKey), hash)
+}
+
+func (t *boltTx) Bucket(name string) Bucket {
+	bucket := t.tx.Bucket([]byte(name))
+	if bucket == nil {
+		return nil
+	}
+	return &boltBucket{bucket: bucket}
+}
+
+func (t *boltTx) CreateBucketIfNotExists(name string) (Bucket, error) {
+	bucket, err := t.tx.CreateBucketIfNotExists([]byte(name))
+	if err != nil {
+		return nil, err
+	}
+	return &boltBucket{bucket: bucket}, nil
+}
+
+func (t *boltTx) DeleteBucket(name string) error {
+	err := t.tx.DeleteBucket([]byte(name))
+	if err == bolt.ErrBucketNotFound {
+		return nil
+	}
+	return err
+}
+
+type boltBucket struct {
+	bucket *bolt.Bucket
+}
+
+func (b *boltBucket) Get(key []byte) []byte {
+	return b.bucket.Get(key)
+}
+
+func (b *boltBucket) Put(key, value []byte) error {
+	return b.bucket.Put(key, value)
+}
+
+func (b *boltBucket) Delete(key []byte) error {
+	return b.bucket.Delete(key)
+}
+
+func (b *boltBucket) ForEach(fn func(k, v []byte) error) error {
+	return b.bucket.ForEach(fn)
+}
+
+func (b *boltBucket) Cursor() Cursor {
+	return b.bucket.Cursor()
+}
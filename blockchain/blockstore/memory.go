@@ -0,0 +1,292 @@
+package blockstore
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// memoryStore 内存中的存储, 主要用于单元测试, 不会在工作目录生成 .db 文件。
+// Update 采用写时复制: 事务第一次修改某个 bucket 时复制一份, 提交时整体替换。
+type memoryStore struct {
+	sync.RWMutex
+	buckets map[string]*memoryBucket
+	closed  bool
+}
+
+// NewMemory 创建一个空的内存存储
+func NewMemory() BlockStore {
+	s := &memoryStore{buckets: make(map[string]*memoryBucket)}
+	s.buckets[BlockBucket] = newMemoryBucket()
+	s.buckets[BodyBucket] = newMemoryBucket()
+	return s
+}
+
+func (s *memoryStore) View(fn func(tx Tx) error) error {
+	s.RLock()
+	defer s.RUnlock()
+	if s.closed {
+		return errors.New("store closed")
+	}
+	return fn(&memoryTx{buckets: s.buckets})
+}
+
+func (s *memoryStore) Update(fn func(tx Tx) error) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return errors.New("store closed")
+	}
+	tx := &memoryTx{writable: true, copied: make(map[string]bool)}
+	tx.buckets = make(map[string]*memoryBucket, len(s.buckets))
+	for name, bucket := range s.buckets {
+		tx.buckets[name] = bucket
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	s.buckets = tx.buckets
+	return nil
+}
+
+func (s *memoryStore) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	s.closed = true
+	return nil
+}
+
+type memoryTx struct {
+	buckets  map[string]*memoryBucket
+	writable bool
+	copied   map[string]bool // 本事务里面已经复制过的 bucket
+}
+
+// writableBucket 得到可以修改的 bucket, 第一次修改时复制
+func (t *memoryTx) writableBucket(name string) (*memoryBucket, error) {
+	if !t.writable {
+		return nil, errors.New("tx not writable")
+	}
+	bucket, has := t.buckets[name]
+	if !has {
+		return nil, errors.New("bucket not found")
+	}
+	if !t.copied[name] {
+		bucket = bucket.clone()
+		t.buckets[name] = bucket
+		t.copied[name] = true
+	}
+	return bucket, nil
+}
+
+func (t *memoryTx) GetBlock(hash []byte) []byte {
+	if len(hash) == 0 {
+		return nil
+	}
+	return t.buckets[BlockBucket].Get(hash)
+}
+
+func (t *memoryTx) PutBlock(hash, data []byte) error {
+	if len(hash) == 0 {
+		return errors.New("empty block hash")
+	}
+	bucket, err := t.writableBucket(BlockBucket)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(hash, data)
+}
+
+func (t *memoryTx) GetBody(hash []byte) []byte {
+	if len(hash) == 0 {
+		return nil
+	}
+	return t.buckets[BodyBucket].Get(hash)
+}
+
+func (t *memoryTx) PutBody(hash, data []byte) error {
+	if len(hash) == 0 {
+		return errors.New("empty block hash")
+	}
+	bucket, err := t.writableBucket(BodyBucket)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(hash, data)
+}
+
+func (t *memoryTx) Tail() []byte {
+	return t.buckets[BlockBucket].Get([]byte(TailKey))
+}
+
+func (t *memoryTx) SetTail(hash []byte) error {
+	bucket, err := t.writableBucket(BlockBucket)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(TailKey), hash)
+}
+
+func (t *memoryTx) Bucket(name string) Bucket {
+	bucket, has := t.buckets[name]
+	if !has {
+		return nil
+	}
+	return &memoryBucketHandle{tx: t, name: name, bucket: bucket}
+}
+
+func (t *memoryTx) CreateBucketIfNotExists(name string) (Bucket, error) {
+	if !t.writable {
+		return nil, errors.New("tx not writable")
+	}
+	if _, has := t.buckets[name]; !has {
+		t.buckets[name] = newMemoryBucket()
+		t.copied[name] = true
+	}
+	return t.Bucket(name), nil
+}
+
+func (t *memoryTx) DeleteBucket(name string) error {
+	if !t.writable {
+		return errors.New("tx not writable")
+	}
+	delete(t.buckets, name)
+	delete(t.copied, name)
+	return nil
+}
+
+// memoryBucket 一个 bucket 的数据
+type memoryBucket struct {
+	data map[string][]byte
+}
+
+func newMemoryBucket() *memoryBucket {
+	return &memoryBucket{data: make(map[string][]byte)}
+}
+
+func (b *memoryBucket) clone() *memoryBucket {
+	nb := newMemoryBucket()
+	for k, v := range b.data {
+		nb.data[k] = v
+	}
+	return nb
+}
+
+// Get 返回值的副本, 调用者修改返回的 []byte 不会影响存储
+func (b *memoryBucket) Get(key []byte) []byte {
+	value, has := b.data[string(key)]
+	if !has {
+		return nil
+	}
+	return append([]byte{}, value...)
+}
+
+func (b *memoryBucket) Put(key, value []byte) error {
+	if len(key) == 0 {
+		return errors.New("empty key")
+	}
+	b.data[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+// memoryBucketHandle 事务里面对 bucket 的引用, 修改时才复制 bucket
+type memoryBucketHandle struct {
+	tx     *memoryTx
+	name   string
+	bucket *memoryBucket
+}
+
+func (h *memoryBucketHandle) current() *memoryBucket {
+	if bucket, has := h.tx.buckets[h.name]; has {
+		return bucket
+	}
+	return h.bucket
+}
+
+func (h *memoryBucketHandle) Get(key []byte) []byte {
+	return h.current().Get(key)
+}
+
+func (h *memoryBucketHandle) Put(key, value []byte) error {
+	bucket, err := h.tx.writableBucket(h.name)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, value)
+}
+
+func (h *memoryBucketHandle) Delete(key []byte) error {
+	bucket, err := h.tx.writableBucket(h.name)
+	if err != nil {
+		return err
+	}
+	delete(bucket.data, string(key))
+	return nil
+}
+
+func (h *memoryBucketHandle) ForEach(fn func(k, v []byte) error) error {
+	c := h.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if err := fn(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *memoryBucketHandle) Cursor() Cursor {
+	bucket := h.current()
+	keys := make([]string, 0, len(bucket.data))
+	for k := range bucket.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return &memoryCursor{bucket: bucket, keys: keys, pos: -1}
+}
+
+// memoryCursor 在创建时对 key 排序, 之后按下标移动
+type memoryCursor struct {
+	bucket *memoryBucket
+	keys   []string
+	pos    int
+}
+
+func (c *memoryCursor) item() ([]byte, []byte) {
+	if c.pos < 0 || c.pos >= len(c.keys) {
+		return nil, nil
+	}
+	k := c.keys[c.pos]
+	return []byte(k), c.bucket.Get([]byte(k))
+}
+
+func (c *memoryCursor) First() ([]byte, []byte) {
+	c.pos = 0
+	return c.item()
+}
+
+func (c *memoryCursor) Last() ([]byte, []byte) {
+	c.pos = len(c.keys) - 1
+	return c.item()
+}
+
+func (c *memoryCursor) Seek(seek []byte) ([]byte, []byte) {
+	c.pos = sort.Search(len(c.keys), func(i int) bool {
+		return bytes.Compare([]byte(c.keys[i]), seek) >= 0
+	})
+	return c.item()
+}
+
+func (c *memoryCursor) Next() ([]byte, []byte) {
+	if c.pos < len(c.keys) {
+		c.pos++
+	}
+	return c.item()
+}
+
+func (c *memoryCursor) Prev() ([]byte, []byte) {
+	if c.pos >= 0 {
+		c.pos--
+	}
+	return c.item()
+}
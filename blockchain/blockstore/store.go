@@ -0,0 +1,65 @@
+package blockstore
+
+// BlockStore 区块链的存储接口。
+// 数据链和表链都通过它保存区块, 链尾指针, 索引以及每个共享表的相关链。
+// 目前有两种实现: 1. boltDB (OpenBolt) 2. 内存 (NewMemory, 用于单元测试, 不会生成 .db 文件)
+// 所有的读写都在事务里面进行。Update 里面的修改要么全部生效, 要么全部不生效。
+// tip 在事务里面读到的 []byte 只在事务里面有效, 需要在事务外使用时要复制一份。
+
+const (
+	BlockBucket = "blockBucket" // BlockBucket 保存 区块HASH -> 区块(区块头) 的 bucket
+	BodyBucket  = "bodyBucket"  // BodyBucket 保存 区块HASH -> 区块体 的 bucket, 区块头和区块体分开保存时使用
+	TailKey     = "lastHashKey" // TailKey 在 BlockBucket 里面保存链尾区块的 HASH
+)
+
+// BlockStore 存储
+type BlockStore interface {
+	// View 只读事务
+	View(fn func(tx Tx) error) error
+	// Update 读写事务, fn 返回 error 时所有修改回滚
+	Update(fn func(tx Tx) error) error
+	// Close 关闭存储
+	Close() error
+}
+
+// Tx 存储的事务
+type Tx interface {
+	// GetBlock 通过区块 HASH 得到序列化的区块, 不存在时返回 nil
+	GetBlock(hash []byte) []byte
+	// PutBlock 保存序列化的区块
+	PutBlock(hash, data []byte) error
+	// GetBody 通过区块 HASH 得到序列化的区块体, 不存在时返回 nil
+	GetBody(hash []byte) []byte
+	// PutBody 保存序列化的区块体
+	PutBody(hash, data []byte) error
+	// Tail 返回链尾区块的 HASH, 空的存储返回 nil
+	Tail() []byte
+	// SetTail 更新链尾区块的 HASH
+	SetTail(hash []byte) error
+
+	// Bucket 得到有名字的 bucket (索引, 共享表相关链等), 不存在时返回 nil
+	Bucket(name string) Bucket
+	// CreateBucketIfNotExists 创建有名字的 bucket
+	CreateBucketIfNotExists(name string) (Bucket, error)
+	// DeleteBucket 删除有名字的 bucket
+	DeleteBucket(name string) error
+}
+
+// Bucket 一个有序的键值空间
+type Bucket interface {
+	Get(key []byte) []byte
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	// ForEach 按照 key 的字节序遍历
+	ForEach(fn func(k, v []byte) error) error
+	Cursor() Cursor
+}
+
+// Cursor 按照 key 的字节序遍历 bucket, 到达末尾时返回 nil key
+type Cursor interface {
+	First() (key []byte, value []byte)
+	Last() (key []byte, value []byte)
+	Seek(seek []byte) (key []byte, value []byte)
+	Next() (key []byte, value []byte)
+	Prev() (key []byte, value []byte)
+}
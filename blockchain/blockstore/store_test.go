@@ -0,0 +1,187 @@
+package blockstore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stores 两种实现都要通过同样的测试
+func stores(t *testing.T) map[string]BlockStore {
+	bolt, err := OpenBolt(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]BlockStore{"bolt": bolt, "memory": NewMemory()}
+}
+
+func TestStoreBlocks(t *testing.T) {
+	for name, s := range stores(t) {
+		err := s.Update(func(tx Tx) error {
+			if tx.Tail() != nil {
+				return errors.New("空的存储有链尾")
+			}
+			if err := tx.PutBlock(nil, []byte("x")); err == nil {
+				return errors.New("空的区块 HASH 可以保存")
+			}
+			if err := tx.PutBlock([]byte("h1"), []byte("header")); err != nil {
+				return err
+			}
+			if err := tx.PutBody([]byte("h1"), []byte("body")); err != nil {
+				return err
+			}
+			return tx.SetTail([]byte("h1"))
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		err = s.View(func(tx Tx) error {
+			if !bytes.Equal(tx.Tail(), []byte("h1")) ||
+				!bytes.Equal(tx.GetBlock([]byte("h1")), []byte("header")) ||
+				!bytes.Equal(tx.GetBody([]byte("h1")), []byte("body")) {
+				return errors.New("读到的区块不一致")
+			}
+			if tx.GetBlock([]byte("h2")) != nil || tx.GetBlock(nil) != nil {
+				return errors.New("不存在的区块不是 nil")
+			}
+			if tx.PutBlock([]byte("h2"), nil) == nil {
+				return errors.New("只读事务可以写入")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		s.Close()
+	}
+}
+
+func TestStoreRollback(t *testing.T) {
+	for name, s := range stores(t) {
+		fail := errors.New("fail")
+		err := s.Update(func(tx Tx) error {
+			bucket, err := tx.CreateBucketIfNotExists("index")
+			if err != nil {
+				return err
+			}
+			if err = bucket.Put([]byte("k"), []byte("v")); err != nil {
+				return err
+			}
+			if err = tx.PutBlock([]byte("h1"), []byte("header")); err != nil {
+				return err
+			}
+			return fail
+		})
+		if err != fail {
+			t.Fatalf("%s: err = %v", name, err)
+		}
+		s.View(func(tx Tx) error {
+			if tx.Bucket("index") != nil || tx.GetBlock([]byte("h1")) != nil {
+				t.Errorf("%s: 回滚的修改可见", name)
+			}
+			return nil
+		})
+		s.Close()
+	}
+}
+
+func TestStoreBuckets(t *testing.T) {
+	keys := []string{"b", "d", "a", "c"}
+	for name, s := range stores(t) {
+		err := s.Update(func(tx Tx) error {
+			bucket, err := tx.CreateBucketIfNotExists("index")
+			if err != nil {
+				return err
+			}
+			for _, k := range keys {
+				if err = bucket.Put([]byte(k), []byte("v"+k)); err != nil {
+					return err
+				}
+			}
+			if err = bucket.Delete([]byte("d")); err != nil {
+				return err
+			}
+			// 修改对同一个事务里面后面的读取可见
+			if !bytes.Equal(bucket.Get([]byte("a")), []byte("va")) || bucket.Get([]byte("d")) != nil {
+				return errors.New("事务里面读不到自己的修改")
+			}
+			if _, err = tx.CreateBucketIfNotExists("other"); err != nil {
+				return err
+			}
+			if err = tx.DeleteBucket("other"); err != nil {
+				return err
+			}
+			// 删除不存在的 bucket 不是错误
+			return tx.DeleteBucket("missing")
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		err = s.View(func(tx Tx) error {
+			if tx.Bucket("other") != nil {
+				return errors.New("删除的 bucket 还存在")
+			}
+			bucket := tx.Bucket("index")
+			var got []string
+			bucket.ForEach(func(k, v []byte) error {
+				got = append(got, string(k)+"="+string(v))
+				return nil
+			})
+			if want := "a=va b=vb c=vc"; strings.Join(got, " ") != want {
+				return fmt.Errorf("ForEach = %v", got)
+			}
+
+			c := bucket.Cursor()
+			steps := []struct {
+				name string
+				move func() ([]byte, []byte)
+				want string
+			}{
+				{"Seek", func() ([]byte, []byte) { return c.Seek([]byte("bb")) }, "c"},
+				{"Next 到末尾", c.Next, ""},
+				{"Last", c.Last, "c"},
+				{"Prev", c.Prev, "b"},
+				{"First", c.First, "a"},
+				{"Seek 超过最后一个 key", func() ([]byte, []byte) { return c.Seek([]byte("z")) }, ""},
+			}
+			for _, step := range steps {
+				k, v := step.move()
+				if string(k) != step.want || (k == nil) != (step.want == "") {
+					return fmt.Errorf("cursor %s: key = %q", step.name, k)
+				}
+				if k != nil && string(v) != "v"+step.want {
+					return fmt.Errorf("cursor %s: value = %q", step.name, v)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		s.Close()
+	}
+}
+
+func TestMemoryGetCopies(t *testing.T) {
+	s := NewMemory()
+	s.Update(func(tx Tx) error {
+		bucket, _ := tx.CreateBucketIfNotExists("index")
+		return bucket.Put([]byte("k"), []byte("value"))
+	})
+	s.View(func(tx Tx) error {
+		bucket := tx.Bucket("index")
+		bucket.Get([]byte("k"))[0] = 'X'
+		_, v := bucket.Cursor().First()
+		v[0] = 'Y'
+		return nil
+	})
+	s.View(func(tx Tx) error {
+		if v := tx.Bucket("index").Get([]byte("k")); string(v) != "value" {
+			t.Errorf("修改 Get 返回的值影响了存储: %s", v)
+		}
+		return nil
+	})
+}
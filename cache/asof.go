@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/util"
+	"bytes"
+	"errors"
+	"log"
+	"sort"
+)
+
+// 按照 Round 的时间点查询 (as-of)。
+// 表的相关链按照区块上链的顺序保存包含这个表的交易的区块, Round 是递增的。
+// 查询只看 Round 不大于指定 Round 的区块, 后面的区块里面的数据优先;
+// 同一个区块里面有同一个数据的多个交易时, 只按照区块里面的顺序取最后一个, 不看时间戳 (和数据索引一样)。
+// 数据在那个时间点最新的交易是墓碑时, 数据已经被删除, 查询的结果里面没有这个数据。
+// 只使用区块链上的信息, 每个节点的结果都一样
+
+// AsOfValue 时间点查询的结果, 数据的交易和交易所在区块的 Round
+type AsOfValue struct {
+	Transaction blockchain_data.Transaction
+	Round       uint64
+}
+
+// latestInBlock 区块里面数据 dataID 最新的交易, 没有时返回 nil
+func latestInBlock(block *blockchain_data.Block, dataID []byte) *blockchain_data.Transaction {
+	var latest *blockchain_data.Transaction
+	for _, tx := range block.Writes() {
+		if bytes.Equal(tx.DataID, dataID) {
+			latest = tx
+		}
+	}
+	return latest
+}
+
+// positionAtRound 在表的相关链里面找到 Round 不大于 round 的最后一个区块的位置, 0 表示没有
+func (tio *tableInfo) positionAtRound(bucket blockstore.Bucket, tableName string, round uint64) uint64 {
+	blockRound := func(position uint64) uint64 {
+		header, err := tio.dataChain.GetHeaderByHash(bucket.Get(util.Uint64ToBytes(position)))
+		if err != nil {
+			log.Panic(err)
+		}
+		return header.Round
+	}
+	// 位置 lo 的区块 Round <= round, 位置 hi 的区块 Round > round
+	lo, hi := uint64(0), util.BytesToUint64(bucket.Get([]byte(tableName)))+1
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if blockRound(mid) <= round {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// walkAsOf 从 round 时的最后一个区块开始, 沿着表的相关链向前遍历区块, fn 返回 false 时停止
+func (tio *tableInfo) walkAsOf(tableName string, round uint64, fn func(block *blockchain_data.Block) bool) error {
+	tio.RLock()
+	defer tio.RUnlock()
+
+	return tio.tableChain.Store.View(func(tx blockstore.Tx) error {
+		bucket := tx.Bucket(tableName)
+		if bucket == nil {
+			return errors.New("no such table")
+		}
+		for position := tio.positionAtRound(bucket, tableName, round); position > 0; position-- {
+			block := tio.getBlock(bucket.Get(util.Uint64ToBytes(position)))
+			if !fn(&block) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// GetValueAsOf 查询数据在 round 时的值
+func (c *Cache) GetValueAsOf(dataID, tableName string, round uint64) (AsOfValue, error) {
+	var value AsOfValue
+	found := false
+	err := c.tableInfo.walkAsOf(tableName, round, func(block *blockchain_data.Block) bool {
+		if tx := latestInBlock(block, []byte(dataID)); tx != nil {
+			value = AsOfValue{Transaction: *tx, Round: block.Round}
+			found = true
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return value, err
+	}
+	if !found || value.Transaction.IsDelete() {
+		return AsOfValue{}, errors.New("null")
+	}
+	return value, nil
+}
+
+// GetTableAsOf 查询整个表在 round 时的数据, 按照 key 排序
+func (c *Cache) GetTableAsOf(tableName string, round uint64) ([]AsOfValue, error) {
+	values := make(map[string]AsOfValue)
+	err := c.tableInfo.walkAsOf(tableName, round, func(block *blockchain_data.Block) bool {
+		for _, tx := range block.Writes() {
+			if tx.Table != tableName {
+				continue
+			}
+			if _, has := values[tx.Key]; has {
+				continue
+			}
+			latest := latestInBlock(block, tx.DataID)
+			values[tx.Key] = AsOfValue{Transaction: *latest, Round: block.Round}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]AsOfValue, 0, len(values))
+	for _, value := range values {
+		if !value.Transaction.IsDelete() {
+			result = append(result, value)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Transaction.Key < result[j].Transaction.Key
+	})
+	return result, nil
+}
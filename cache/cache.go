@@ -3,10 +3,11 @@ package cache
 import (
 	"alg_bcDB/blockchain/blockchain_data"
 	"alg_bcDB/blockchain/blockchain_table"
+	"alg_bcDB/config"
 	"bytes"
 	"errors"
-	"fmt"
 	"sync"
+	"time"
 )
 
 var LocalCache *Cache
@@ -17,15 +18,17 @@ var LocalCache *Cache
 // 读取数据区块更新 lru3Query。 读取表区块更新 powerTable。 数据区块或者表区块都要更新的是 表相关链 tableHashChain。
 // funcAPI 1. 在程序启动时，初始化
 // funcAPI 2. 在拿到新的区块时，更新
-// funcAPI 3. 对外的查询接口 （1.在 lru 中查找， 2.在表相关链中查找  3.遍历boltDB查找（原则是是不需要的）
+// funcAPI 3. 对外的查询接口 （1.在 lru 中查找， 2.在世界状态中查找  世界状态不可用时(轻节点): 3.在数据索引中查找  4.在表相关链中查找  5.遍历boltDB查找（原则是是不需要的）
 // funcAPI 其他。
-// a. CheckPermission(address, table string) 查看指定地址在表里面的权限
+// a. CheckPermission(address, table string) 查看指定地址在表里面的角色
 
 type Cache struct {
 	sync.Mutex
 
 	lru3Query lru3Query
 	tableInfo tableInfo
+	// tablesReplay 最近一次重新应用得到的表区块链更早的位置的权限表和 schema (见 tablesAt)
+	tablesReplay *tableInfo
 
 	dataChain  *blockchain_data.BlockChain
 	tableChain *blockchain_table.BlockChain
@@ -37,58 +40,115 @@ func (c *Cache) Init(dc *blockchain_data.BlockChain, tc *blockchain_table.BlockC
 
 	c.dataChain = dc
 	c.tableChain = tc
-	c.lru3Query.init(dc)
+	cc := config.LocalConfig.Cache
+	c.lru3Query.init(dc, cc.Policy, cc.TxSize, cc.IndexSize)
 	c.tableInfo.init(dc, tc)
+	c.syncIndexes()
 	LocalCache = c
 }
 
+// GetOneValue 查找数据当前的值, 数据被删除 (最新的交易是墓碑) 时和不存在一样返回 err
 func (c *Cache) GetOneValue(dataID string, tableName string) (blockchain_data.Transaction, error) {
+	tx, err := c.getLatest(dataID, tableName)
+	if err == nil && tx.IsDelete() {
+		return blockchain_data.Transaction{}, errors.New("null")
+	}
+	return tx, err
+}
+
+// getLatest 查找数据最新的交易, 依次在 交易队列, 索引队列, 世界状态 里面查找。
+// 世界状态是数据当前值的唯一来源, 不可用时才依次在 数据索引, 表相关链, boltDB 里面查找,
+// 每一层的命中情况和耗时记录在缓存的统计里面
+func (c *Cache) getLatest(dataID string, tableName string) (blockchain_data.Transaction, error) {
 	// 1.在lru3Query 数据队列 里面查找元素
+	start := time.Now()
 	tx, err := c.lru3Query.getInTX(dataID)
+	txTier.record(start, err)
 	if err == nil {
-		c.lru3Query.listTx.toTail(dataID)
-		fmt.Printf("find in cache  ")
 		return tx, nil
 	}
 	// 2.在lru3Query 索引队列 里面查找元素
+	start = time.Now()
 	tx, err = c.lru3Query.getInIndex(dataID)
+	indexTier.record(start, err)
 	if err == nil { // 找到了具体的数据
-		c.lru3Query.listIndex.toTail(dataID)
-		if c.lru3Query.listIndex.checkDel(dataID) {
-			c.lru3Query.listTx.queueIn(dataID, tx)
-		}
-		//dc.CacheInfo()
-		fmt.Printf("find in index  ")
 		return tx, nil
 	}
-	// 3. 在表的相关链里面查找
+	// 3. 在世界状态里面查找, 世界状态里面没有的数据就是不存在
+	start = time.Now()
+	state, err := c.dataChain.GetState(dataID)
+	if err != blockchain_data.ErrNoState {
+		stateTier.record(start, err)
+		if err != nil {
+			missCounter.Inc(1)
+			return tx, errors.New("null")
+		}
+		c.lru3Query.putIndex(dataID, state.BlockHash, state.TxID)
+		return state.Transaction, nil
+	}
+	// 4. 在数据索引里面查找, 读一次索引和一个区块
+	start = time.Now()
+	itx, loc, err := c.dataChain.GetLatestTransaction(dataID)
+	keyIndexTier.record(start, err)
+	if err == nil {
+		c.lru3Query.putIndex(dataID, loc.BlockHash, itx.TxID)
+		return *itx, nil
+	}
+	// 5. 在表的相关链里面查找 (数据索引不可用时, 比如轻节点)
+	start = time.Now()
 	tx, blockHash, txID, err := c.tableInfo.getInTableHashChain(dataID, tableName)
+	tableChainTier.record(start, err)
 	if err == nil {
-		c.lru3Query.listIndex.queueIn(dataID, blockHash, txID)
-		//dc.CacheInfo()
-		fmt.Printf("find in table hash chain  ")
+		c.lru3Query.putIndex(dataID, blockHash, txID)
 		return tx, nil
 	}
 
-	// 4. 在boltDB 里面查找
+	// 6. 在boltDB 里面查找
+	start = time.Now()
 	tx, blockHash, txID, err = c.getInBoltDb(dataID)
+	boltTier.record(start, err)
 	if err == nil {
-		c.lru3Query.listIndex.queueIn(dataID, blockHash, txID)
-		//dc.CacheInfo()
-		fmt.Printf("find in boltDB  ")
+		c.lru3Query.putIndex(dataID, blockHash, txID)
 		return tx, nil
 	}
 
+	missCounter.Inc(1)
 	return tx, errors.New("null")
 }
 
+// LocateValue 查找数据最新的交易所在的区块, 返回区块的 HASH 和交易的 ID。
+// 交易队列里面没有区块的信息, 从索引队列开始查找。 世界状态不可用时找到的交易可能是墓碑
+func (c *Cache) LocateValue(dataID string, tableName string) (blockHash []byte, txID []byte, err error) {
+	if blockHash, txID, err = c.lru3Query.locateInIndex(dataID); err == nil {
+		return blockHash, txID, nil
+	}
+	if state, err := c.dataChain.GetState(dataID); err == nil {
+		c.lru3Query.putIndex(dataID, state.BlockHash, state.TxID)
+		return state.BlockHash, state.TxID, nil
+	} else if err != blockchain_data.ErrNoState {
+		return nil, nil, errors.New("null")
+	}
+	if tx, loc, err := c.dataChain.GetLatestTransaction(dataID); err == nil {
+		c.lru3Query.putIndex(dataID, loc.BlockHash, tx.TxID)
+		return loc.BlockHash, tx.TxID, nil
+	}
+	if _, blockHash, txID, err = c.tableInfo.getInTableHashChain(dataID, tableName); err == nil {
+		c.lru3Query.putIndex(dataID, blockHash, txID)
+		return blockHash, txID, nil
+	}
+	if _, blockHash, txID, err = c.getInBoltDb(dataID); err == nil {
+		c.lru3Query.putIndex(dataID, blockHash, txID)
+		return blockHash, txID, nil
+	}
+	return nil, nil, errors.New("null")
+}
+
 // 在boltDB里面查找数据, 返回交易或者 err.
 // 查找的最后一种可能，遍历区块链查找数据。
-// 处理一种情况。一个区块里面有对一个数据的多个交易时那么就需要得到这个最新时间的数据
+// 处理一种情况。一个区块里面有对一个数据的多个交易时那么就需要得到区块里面靠后的数据
 func (c *Cache) getInBoltDb(ID string) (blockchain_data.Transaction, []byte, []byte, error) {
 	it := c.dataChain.CreateIterator()
-	// 通过时间戳确定同个区块，同ID的最新数据
-	var lastTimeStamp int64
+	// 同个区块，同ID的最新数据
 	var lastTX *blockchain_data.Transaction
 
 	for {
@@ -99,33 +159,52 @@ func (c *Cache) getInBoltDb(ID string) (blockchain_data.Transaction, []byte, []b
 		}
 
 		// 遍历交易
-		for _, tx := range block.Transactions {
+		for _, tx := range block.Writes() {
 			if bytes.Equal(tx.DataID, []byte(ID)) {
-				fmt.Println("here")
-				if tx.TimeStamp > lastTimeStamp {
-					lastTimeStamp = tx.TimeStamp
-					lastTX = tx
-				}
+				lastTX = tx
 			}
 		}
 		// 在当前区块里面找到了数据，直接返回。
-		if lastTimeStamp > 0 {
+		if lastTX != nil {
 			return *lastTX, block.CurrentBlockHash, lastTX.TxID, nil
 		}
 	}
 }
 
-// GetHistoryValue 查找指定数据的历史记录
+// GetHistoryValue 查找指定数据的历史记录, 包括删除数据的墓碑
 func (c *Cache) GetHistoryValue(dataID string, tableName string) (txs []blockchain_data.Transaction, e error) {
 	return c.tableInfo.getHistoryInTableHashChain(dataID, tableName)
 }
 
+// GetTableData 表里面所有数据当前的值, key -> 交易。 优先读取世界状态, 不可用时通过表的相关链读取
 func (c *Cache) GetTableData(tableName string) map[string]*blockchain_data.Transaction {
-	return c.tableInfo.getTableData(tableName)
+	entries, _, err := c.dataChain.ScanState(tableName, "", "", "", -1)
+	if err != nil {
+		return c.tableInfo.getTableData(tableName)
+	}
+	txs := make(map[string]*blockchain_data.Transaction, len(entries))
+	for _, e := range entries {
+		txs[e.Key] = &e.Transaction
+	}
+	return txs
+}
+
+// RebuildState 从创世区块开始重新生成世界状态, 返回和原来的世界状态的差别。
+// 重建后缓存的两个队列可能和世界状态不一致, 一起清空
+func (c *Cache) RebuildState() (*blockchain_data.StateDiff, error) {
+	diff, err := c.dataChain.RebuildState()
+	if err != nil {
+		return nil, err
+	}
+	if err = c.lru3Query.setPolicy(c.lru3Query.policyName()); err != nil {
+		return nil, err
+	}
+	return diff, nil
 }
 
 // UpdateByDataBlock 在接受新的数据区块时，更新缓存中的 lru3Query 和 tableHashChain
 func (c *Cache) UpdateByDataBlock(block blockchain_data.Block) {
+	// 无效的交易在区块头里面, 无效交易的写入不进入缓存
 	c.lru3Query.updateDataCache(block)
 	c.tableInfo.upDateByData(block)
 }
@@ -133,13 +212,28 @@ func (c *Cache) UpdateByDataBlock(block blockchain_data.Block) {
 // UpdateByTableBlock 在接受新的共享表区块时， 更新缓存中的 powerTable 和 tableHashChain
 func (c *Cache) UpdateByTableBlock(block blockchain_table.Block) {
 	c.tableInfo.upDateByTables(block)
+	for _, tx := range block.Transactions {
+		if tx.Op == blockchain_table.OpIndex {
+			c.setIndexes(tx.Table)
+		}
+	}
 }
 
-// CheckPermission 返回对应地址在指定表的权限
-func (c *Cache) CheckPermission(address, tableName string) (string, error) {
+// CheckPermission 返回对应地址在指定表的角色
+func (c *Cache) CheckPermission(address, tableName string) (blockchain_table.Role, error) {
 	return c.tableInfo.checkPermission(address, tableName)
 }
 
-func (c *Cache) MyTables(address string) (myTables map[string]string, err error) {
+// Permissions 返回指定表的权限表
+func (c *Cache) Permissions(tableName string) (blockchain_table.Permissions, error) {
+	return c.tableInfo.permissions(tableName)
+}
+
+// CheckTableTx 检查表交易的签名者能否修改表 (见 blockchain_table.CheckAuthority)
+func (c *Cache) CheckTableTx(tx *blockchain_table.Transaction) error {
+	return c.tableInfo.checkAuthority(tx)
+}
+
+func (c *Cache) MyTables(address string) (myTables map[string]blockchain_table.Role, err error) {
 	return c.tableInfo.uerTables(address)
 }
@@ -2,204 +2,89 @@ package cache
 
 import (
 	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/cache/policy"
 	"bytes"
 	"errors"
-	"fmt"
-	"github.com/boltdb/bolt"
-	"os"
+	"log"
 	"sync"
 )
 
-type txNode struct {
-	tx        blockchain_data.Transaction
-	pre, next *txNode
-}
+// LRU-3 查询缓存, 有两层:
+// 1. 交易队列 listTx: 数据ID -> 数据最新的交易
+// 2. 索引队列 listIndex: 数据ID -> 交易所在的区块 HASH 和交易 ID, 访问 3 次以后升级到交易队列
+// 两层各自的淘汰由 policy.Policy 决定 (lru, lruk, arc, tinylfu)
 
+// indexNode 索引队列的元素
 type indexNode struct {
 	blockHash []byte
 	txID      []byte
-	count     int
-	dataID    string
-
-	pre, next *indexNode
-}
-
-type lruTx struct {
-	maxSize int
-	curSize int
-
-	txMap map[string]*txNode
-	head  *txNode
-	tail  *txNode
-}
-
-type lruIndex struct {
-	maxSize int
-	curSize int
-
-	indexMap map[string]*indexNode
-	head     *indexNode
-	tail     *indexNode
-}
-
-func (lt *lruTx) init() {
-	lt.maxSize = 120
-	lt.curSize = 0
-	lt.txMap = make(map[string]*txNode)
-	p1 := new(txNode)
-	p2 := new(txNode)
-	lt.head = p1
-	lt.tail = p2
-	p1.next = p2
-	p2.pre = p1
-}
-
-func (li *lruIndex) init() {
-	li.maxSize = 300
-	li.curSize = 0
-	li.indexMap = make(map[string]*indexNode)
-	p1 := new(indexNode)
-	p2 := new(indexNode)
-	li.head = p1
-	li.tail = p2
-	p1.next = p2
-	p2.pre = p1
+	count     int // 访问次数
 }
 
 type lru3Query struct {
 	sync.RWMutex
 
-	listTx    lruTx
-	listIndex lruIndex
+	policy    string
+	listTx    policy.Policy // 数据ID -> *blockchain_data.Transaction
+	listIndex policy.Policy // 数据ID -> *indexNode
 	chain     *blockchain_data.BlockChain
 }
 
-func (lru *lru3Query) init(chain *blockchain_data.BlockChain) {
+func (lru *lru3Query) init(chain *blockchain_data.BlockChain, name string, txSize, indexSize int) {
 	lru.Lock()
 	defer lru.Unlock()
 
-	lru.listTx.init()
-	lru.listIndex.init()
 	lru.chain = chain
-}
-
-// 维护队列之在队列里面插入元素
-// 维护缓存交易队列
-func (lt *lruTx) queueIn(ID string, tx blockchain_data.Transaction) {
-	p := &txNode{
-		tx:   tx,
-		pre:  nil,
-		next: nil,
-	}
-
-	if lt.curSize > lt.maxSize {
-		pg := lt.head.next
-		pg.next.pre = pg.pre
-		pg.pre.next = pg.next
-		delete(lt.txMap, string(pg.tx.DataID))
-		lt.curSize--
+	if err := lru.newTiers(name, txSize, indexSize); err != nil {
+		log.Panic(err)
 	}
-
-	ptag := lt.tail.pre
-	p.next = ptag.next
-	ptag.next.pre = p
-	p.pre = ptag
-	ptag.next = p
-
-	lt.txMap[ID] = p
-	lt.curSize++
-	return
 }
 
-// 维护缓存索引队列
-func (li *lruIndex) queueIn(ID string, blockHash, txID []byte) {
-	p := &indexNode{
-		blockHash: blockHash,
-		txID:      txID,
-		dataID:    ID,
-		count:     1, // 加入队列即为被最近被访问一次
-		pre:       nil,
-		next:      nil,
+// newTiers 使用淘汰策略 name 创建两个队列, 淘汰的元素记录在缓存的统计里面
+func (lru *lru3Query) newTiers(name string, txSize, indexSize int) error {
+	listTx, err := policy.New(name, txSize, func(string, interface{}) { txEvictCounter.Inc(1) })
+	if err != nil {
+		return err
 	}
-	if li.curSize > li.maxSize {
-		pg := li.head.next
-		pg.next.pre = pg.pre
-		pg.pre.next = pg.next
-		delete(li.indexMap, pg.dataID)
-		li.curSize--
+	listIndex, err := policy.New(name, indexSize, func(string, interface{}) { indexEvictCounter.Inc(1) })
+	if err != nil {
+		return err
 	}
-
-	ptag := li.tail.pre
-	p.next = ptag.next
-	ptag.next.pre = p
-	p.pre = ptag
-	ptag.next = p
-
-	li.indexMap[ID] = p
-	li.curSize++
-
-	return
+	lru.policy, lru.listTx, lru.listIndex = name, listTx, listIndex
+	return nil
 }
 
-// 更新指定的元素到交易队列尾部
-func (lt *lruTx) toTail(ID string) {
-
-	p := lt.txMap[ID]
-	if lt.tail.pre == p {
-		return
-	}
-
-	// 断开
-	p.pre.next = p.next
-	p.next = p.pre
-	// 接上
-	ptag := lt.tail.pre
-	p.next = ptag.next
-	ptag.next.pre = p
-	p.pre = ptag
-	ptag.next = p
+// setPolicy 更换淘汰策略, 两个队列清空后重新缓存
+func (lru *lru3Query) setPolicy(name string) error {
+	lru.Lock()
+	defer lru.Unlock()
 
+	return lru.newTiers(name, lru.listTx.Cap(), lru.listIndex.Cap())
 }
 
-// 更新指定的元素到索引队列尾部
-func (li *lruIndex) toTail(ID string) {
-	// 移动一次意味着访问次数加一
-	p := li.indexMap[ID]
-	p.count++
-
-	// 在队尾，不用处理
-	if li.tail.pre == p {
-		return
-	}
-
-	// 断开
-	p.pre.next = p.next
-	p.next = p.pre
-	// 接上
-	ptag := li.tail.pre
-	p.next = ptag.next
-	ptag.next.pre = p
-	p.pre = ptag
-	ptag.next = p
+// setSize 修改两个队列的容量, 缩小时淘汰多出来的元素
+func (lru *lru3Query) setSize(txSize, indexSize int) {
+	lru.Lock()
+	defer lru.Unlock()
 
-	return
+	lru.listTx.Resize(txSize)
+	lru.listIndex.Resize(indexSize)
 }
 
-// checkDel 判断这个节点时候满足次数，如果满足就删除
-// 应为已经是移动到了 tail，所以直接判断tail就可以了。
-func (li *lruIndex) checkDel(ID string) bool {
-	p := li.indexMap[ID]
+// size 两个队列的容量和元素数量
+func (lru *lru3Query) size() (txSize, txLen, indexSize, indexLen int) {
+	lru.RLock()
+	defer lru.RUnlock()
 
-	if p.count >= 3 {
-		p.pre.next = p.next
-		p.next.pre = p.pre
+	return lru.listTx.Cap(), lru.listTx.Len(), lru.listIndex.Cap(), lru.listIndex.Len()
+}
 
-		delete(li.indexMap, ID)
-		li.curSize--
+// policyName 淘汰策略的名字
+func (lru *lru3Query) policyName() string {
+	lru.RLock()
+	defer lru.RUnlock()
 
-		return true
-	}
-	return false
+	return lru.policy
 }
 
 // 在交易队列里面查找，返回交易或者err
@@ -208,8 +93,8 @@ func (lru *lru3Query) getInTX(dataID string) (blockchain_data.Transaction, error
 	lru.Lock()
 	defer lru.Unlock()
 
-	if p, has := lru.listTx.txMap[dataID]; has {
-		return p.tx, nil
+	if v, has := lru.listTx.Get(dataID); has {
+		return *v.(*blockchain_data.Transaction), nil
 	}
 	return blockchain_data.Transaction{}, errors.New("null")
 }
@@ -223,48 +108,72 @@ func (lru *lru3Query) getInIndex(dataID string) (blockchain_data.Transaction, er
 
 	var tx0 blockchain_data.Transaction
 
-	if p, has := lru.listIndex.indexMap[dataID]; has {
-		blockHash := p.blockHash
-		txID := p.txID
+	if v, has := lru.listIndex.Get(dataID); has {
+		p := v.(*indexNode)
 
-		var block blockchain_data.Block
-		lru.chain.Db.View(func(tx *bolt.Tx) error {
-
-			bucket := tx.Bucket([]byte(lru.chain.BlockBucket))
-			if bucket == nil {
-				fmt.Println("BlockBucket is nil")
-				os.Exit(1)
-			}
-			// 在boltDB里面得到区块
-			bytesBlock := bucket.Get(blockHash)
-			block = blockchain_data.Deserialize(bytesBlock)
-
-			for _, tx := range block.Transactions {
-				if bytes.Equal(tx.TxID, txID) {
-					tx0 = *tx
-				}
+		// 在存储里面得到区块, 轻节点会按需获取区块体
+		block, err := lru.chain.GetBlockByHash(p.blockHash)
+		if err != nil {
+			return tx0, err
+		}
+		found := false
+		for _, tx := range block.Writes() {
+			if bytes.Equal(tx.TxID, p.txID) && string(tx.DataID) == dataID {
+				tx0, found = *tx, true
 			}
-			return nil
-		})
+		}
+		// 区块里面没有这个交易 (索引过期), 去掉索引, 不算命中
+		if !found {
+			lru.listIndex.Remove(dataID)
+			return blockchain_data.Transaction{}, errors.New("null")
+		}
+		// 访问次数达到 3 次时升级到交易队列
+		p.count++
+		if p.count >= 3 {
+			lru.listIndex.Remove(dataID)
+			lru.listTx.Put(dataID, &tx0)
+			indexPromoteCounter.Inc(1)
+		}
 		return tx0, nil
 	}
 	return tx0, errors.New("null")
 }
 
+// 在索引队列里面查找数据所在的区块 HASH 和交易 ID
+func (lru *lru3Query) locateInIndex(dataID string) ([]byte, []byte, error) {
+	lru.Lock()
+	defer lru.Unlock()
+
+	if v, has := lru.listIndex.Peek(dataID); has {
+		p := v.(*indexNode)
+		return p.blockHash, p.txID, nil
+	}
+	return nil, nil, errors.New("null")
+}
+
+// putIndex 把数据的位置加入索引队列
+func (lru *lru3Query) putIndex(dataID string, blockHash, txID []byte) {
+	lru.Lock()
+	defer lru.Unlock()
+
+	// 加入队列即为被最近被访问一次
+	lru.listIndex.Put(dataID, &indexNode{blockHash: blockHash, txID: txID, count: 1})
+}
+
 // UpdateDataCache 得到新区块的时候 更新缓存
 func (lru *lru3Query) updateDataCache(block blockchain_data.Block) {
 	lru.Lock()
 	defer lru.Unlock()
 
-	// 因为是遍历了所有的交易，那么得到的信息就是区块里面靠后的。
-	for _, tx := range block.Transactions {
+	// 因为是遍历了所有的交易，那么得到的信息就是区块里面靠后的, 和世界状态的规则一致 (不比较时间戳)。
+	for _, tx := range block.Writes() {
 		dataId := string(tx.DataID)
-		if _, has := lru.listTx.txMap[dataId]; has {
-			lru.listTx.txMap[dataId].tx = *tx
+		if v, has := lru.listTx.Peek(dataId); has {
+			*v.(*blockchain_data.Transaction) = *tx
 		}
-		if _, has := lru.listIndex.indexMap[dataId]; has {
-			lru.listIndex.indexMap[dataId].blockHash = block.CurrentBlockHash
-			lru.listIndex.indexMap[dataId].txID = tx.TxID
+		if v, has := lru.listIndex.Peek(dataId); has {
+			v.(*indexNode).blockHash = block.CurrentBlockHash
+			v.(*indexNode).txID = tx.TxID
 		}
 	}
 }
@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/blockchain/blockchain_table"
+	"alg_bcDB/blockchain/blockstore"
+	"alg_bcDB/util"
+	"testing"
+)
+
+// newTestCache 使用内存存储的两条链创建缓存, 不会生成 .db 文件
+// 缓存里面有一个共享表 t
+func newTestCache() (*Cache, *blockchain_data.BlockChain) {
+	dc := new(blockchain_data.BlockChain)
+	dc.InitWithStore(blockstore.NewMemory())
+	tc := new(blockchain_table.BlockChain)
+	tc.InitWithStore(blockstore.NewMemory())
+	c := new(Cache)
+	c.Init(dc, tc)
+	addTable(c, "t", "owner4")
+	return c, dc
+}
+
+// addDataBlock 在链尾添加数据区块并更新缓存
+func addDataBlock(c *Cache, dc *blockchain_data.BlockChain, round uint64, txs []*blockchain_data.Transaction, invalid []blockchain_data.InvalidTx) blockchain_data.Block {
+	block := blockchain_data.NewBlock()
+	block.InitBlock(txs, dc.TailHash, round)
+	block.Invalid = invalid
+	block.SetBlockHash()
+	dc.AddBlockToChain(block)
+	c.UpdateByDataBlock(block)
+	return block
+}
+
+func write(txID, key, value string) *blockchain_data.Transaction {
+	return &blockchain_data.Transaction{TxID: []byte(txID), DataID: []byte("t-QAQ-" + key), Table: "t", Key: key, Value: value}
+}
+
+func TestCacheGetOneValue(t *testing.T) {
+	c, dc := newTestCache()
+	addDataBlock(c, dc, 1, []*blockchain_data.Transaction{write("t1", "a", "v1"), write("t2", "b", "v1")}, nil)
+
+	// 依次命中 世界状态, 索引队列, 升级以后的交易队列
+	for i := 0; i < 5; i++ {
+		tx, err := c.GetOneValue("t-QAQ-a", "t")
+		if err != nil || tx.Value != "v1" {
+			t.Fatalf("第 %d 次查找: %v %v", i, tx.Value, err)
+		}
+	}
+
+	// 新的区块更新缓存里面的值, 无效交易的写入不可见
+	addDataBlock(c, dc, 2, []*blockchain_data.Transaction{write("t3", "a", "v2"), write("t4", "a", "v3")},
+		[]blockchain_data.InvalidTx{{Index: 1, Reason: "读取的版本过期"}})
+	for i := 0; i < 2; i++ {
+		tx, err := c.GetOneValue("t-QAQ-a", "t")
+		if err != nil || tx.Value != "v2" {
+			t.Fatalf("更新以后第 %d 次查找: %v %v", i, tx.Value, err)
+		}
+	}
+	if tx, err := c.GetOneValue("t-QAQ-b", "t"); err != nil || tx.Value != "v1" {
+		t.Fatalf("没有修改的数据: %v %v", tx.Value, err)
+	}
+	if _, err := c.GetOneValue("t-QAQ-c", "t"); err == nil {
+		t.Fatal("不存在的数据没有返回 err")
+	}
+}
+
+func TestGetInIndexStale(t *testing.T) {
+	c, dc := newTestCache()
+	block := addDataBlock(c, dc, 1, []*blockchain_data.Transaction{write("t1", "a", "v1")}, nil)
+
+	// 索引指向的区块里面没有这个交易
+	c.lru3Query.putIndex("t-QAQ-a", block.CurrentBlockHash, []byte("missing"))
+	if tx, err := c.lru3Query.getInIndex("t-QAQ-a"); err == nil {
+		t.Fatalf("过期的索引命中: %+v", tx)
+	}
+	if _, has := c.lru3Query.listIndex.Peek("t-QAQ-a"); has {
+		t.Fatal("过期的索引没有去掉")
+	}
+	if tx, err := c.GetOneValue("t-QAQ-a", "t"); err != nil || tx.Value != "v1" {
+		t.Fatalf("GetOneValue = %v %v", tx.Value, err)
+	}
+}
+
+// addTable 在表区块链的链尾添加创建表的区块, grants 为 地址+角色的数字
+func addTable(c *Cache, name string, grants ...string) {
+	tc := c.tableChain
+	block := blockchain_table.NewBlock()
+	block.InitBlock([]*blockchain_table.Transaction{{TxID: []byte("table " + name), Table: name, PermissionTable: grants}}, tc.TailHash, tc.LastID)
+	block.SetBlockHash()
+	tc.AddBlockToChain(block)
+	c.UpdateByTableBlock(block)
+}
+
+// signedWrite 公钥为 user 的写入, 权限按照公钥的地址检查 (checkTx 不校验签名)
+func signedWrite(user, txID, table, key, value string) *blockchain_data.Transaction {
+	tx := &blockchain_data.Transaction{TxID: []byte(txID), Table: table, Key: key, Value: value, PublicKey: []byte(user)}
+	tx.SetDataID()
+	return tx
+}
+
+func TestCheckWriteDataID(t *testing.T) {
+	c, _ := newTestCache()
+	addTable(c, "a", util.CalculateAddress([]byte("alice"))+"2")
+	addTable(c, "b", "owner4")
+
+	if err := c.CheckWrite(signedWrite("alice", "t1", "a", "k", "v")); err != nil {
+		t.Fatal(err)
+	}
+	// 用表 a 的权限写入表 b 的数据
+	tx := signedWrite("alice", "t2", "a", "k", "v")
+	tx.DataID = []byte("b-QAQ-k")
+	if err := c.CheckWrite(tx); err == nil {
+		t.Fatal("数据ID 和表不一致的交易通过了检查")
+	}
+}
+
+func TestCheckBlockWritesTablePosition(t *testing.T) {
+	c, dc := newTestCache()
+	alice := util.CalculateAddress([]byte("alice"))
+	addTable(c, "s", alice+"2", "owner4")
+	granted, grantedHash := c.tableInfo.position()
+
+	// 移除 alice 以后, 按照移除之前的位置打包的区块仍然有效
+	tc := c.tableChain
+	revoke := blockchain_table.NewBlock()
+	revoke.InitBlock([]*blockchain_table.Transaction{{TxID: []byte("revoke"), Table: "s", Op: blockchain_table.OpRevoke, PermissionTable: []string{alice}}}, tc.TailHash, tc.LastID)
+	tc.AddBlockToChain(revoke)
+	c.UpdateByTableBlock(revoke)
+
+	newBlock := func(tableID uint64, tableHash []byte) *blockchain_data.Block {
+		block := blockchain_data.NewBlock()
+		block.InitBlock([]*blockchain_data.Transaction{signedWrite("alice", "t1", "s", "k", "v")}, dc.TailHash, dc.LastID)
+		block.TableID, block.TableHash = tableID, tableHash
+		block.SetBlockHash()
+		return &block
+	}
+	if err := c.CheckBlockWrites(newBlock(granted, grantedHash)); err != nil {
+		t.Fatalf("按照打包时的位置校验失败; %v", err)
+	}
+	if err := c.CheckBlockWrites(newBlock(uint64(revoke.ID), revoke.CurrentBlockHash)); err == nil {
+		t.Fatal("移除以后的位置没有拒绝 alice 的写入")
+	}
+	if err := c.CheckBlockWrites(newBlock(granted, revoke.CurrentBlockHash)); err == nil {
+		t.Fatal("区块 HASH 和位置不一致时没有拒绝")
+	}
+	if err := c.CheckBlockWrites(newBlock(uint64(revoke.ID)+1, []byte("future"))); err == nil {
+		t.Fatal("本地还没有的位置没有拒绝")
+	}
+
+	// 位置不能早于前面的区块
+	empty := blockchain_data.NewBlock()
+	empty.InitBlock(nil, dc.TailHash, dc.LastID)
+	empty.TableID, empty.TableHash = uint64(revoke.ID), revoke.CurrentBlockHash
+	empty.SetBlockHash()
+	dc.AddBlockToChain(empty)
+	if err := c.CheckBlockWrites(newBlock(granted, grantedHash)); err == nil {
+		t.Fatal("位置早于前面的区块时没有拒绝")
+	}
+}
+
+func TestCheckWriteOwner(t *testing.T) {
+	c, dc := newTestCache()
+	addTable(c, "w", util.CalculateAddress([]byte("alice"))+"2", util.CalculateAddress([]byte("bob"))+"2",
+		util.CalculateAddress([]byte("carol"))+"3")
+
+	// write 可以创建新的 key, 和修改自己的 key
+	if err := c.CheckWrite(signedWrite("alice", "t1", "w", "k", "v1")); err != nil {
+		t.Fatalf("创建新的 key 失败; %v", err)
+	}
+	addDataBlock(c, dc, 1, []*blockchain_data.Transaction{signedWrite("alice", "t1", "w", "k", "v1")}, nil)
+	if err := c.CheckWrite(signedWrite("alice", "t2", "w", "k", "v2")); err != nil {
+		t.Fatalf("修改自己的 key 失败; %v", err)
+	}
+
+	// write 不能修改其他用户的 key, overwrite 可以
+	if err := c.CheckWrite(signedWrite("bob", "t3", "w", "k", "v3")); err == nil {
+		t.Fatal("write 权限修改了其他用户的 key")
+	}
+	if err := c.CheckWrite(signedWrite("carol", "t4", "w", "k", "v4")); err != nil {
+		t.Fatalf("overwrite 权限修改其他用户的 key 失败; %v", err)
+	}
+
+	// key 的拥有者是最新的写入者
+	addDataBlock(c, dc, 2, []*blockchain_data.Transaction{signedWrite("carol", "t4", "w", "k", "v4")}, nil)
+	if err := c.CheckWrite(signedWrite("alice", "t5", "w", "k", "v5")); err == nil {
+		t.Fatal("被 overwrite 以后原来的用户仍然可以修改")
+	}
+}
+
+func TestCheckBlockWritesSameKey(t *testing.T) {
+	c, dc := newTestCache()
+	addTable(c, "w", util.CalculateAddress([]byte("alice"))+"2", util.CalculateAddress([]byte("bob"))+"2")
+	newBlock := func(txs ...*blockchain_data.Transaction) *blockchain_data.Block {
+		block := blockchain_data.NewBlock()
+		block.InitBlock(txs, dc.TailHash, dc.LastID)
+		return &block
+	}
+
+	// 同一个区块里面前面的交易创建的 key 属于前面的写入者
+	if err := c.CheckBlockWrites(newBlock(signedWrite("alice", "t1", "w", "k", "v1"), signedWrite("alice", "t2", "w", "k", "v2"))); err != nil {
+		t.Fatalf("同一个用户两次创建 key 失败; %v", err)
+	}
+	if err := c.CheckBlockWrites(newBlock(signedWrite("alice", "t1", "w", "k", "v1"), signedWrite("bob", "t2", "w", "k", "v2"))); err == nil {
+		t.Fatal("两个用户在同一个区块里面创建了同一个 key")
+	}
+	valid, rejected, _, _ := c.FilterWrites([]*blockchain_data.Transaction{
+		signedWrite("alice", "t1", "w", "k", "v1"), signedWrite("bob", "t2", "w", "k", "v2")})
+	if len(valid) != 1 || len(rejected) != 1 || string(rejected[0].Tx.TxID) != "t2" {
+		t.Fatalf("FilterWrites = %d 个有效, %d 个去掉", len(valid), len(rejected))
+	}
+}
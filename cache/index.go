@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/util"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// 二级索引: 表声明的索引字段来自表区块链, 索引的内容在数据区块链的存储里面 (见 blockchain_data.SetIndexes)。
+// 没有世界状态时 (轻节点) 遍历表的数据查找
+
+// syncIndexes 启动时把表区块链上面声明的索引同步到数据区块链的存储, 去掉已经不存在的索引
+func (c *Cache) syncIndexes() {
+	tables := make(map[string]bool)
+	for table := range c.dataChain.IndexedTables() {
+		tables[table] = true
+	}
+	for _, table := range c.tableInfo.indexedTables() {
+		tables[table] = true
+	}
+	for table := range tables {
+		c.setIndexes(table)
+	}
+}
+
+// setIndexes 用表当前声明的索引字段更新数据区块链的索引
+func (c *Cache) setIndexes(table string) {
+	if err := c.dataChain.SetIndexes(table, c.tableInfo.indexFields(table)); err != nil {
+		log.Panic(err)
+	}
+}
+
+// Indexes 表声明的索引字段
+func (c *Cache) Indexes(table string) []string {
+	return c.tableInfo.indexFields(table)
+}
+
+// RebuildIndexes 由世界状态重新生成所有的二级索引, 返回索引项的个数
+func (c *Cache) RebuildIndexes() (int, error) {
+	return c.dataChain.RebuildIndexes()
+}
+
+// Find 查找表里面索引字段 field 的值等于 value 的数据, 按照 key 的顺序, limit 小于 0 时返回所有的数据。
+// 字符串字段的值是字符串的内容, 其他字段的值是规范的 JSON (比如 3, true)
+func (c *Cache) Find(table, field, value string, limit int) ([]blockchain_data.Transaction, error) {
+	declared := false
+	for _, f := range c.tableInfo.indexFields(table) {
+		declared = declared || f == field
+	}
+	if !declared {
+		return nil, fmt.Errorf("表 %s 的字段 %s 没有索引", table, field)
+	}
+	states, err := c.dataChain.FindByIndex(table, field, value, limit)
+	if err == nil {
+		txs := make([]blockchain_data.Transaction, 0, len(states))
+		for _, e := range states {
+			txs = append(txs, e.Transaction)
+		}
+		return txs, nil
+	}
+	if err == blockchain_data.ErrNoIndex {
+		return nil, errors.New("索引还没有生成, 可以使用 rebuildindex 重建")
+	}
+	if err != blockchain_data.ErrNoState {
+		return nil, err
+	}
+	// 没有世界状态时遍历表的数据
+	var txs []blockchain_data.Transaction
+	for _, tx := range c.tableInfo.getTableData(table) {
+		if tx.Type != blockchain_data.TypeJSON {
+			continue
+		}
+		if v, ok := util.IndexValue([]byte(tx.Value), field); ok && v == value {
+			txs = append(txs, *tx)
+		}
+	}
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Key < txs[j].Key })
+	if limit >= 0 && len(txs) > limit {
+		txs = txs[:limit]
+	}
+	return txs, nil
+}
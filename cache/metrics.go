@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"alg_bcDB/cache/policy"
+	"errors"
+	"github.com/rcrowley/go-metrics"
+	"time"
+)
+
+// 缓存的统计信息, 注册在 metrics.DefaultRegistry 里面。
+// 每一层查询(交易队列, 索引队列, 数据索引, 表相关链, 遍历boltDB)记录命中, 没有命中的次数和耗时(微秒),
+// 两个队列记录淘汰的次数, 索引队列还记录升级到交易队列的次数
+
+// tierMetrics 一层查询的统计
+type tierMetrics struct {
+	name    string
+	hit     metrics.Counter
+	miss    metrics.Counter
+	latency metrics.Histogram
+}
+
+func newTierMetrics(name string) *tierMetrics {
+	return &tierMetrics{
+		name:    name,
+		hit:     metrics.NewRegisteredCounter("cache/"+name+"/hit", nil),
+		miss:    metrics.NewRegisteredCounter("cache/"+name+"/miss", nil),
+		latency: metrics.NewRegisteredHistogram("cache/"+name+"/latency", nil, metrics.NewExpDecaySample(1028, 0.015)),
+	}
+}
+
+// record 记录一次查询, err 为 nil 表示命中
+func (t *tierMetrics) record(start time.Time, err error) {
+	if err == nil {
+		t.hit.Inc(1)
+	} else {
+		t.miss.Inc(1)
+	}
+	t.latency.Update(time.Since(start).Microseconds())
+}
+
+var (
+	txTier         = newTierMetrics("tx")
+	indexTier      = newTierMetrics("index")
+	stateTier      = newTierMetrics("state")
+	keyIndexTier   = newTierMetrics("keyindex")
+	tableChainTier = newTierMetrics("tablechain")
+	boltTier       = newTierMetrics("boltdb")
+	tiers          = []*tierMetrics{txTier, indexTier, stateTier, keyIndexTier, tableChainTier, boltTier}
+
+	missCounter         = metrics.NewRegisteredCounter("cache/miss", nil)
+	txEvictCounter      = metrics.NewRegisteredCounter("cache/tx/evict", nil)
+	indexEvictCounter   = metrics.NewRegisteredCounter("cache/index/evict", nil)
+	indexPromoteCounter = metrics.NewRegisteredCounter("cache/index/promote", nil)
+)
+
+// TierStats 一层查询的统计, 耗时的单位为微秒
+type TierStats struct {
+	Name    string  `json:"name"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+	Mean    float64 `json:"latency_mean_us"`
+	P50     float64 `json:"latency_p50_us"`
+	P99     float64 `json:"latency_p99_us"`
+	Max     int64   `json:"latency_max_us"`
+}
+
+// Stats 缓存的统计
+type Stats struct {
+	Policy          string      `json:"policy"` // 淘汰策略
+	Tiers           []TierStats `json:"tiers"`
+	Misses          int64       `json:"misses"` // 所有层都没有找到的次数
+	TxSize          int         `json:"tx_size"`
+	TxLen           int         `json:"tx_len"`
+	TxEvictions     int64       `json:"tx_evictions"`
+	IndexSize       int         `json:"index_size"`
+	IndexLen        int         `json:"index_len"`
+	IndexEvictions  int64       `json:"index_evictions"`
+	IndexPromotions int64       `json:"index_promotions"` // 索引队列升级到交易队列的次数
+}
+
+// Stats 得到缓存的统计
+func (c *Cache) Stats() Stats {
+	var stats Stats
+	for _, t := range tiers {
+		latency := t.latency.Snapshot()
+		ps := latency.Percentiles([]float64{0.5, 0.99})
+		ts := TierStats{
+			Name:   t.name,
+			Hits:   t.hit.Count(),
+			Misses: t.miss.Count(),
+			Mean:   latency.Mean(),
+			P50:    ps[0],
+			P99:    ps[1],
+			Max:    latency.Max(),
+		}
+		if total := ts.Hits + ts.Misses; total > 0 {
+			ts.HitRate = float64(ts.Hits) / float64(total)
+		}
+		stats.Tiers = append(stats.Tiers, ts)
+	}
+	stats.Policy = c.lru3Query.policyName()
+	stats.Misses = missCounter.Count()
+	stats.TxSize, stats.TxLen, stats.IndexSize, stats.IndexLen = c.lru3Query.size()
+	stats.TxEvictions = txEvictCounter.Count()
+	stats.IndexEvictions = indexEvictCounter.Count()
+	stats.IndexPromotions = indexPromoteCounter.Count()
+	return stats
+}
+
+// SetSize 运行时修改交易队列和索引队列的容量, 缩小时淘汰最久没有访问的元素
+func (c *Cache) SetSize(txSize, indexSize int) error {
+	if txSize <= 0 || indexSize <= 0 {
+		return errors.New("缓存的容量必须大于 0")
+	}
+	c.lru3Query.setSize(txSize, indexSize)
+	return nil
+}
+
+// CheckPolicy 检查淘汰策略的名字, 启动时用来检查配置
+func CheckPolicy(name string) error {
+	_, err := policy.New(name, 1, nil)
+	return err
+}
+
+// SetPolicy 运行时更换淘汰策略, 两个队列会清空
+func (c *Cache) SetPolicy(name string) error {
+	return c.lru3Query.setPolicy(name)
+}
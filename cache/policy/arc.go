@@ -0,0 +1,179 @@
+package policy
+
+import "container/list"
+
+// ARC 自适应替换缓存 (Megiddo & Modha)。
+// T1 保存只访问过一次的元素, T2 保存访问过多次的元素, B1, B2 分别保存从 T1, T2 淘汰的 key。
+// 在 B1 命中说明 T1 太小, 增大 T1 的目标大小 p; 在 B2 命中说明 T2 太小, 减小 p
+type ARC struct {
+	capacity int
+	p        int // T1 的目标大小
+	t1, t2   *list.List
+	b1, b2   *list.List
+	items    map[string]*list.Element // 所有 key (包括 B1, B2) -> 链表元素
+	where    map[string]*list.List    // key 所在的链表
+	onEvict  EvictFunc
+}
+
+// NewARC 创建 ARC 策略
+func NewARC(capacity int, onEvict EvictFunc) *ARC {
+	return &ARC{
+		capacity: capacity,
+		t1:       list.New(),
+		t2:       list.New(),
+		b1:       list.New(),
+		b2:       list.New(),
+		items:    make(map[string]*list.Element),
+		where:    make(map[string]*list.List),
+		onEvict:  onEvict,
+	}
+}
+
+func (c *ARC) Name() string { return "arc" }
+
+// move 把元素移动到链表 to 的头部 (最近访问)
+func (c *ARC) move(key string, to *list.List) {
+	e := c.items[key]
+	ent := c.where[key].Remove(e).(*entry)
+	c.items[key] = to.PushFront(ent)
+	c.where[key] = to
+}
+
+// drop 删除链表尾部的 key
+func (c *ARC) drop(l *list.List) {
+	e := l.Back()
+	ent := l.Remove(e).(*entry)
+	delete(c.items, ent.key)
+	delete(c.where, ent.key)
+}
+
+func (c *ARC) resident(key string) bool {
+	l := c.where[key]
+	return l == c.t1 || l == c.t2
+}
+
+func (c *ARC) Get(key string) (interface{}, bool) {
+	if !c.resident(key) {
+		return nil, false
+	}
+	value := c.items[key].Value.(*entry).value
+	c.move(key, c.t2)
+	return value, true
+}
+
+func (c *ARC) Peek(key string) (interface{}, bool) {
+	if !c.resident(key) {
+		return nil, false
+	}
+	return c.items[key].Value.(*entry).value, true
+}
+
+func (c *ARC) Put(key string, value interface{}) {
+	switch c.where[key] {
+	case c.t1, c.t2:
+		c.items[key].Value.(*entry).value = value
+		c.move(key, c.t2)
+		return
+	case c.b1:
+		c.p = minInt(c.capacity, c.p+maxInt(c.b2.Len()/c.b1.Len(), 1))
+		c.replace(false)
+		c.items[key].Value.(*entry).value = value
+		c.move(key, c.t2)
+		return
+	case c.b2:
+		c.p = maxInt(0, c.p-maxInt(c.b1.Len()/c.b2.Len(), 1))
+		c.replace(true)
+		c.items[key].Value.(*entry).value = value
+		c.move(key, c.t2)
+		return
+	}
+	// 新的 key
+	if c.t1.Len()+c.b1.Len() >= c.capacity {
+		if c.t1.Len() < c.capacity {
+			c.drop(c.b1)
+			c.replace(false)
+		} else {
+			c.evictBack(c.t1, nil)
+		}
+	} else if total := c.t1.Len() + c.t2.Len() + c.b1.Len() + c.b2.Len(); total >= c.capacity {
+		if total >= 2*c.capacity {
+			c.drop(c.b2)
+		}
+		c.replace(false)
+	}
+	c.items[key] = c.t1.PushFront(&entry{key, value})
+	c.where[key] = c.t1
+}
+
+// replace 缓存满了的时候淘汰 T1 或者 T2 尾部的元素, 移动到对应的 B1 或者 B2
+func (c *ARC) replace(inB2 bool) {
+	if c.t1.Len()+c.t2.Len() < c.capacity {
+		return
+	}
+	if c.t1.Len() > 0 && (c.t1.Len() > c.p || (inB2 && c.t1.Len() == c.p)) {
+		c.evictBack(c.t1, c.b1)
+	} else if c.t2.Len() > 0 {
+		c.evictBack(c.t2, c.b2)
+	} else {
+		c.evictBack(c.t1, c.b1)
+	}
+}
+
+// evictBack 淘汰链表尾部的元素, ghost 不为 nil 时 key 移动到 ghost
+func (c *ARC) evictBack(l, ghost *list.List) {
+	ent := l.Back().Value.(*entry)
+	value := ent.value
+	if ghost != nil {
+		c.move(ent.key, ghost)
+		ent.value = nil
+	} else {
+		c.drop(l)
+	}
+	if c.onEvict != nil {
+		c.onEvict(ent.key, value)
+	}
+}
+
+func (c *ARC) Remove(key string) {
+	if l, has := c.where[key]; has {
+		l.Remove(c.items[key])
+		delete(c.items, key)
+		delete(c.where, key)
+	}
+}
+
+func (c *ARC) Len() int { return c.t1.Len() + c.t2.Len() }
+
+func (c *ARC) Cap() int { return c.capacity }
+
+func (c *ARC) Resize(capacity int) {
+	c.capacity = capacity
+	c.p = minInt(c.p, capacity)
+	for c.t1.Len()+c.t2.Len() > capacity {
+		if c.t1.Len() > c.p || c.t2.Len() == 0 {
+			c.evictBack(c.t1, c.b1)
+		} else {
+			c.evictBack(c.t2, c.b2)
+		}
+	}
+	for c.t1.Len()+c.b1.Len() > capacity && c.b1.Len() > 0 {
+		c.drop(c.b1)
+	}
+	for c.t1.Len()+c.t2.Len()+c.b1.Len()+c.b2.Len() > 2*capacity && c.b2.Len() > 0 {
+		c.drop(c.b2)
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
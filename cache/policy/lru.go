@@ -0,0 +1,77 @@
+package policy
+
+import "container/list"
+
+// LRU 淘汰最久没有访问的元素
+type LRU struct {
+	capacity int
+	ll       *list.List // 链表头部为最近访问的元素
+	items    map[string]*list.Element
+	onEvict  EvictFunc
+}
+
+// NewLRU 创建 LRU 策略
+func NewLRU(capacity int, onEvict EvictFunc) *LRU {
+	return &LRU{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		onEvict:  onEvict,
+	}
+}
+
+func (c *LRU) Name() string { return "lru" }
+
+func (c *LRU) Get(key string) (interface{}, bool) {
+	if e, has := c.items[key]; has {
+		c.ll.MoveToFront(e)
+		return e.Value.(*entry).value, true
+	}
+	return nil, false
+}
+
+func (c *LRU) Peek(key string) (interface{}, bool) {
+	if e, has := c.items[key]; has {
+		return e.Value.(*entry).value, true
+	}
+	return nil, false
+}
+
+func (c *LRU) Put(key string, value interface{}) {
+	if e, has := c.items[key]; has {
+		e.Value.(*entry).value = value
+		c.ll.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&entry{key, value})
+	c.evict()
+}
+
+func (c *LRU) Remove(key string) {
+	if e, has := c.items[key]; has {
+		c.ll.Remove(e)
+		delete(c.items, key)
+	}
+}
+
+func (c *LRU) Len() int { return c.ll.Len() }
+
+func (c *LRU) Cap() int { return c.capacity }
+
+func (c *LRU) Resize(capacity int) {
+	c.capacity = capacity
+	c.evict()
+}
+
+// evict 淘汰链表尾部的元素, 直到不超过容量
+func (c *LRU) evict() {
+	for c.ll.Len() > c.capacity {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		ent := e.Value.(*entry)
+		delete(c.items, ent.key)
+		if c.onEvict != nil {
+			c.onEvict(ent.key, ent.value)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package policy
+
+import (
+	"container/heap"
+	"container/list"
+)
+
+// LRUK LRU-K: 淘汰倒数第 K 次访问最早的元素。
+// 访问次数不到 K 次的元素倒数第 K 次访问视为无穷远, 最先淘汰, 它们之间按照最近一次访问淘汰。
+// 被淘汰的元素的访问历史会保留一段时间 (最多 capacity 个), 再次加入时继续使用
+type LRUK struct {
+	capacity int
+	k        int
+	clock    int64
+	items    map[string]*lrukEntry
+	heap     lrukHeap
+	onEvict  EvictFunc
+
+	ghosts    map[string]*list.Element // 被淘汰的元素的访问历史
+	ghostList *list.List               // 链表头部为最近淘汰的
+}
+
+type lrukEntry struct {
+	key     string
+	value   interface{}
+	history []int64 // 最近 K 次访问的时间, 最近的在前面
+	index   int     // 在堆里面的下标
+}
+
+type lrukGhost struct {
+	key     string
+	history []int64
+}
+
+// NewLRUK 创建 LRU-K 策略
+func NewLRUK(capacity, k int, onEvict EvictFunc) *LRUK {
+	if k < 1 {
+		k = 1
+	}
+	return &LRUK{
+		capacity:  capacity,
+		k:         k,
+		items:     make(map[string]*lrukEntry),
+		onEvict:   onEvict,
+		ghosts:    make(map[string]*list.Element),
+		ghostList: list.New(),
+	}
+}
+
+func (c *LRUK) Name() string { return "lruk" }
+
+// access 记录一次访问。 history 的容量固定为 K, 堆用 len == cap 判断访问次数是否达到 K 次
+func (c *LRUK) access(history []int64) []int64 {
+	c.clock++
+	if history == nil {
+		history = make([]int64, 0, c.k)
+	}
+	if len(history) < c.k {
+		history = append(history, 0)
+	}
+	copy(history[1:], history[:len(history)-1])
+	history[0] = c.clock
+	return history
+}
+
+func (c *LRUK) Get(key string) (interface{}, bool) {
+	if e, has := c.items[key]; has {
+		e.history = c.access(e.history)
+		heap.Fix(&c.heap, e.index)
+		return e.value, true
+	}
+	return nil, false
+}
+
+func (c *LRUK) Peek(key string) (interface{}, bool) {
+	if e, has := c.items[key]; has {
+		return e.value, true
+	}
+	return nil, false
+}
+
+func (c *LRUK) Put(key string, value interface{}) {
+	if e, has := c.items[key]; has {
+		e.value = value
+		e.history = c.access(e.history)
+		heap.Fix(&c.heap, e.index)
+		return
+	}
+	var history []int64
+	if g, has := c.ghosts[key]; has {
+		history = g.Value.(*lrukGhost).history
+		c.ghostList.Remove(g)
+		delete(c.ghosts, key)
+	}
+	e := &lrukEntry{key: key, value: value, history: c.access(history)}
+	c.items[key] = e
+	heap.Push(&c.heap, e)
+	c.evict()
+}
+
+func (c *LRUK) Remove(key string) {
+	if e, has := c.items[key]; has {
+		heap.Remove(&c.heap, e.index)
+		delete(c.items, key)
+	}
+}
+
+func (c *LRUK) Len() int { return len(c.items) }
+
+func (c *LRUK) Cap() int { return c.capacity }
+
+func (c *LRUK) Resize(capacity int) {
+	c.capacity = capacity
+	c.evict()
+	c.trimGhosts()
+}
+
+// evict 淘汰堆顶的元素, 直到不超过容量
+func (c *LRUK) evict() {
+	for len(c.items) > c.capacity {
+		e := heap.Pop(&c.heap).(*lrukEntry)
+		delete(c.items, e.key)
+		c.ghosts[e.key] = c.ghostList.PushFront(&lrukGhost{e.key, e.history})
+		c.trimGhosts()
+		if c.onEvict != nil {
+			c.onEvict(e.key, e.value)
+		}
+	}
+}
+
+// trimGhosts 访问历史最多保留 capacity 个
+func (c *LRUK) trimGhosts() {
+	for c.ghostList.Len() > c.capacity {
+		g := c.ghostList.Back()
+		c.ghostList.Remove(g)
+		delete(c.ghosts, g.Value.(*lrukGhost).key)
+	}
+}
+
+// lrukHeap 按照 (访问次数是否达到 K 次, 倒数第 K 次访问或者最近一次访问的时间) 排序的最小堆
+type lrukHeap []*lrukEntry
+
+func (h lrukHeap) Len() int { return len(h) }
+
+func (h lrukHeap) Less(i, j int) bool {
+	fi, fj := len(h[i].history) == cap(h[i].history), len(h[j].history) == cap(h[j].history)
+	if fi != fj {
+		return !fi
+	}
+	if fi {
+		return h[i].history[len(h[i].history)-1] < h[j].history[len(h[j].history)-1]
+	}
+	return h[i].history[0] < h[j].history[0]
+}
+
+func (h lrukHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lrukHeap) Push(x interface{}) {
+	e := x.(*lrukEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lrukHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
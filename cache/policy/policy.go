@@ -0,0 +1,71 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+)
+
+// 缓存的淘汰策略。
+// 查询缓存的交易队列和索引队列都通过 Policy 保存元素, 容量满了以后由策略决定淘汰哪一个。
+// 目前有四种实现: lru, lruk (LRU-2), arc, tinylfu (W-TinyLFU)。
+// Policy 不是并发安全的, 由调用者加锁。 访问一个元素时先 Get, 没有命中再 Put,
+// 部分策略(LRU-K, W-TinyLFU)会在 Get 里面记录没有命中的访问
+
+// Policy 淘汰策略
+type Policy interface {
+	// Name 策略的名字
+	Name() string
+	// Get 查找元素, 命中时更新访问信息
+	Get(key string) (interface{}, bool)
+	// Peek 查找元素, 不更新访问信息
+	Peek(key string) (interface{}, bool)
+	// Put 加入新的元素或者更新已有的元素, 超过容量时淘汰元素
+	Put(key string, value interface{})
+	// Remove 移除元素, 不算作淘汰
+	Remove(key string)
+	// Len 元素的数量
+	Len() int
+	// Cap 容量
+	Cap() int
+	// Resize 修改容量, 缩小时淘汰多出来的元素
+	Resize(capacity int)
+}
+
+// EvictFunc 元素被淘汰时调用
+type EvictFunc func(key string, value interface{})
+
+// constructors 策略的名字 -> 构造函数
+var constructors = map[string]func(capacity int, onEvict EvictFunc) Policy{
+	"lru":     func(capacity int, onEvict EvictFunc) Policy { return NewLRU(capacity, onEvict) },
+	"lruk":    func(capacity int, onEvict EvictFunc) Policy { return NewLRUK(capacity, 2, onEvict) },
+	"arc":     func(capacity int, onEvict EvictFunc) Policy { return NewARC(capacity, onEvict) },
+	"tinylfu": func(capacity int, onEvict EvictFunc) Policy { return NewTinyLFU(capacity, onEvict) },
+}
+
+// Names 所有策略的名字
+func Names() []string {
+	names := make([]string, 0, len(constructors))
+	for name := range constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New 通过名字创建策略, onEvict 可以为 nil
+func New(name string, capacity int, onEvict EvictFunc) (Policy, error) {
+	constructor, has := constructors[name]
+	if !has {
+		return nil, fmt.Errorf("不存在淘汰策略 %s, 可以使用 %v", name, Names())
+	}
+	if capacity <= 0 {
+		return nil, fmt.Errorf("缓存的容量必须大于 0")
+	}
+	return constructor(capacity, onEvict), nil
+}
+
+// entry 链表里面的元素
+type entry struct {
+	key   string
+	value interface{}
+}
@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// keys 生成 prefix0 ... prefix(n-1)
+func keys(prefix string, n int) []string {
+	ks := make([]string, n)
+	for i := range ks {
+		ks[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return ks
+}
+
+// ops 操作序列: "put a b" 依次加入 a, b; "get a" 访问 a (没有命中也算一次访问)
+func run(c Policy, ops []string) {
+	for _, op := range ops {
+		fields := strings.Fields(op)
+		for _, key := range fields[1:] {
+			if fields[0] == "put" {
+				c.Put(key, key)
+			} else {
+				c.Get(key)
+			}
+		}
+	}
+}
+
+func TestEvictionOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		cap     int
+		ops     []string
+		evicted []string
+	}{
+		{"lru 淘汰最久没有访问的", "lru", 3,
+			[]string{"put a b c", "get a", "put d e"},
+			[]string{"b", "c"}},
+		// 访问不到两次的最先淘汰; c 重新加入时继续使用淘汰前的访问历史, 新加入的 f 最先淘汰
+		{"lruk 淘汰倒数第 K 次访问最早的", "lruk", 3,
+			[]string{"put a b c", "get a b", "put d e c f"},
+			[]string{"c", "d", "e", "f"}},
+		// b 在 B1 命中以后增大 T1 的目标大小, 之后从 T2 淘汰
+		{"arc 根据 ghost 命中调整", "arc", 2,
+			[]string{"put a b", "get a", "put c b d"},
+			[]string{"b", "a", "b"}},
+		// 没有访问过的新元素不能替换主缓存里面的元素; x 在加入之前被访问过, 替换试用段尾部的 k1, 保护段的 k0 一直保留
+		{"tinylfu 按照访问频率准入", "tinylfu", 100,
+			[]string{"put " + strings.Join(keys("k", 100), " "), "get k0 k0 k0 k0 k0", "put new", "get x x x", "put x y"},
+			[]string{"k99", "new", "k1"}},
+	}
+	for _, test := range tests {
+		var evicted []string
+		c, err := New(test.policy, test.cap, func(key string, _ interface{}) { evicted = append(evicted, key) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		run(c, test.ops)
+		if strings.Join(evicted, " ") != strings.Join(test.evicted, " ") {
+			t.Errorf("%s: 淘汰顺序 %v, 期望 %v", test.name, evicted, test.evicted)
+		}
+		if c.Len() > c.Cap() {
+			t.Errorf("%s: 元素数量 %d 超过容量 %d", test.name, c.Len(), c.Cap())
+		}
+	}
+}
+
+// 所有策略共同的行为
+func TestPolicyCommon(t *testing.T) {
+	for _, name := range Names() {
+		evictions := 0
+		c, err := New(name, 4, func(string, interface{}) { evictions++ })
+		if err != nil {
+			t.Fatal(err)
+		}
+		run(c, []string{"put a b c d"})
+		c.Put("a", "A")
+		if v, has := c.Peek("a"); !has || v != "A" {
+			t.Errorf("%s: 更新以后 Peek = %v %v", name, v, has)
+		}
+		c.Remove("b")
+		if _, has := c.Get("b"); has || c.Len() != 3 || evictions != 0 {
+			t.Errorf("%s: Remove 以后 Len = %d, 淘汰 %d 次", name, c.Len(), evictions)
+		}
+		run(c, []string{"put e f g h i"})
+		if c.Len() != 4 {
+			t.Errorf("%s: Len = %d", name, c.Len())
+		}
+		c.Resize(2)
+		if c.Len() > 2 || c.Cap() != 2 || evictions != 6 {
+			t.Errorf("%s: Resize 以后 Len = %d, Cap = %d, 淘汰 %d 次", name, c.Len(), c.Cap(), evictions)
+		}
+	}
+	if _, err := New("fifo", 4, nil); err == nil {
+		t.Error("不存在的策略没有返回 err")
+	}
+	if _, err := New("lru", 0, nil); err == nil {
+		t.Error("容量为 0 没有返回 err")
+	}
+}
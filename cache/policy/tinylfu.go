@@ -0,0 +1,222 @@
+package policy
+
+import (
+	"container/list"
+	"hash/fnv"
+)
+
+// TinyLFU W-TinyLFU (Einziger, Friedman, Manes)。
+// 新的元素先进入窗口 LRU (容量的 1%), 从窗口淘汰的元素和主缓存里面将要淘汰的元素比较访问频率,
+// 频率高的留在主缓存。 主缓存为分段 LRU: 试用段 (20%) 和保护段 (80%), 试用段再次访问时进入保护段。
+// 访问频率用 Count-Min Sketch 估计, 访问次数达到 10 倍容量时所有计数减半, 让旧的频率逐渐失效
+type TinyLFU struct {
+	capacity     int
+	windowCap    int
+	protectedCap int
+	window       *list.List
+	probation    *list.List
+	protected    *list.List
+	items        map[string]*list.Element
+	where        map[string]*list.List
+	sketch       *countMinSketch
+	onEvict      EvictFunc
+}
+
+// NewTinyLFU 创建 W-TinyLFU 策略
+func NewTinyLFU(capacity int, onEvict EvictFunc) *TinyLFU {
+	c := &TinyLFU{
+		window:    list.New(),
+		probation: list.New(),
+		protected: list.New(),
+		items:     make(map[string]*list.Element),
+		where:     make(map[string]*list.List),
+		onEvict:   onEvict,
+	}
+	c.setCapacity(capacity)
+	c.sketch = newCountMinSketch(capacity)
+	return c
+}
+
+func (c *TinyLFU) Name() string { return "tinylfu" }
+
+// setCapacity 计算窗口和保护段的容量
+func (c *TinyLFU) setCapacity(capacity int) {
+	c.capacity = capacity
+	c.windowCap = maxInt(1, capacity/100)
+	c.protectedCap = (capacity - c.windowCap) * 8 / 10
+}
+
+func (c *TinyLFU) mainLen() int { return c.probation.Len() + c.protected.Len() }
+
+// move 把元素移动到链表 to 的头部 (最近访问)
+func (c *TinyLFU) move(key string, to *list.List) {
+	ent := c.where[key].Remove(c.items[key]).(*entry)
+	c.items[key] = to.PushFront(ent)
+	c.where[key] = to
+}
+
+func (c *TinyLFU) Get(key string) (interface{}, bool) {
+	c.sketch.increment(key)
+	l, has := c.where[key]
+	if !has {
+		return nil, false
+	}
+	value := c.items[key].Value.(*entry).value
+	if l == c.probation {
+		c.move(key, c.protected)
+		// 保护段超过容量时, 最久没有访问的元素降级到试用段
+		for c.protected.Len() > c.protectedCap {
+			c.move(c.protected.Back().Value.(*entry).key, c.probation)
+		}
+	} else {
+		c.move(key, l)
+	}
+	return value, true
+}
+
+func (c *TinyLFU) Peek(key string) (interface{}, bool) {
+	if e, has := c.items[key]; has {
+		return e.Value.(*entry).value, true
+	}
+	return nil, false
+}
+
+func (c *TinyLFU) Put(key string, value interface{}) {
+	if e, has := c.items[key]; has {
+		e.Value.(*entry).value = value
+		return
+	}
+	c.items[key] = c.window.PushFront(&entry{key, value})
+	c.where[key] = c.window
+	c.evict()
+}
+
+// evict 窗口超过容量时, 淘汰的元素和主缓存试用段的尾部比较访问频率
+func (c *TinyLFU) evict() {
+	for c.window.Len() > c.windowCap {
+		candidate := c.window.Back().Value.(*entry)
+		c.move(candidate.key, c.probation)
+		if c.mainLen() <= c.capacity-c.windowCap {
+			continue
+		}
+		// 主缓存满了, 候选元素和试用段尾部 (不是候选元素自己) 比较, 频率高的留下
+		victim := c.probation.Back().Value.(*entry)
+		if victim == candidate {
+			if c.protected.Len() == 0 {
+				c.drop(candidate)
+				continue
+			}
+			victim = c.protected.Back().Value.(*entry)
+		}
+		if c.sketch.estimate(candidate.key) > c.sketch.estimate(victim.key) {
+			c.drop(victim)
+		} else {
+			c.drop(candidate)
+		}
+	}
+	for c.mainLen() > c.capacity-c.windowCap {
+		if c.probation.Len() > 0 {
+			c.drop(c.probation.Back().Value.(*entry))
+		} else {
+			c.drop(c.protected.Back().Value.(*entry))
+		}
+	}
+}
+
+// drop 淘汰元素
+func (c *TinyLFU) drop(ent *entry) {
+	c.where[ent.key].Remove(c.items[ent.key])
+	delete(c.items, ent.key)
+	delete(c.where, ent.key)
+	if c.onEvict != nil {
+		c.onEvict(ent.key, ent.value)
+	}
+}
+
+func (c *TinyLFU) Remove(key string) {
+	if l, has := c.where[key]; has {
+		l.Remove(c.items[key])
+		delete(c.items, key)
+		delete(c.where, key)
+	}
+}
+
+func (c *TinyLFU) Len() int { return len(c.items) }
+
+func (c *TinyLFU) Cap() int { return c.capacity }
+
+func (c *TinyLFU) Resize(capacity int) {
+	c.setCapacity(capacity)
+	for c.protected.Len() > c.protectedCap {
+		c.move(c.protected.Back().Value.(*entry).key, c.probation)
+	}
+	c.evict()
+	// 容量变化时重新统计访问频率
+	c.sketch = newCountMinSketch(capacity)
+}
+
+// countMinSketch 4 行, 每个计数最大为 15 的 Count-Min Sketch
+type countMinSketch struct {
+	rows       [4][]uint8
+	mask       uint64
+	additions  int
+	sampleSize int
+}
+
+func newCountMinSketch(capacity int) *countMinSketch {
+	width := 16
+	for width < capacity {
+		width <<= 1
+	}
+	s := &countMinSketch{mask: uint64(width - 1), sampleSize: 10 * capacity}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// index 第 i 行的下标, 用双重哈希得到 4 个下标
+func (s *countMinSketch) index(h uint64, i int) uint64 {
+	h1, h2 := h, (h>>32)|1
+	return (h1 + uint64(i)*h2) & s.mask
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+func (s *countMinSketch) increment(key string) {
+	h := hashKey(key)
+	for i := range s.rows {
+		if j := s.index(h, i); s.rows[i][j] < 15 {
+			s.rows[i][j]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.reset()
+	}
+}
+
+func (s *countMinSketch) estimate(key string) uint8 {
+	h := hashKey(key)
+	min := uint8(15)
+	for i := range s.rows {
+		if v := s.rows[i][s.index(h, i)]; v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// reset 所有计数减半
+func (s *countMinSketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
@@ -5,7 +5,7 @@ import (
 )
 
 func (c *Cache) ROOT_PooledTables() {
-	if len(c.tableInfo.tables) == 0 && len(c.tableInfo.tables) == 0 {
+	if len(c.tableInfo.tables) == 0 {
 		fmt.Printf("没有任何表被加载\n")
 		return
 	}
@@ -21,8 +21,8 @@ func (c *Cache) ROOT_PooledTables() {
 }
 
 func (c *Cache) ROOT_lruQ() {
-	fmt.Printf("lru data len : %v; lru index len : %v\n", c.lru3Query.listTx.curSize, c.lru3Query.listIndex.curSize)
-	fmt.Printf("map data len : %v; map index len : %v\n", c.lru3Query.listTx.curSize, c.lru3Query.listIndex.curSize)
+	txSize, txLen, indexSize, indexLen := c.lru3Query.size()
+	fmt.Printf("lru data len : %v/%v; lru index len : %v/%v\n", txLen, txSize, indexLen, indexSize)
 
 	//p := c.lru3Query.listTx.head
 	//ct1 := 0
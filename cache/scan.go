@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"alg_bcDB/blockchain/blockchain_data"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ScanTable 按照 key 的字节序查询表里面 [from, to) 之间, 有前缀 prefix 的数据, to 为空时到表的末尾。
+// 最多返回 limit 个, 后面还有数据时返回下一个 key, 作为下一次查询的 from。
+// 优先使用世界状态; 世界状态不可用时使用数据索引, 每个区块只读取一次; 数据索引也不可用时(轻节点)读取整个表再排序
+func (c *Cache) ScanTable(table, from, to, prefix string, limit int) ([]blockchain_data.Transaction, string, error) {
+	states, next, err := c.dataChain.ScanState(table, from, to, prefix, limit)
+	if err == nil {
+		txs := make([]blockchain_data.Transaction, 0, len(states))
+		for _, e := range states {
+			txs = append(txs, e.Transaction)
+		}
+		return txs, next, nil
+	}
+	if err != blockchain_data.ErrNoState {
+		return nil, "", err
+	}
+	entries, next, err := c.dataChain.ScanKeys(table, from, to, prefix, limit)
+	if err != nil {
+		return c.scanTableData(table, from, to, prefix, limit)
+	}
+	// 区块 HASH -> 区块里面所有的写入
+	blocks := make(map[string][]*blockchain_data.Transaction)
+	txs := make([]blockchain_data.Transaction, 0, len(entries))
+	for _, entry := range entries {
+		writes, has := blocks[string(entry.Location.BlockHash)]
+		if !has {
+			block, err := c.dataChain.GetBlockByHash(entry.Location.BlockHash)
+			if err != nil {
+				return nil, "", err
+			}
+			writes = block.Writes()
+			blocks[string(entry.Location.BlockHash)] = writes
+		}
+		if int(entry.Location.TxIndex) >= len(writes) {
+			return nil, "", errors.New("索引指向的交易不存在")
+		}
+		// 被删除的数据不返回, 这一页的数据可能少于 limit
+		if tx := writes[entry.Location.TxIndex]; !tx.IsDelete() {
+			txs = append(txs, *tx)
+		}
+	}
+	return txs, next, nil
+}
+
+// scanTableData 通过表的相关链读取整个表(表必须存在), 排序后返回 ScanTable 的结果
+func (c *Cache) scanTableData(table, from, to, prefix string, limit int) ([]blockchain_data.Transaction, string, error) {
+	data := c.tableInfo.getTableData(table)
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if key >= from && strings.HasPrefix(key, prefix) && (to == "" || key < to) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	next := ""
+	if len(keys) > limit {
+		next = keys[limit]
+		keys = keys[:limit]
+	}
+	txs := make([]blockchain_data.Transaction, 0, len(keys))
+	for _, key := range keys {
+		txs = append(txs, *data[key])
+	}
+	return txs, next, nil
+}
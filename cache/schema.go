@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/blockchain/blockchain_table"
+	"fmt"
+)
+
+// Schema 表当前的 schema (没有时为 nil) 和版本, 没有声明过 schema 的表版本为 0
+func (c *Cache) Schema(table string) (*blockchain_table.Schema, uint64) {
+	return c.tableInfo.schema(table)
+}
+
+// CheckSchema 检查交易的每个写入是否符合表当前的 schema, 使用本地当前的数据检查引用
+func (c *Cache) CheckSchema(tx *blockchain_data.Transaction) error {
+	return c.checkSchema(&c.tableInfo, tx.Expand(), nil)
+}
+
+// checkSchema 检查写入记录的 schema 版本是 tio 里面表的版本, 并且写入的值符合 schema。
+// 引用的 key 可以是同一个交易里面的写入, 或者 written (可以为 nil) 里面同一个区块前面的交易的写入
+func (c *Cache) checkSchema(tio *tableInfo, writes []*blockchain_data.Transaction, written map[string]*blockchain_data.Transaction) error {
+	own := make(map[string]*blockchain_data.Transaction, len(writes))
+	for _, w := range writes {
+		own[string(w.DataID)] = w
+	}
+	exists := func(table, key string) bool {
+		dataID := table + "-QAQ-" + key
+		if w, has := own[dataID]; has {
+			return !w.IsDelete()
+		}
+		if _, err := tio.checkPermission("", table); err != nil {
+			return false
+		}
+		return c.current(dataID, table, written) != nil
+	}
+	for _, w := range writes {
+		schema, version := tio.schema(w.Table)
+		if w.SchemaVersion != version {
+			return fmt.Errorf("表 %s 的 schema 版本是 %d, 写入是按照版本 %d 检查的", w.Table, version, w.SchemaVersion)
+		}
+		if schema == nil || w.IsDelete() {
+			continue
+		}
+		if w.Type != blockchain_data.TypeJSON {
+			return fmt.Errorf("表 %s 有 schema, 值的类型必须是 json", w.Table)
+		}
+		if err := schema.Validate([]byte(w.Value), exists); err != nil {
+			return fmt.Errorf("表 %s 里面的 key %s 不符合 schema: %v", w.Table, w.Key, err)
+		}
+	}
+	return nil
+}
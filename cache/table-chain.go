@@ -3,11 +3,11 @@ package cache
 import (
 	"alg_bcDB/blockchain/blockchain_data"
 	"alg_bcDB/blockchain/blockchain_table"
+	"alg_bcDB/blockchain/blockstore"
 	"alg_bcDB/util"
 	"bytes"
 	"errors"
 	"fmt"
-	"github.com/boltdb/bolt"
 	"log"
 	"os"
 	"sync"
@@ -18,12 +18,17 @@ import (
 
 type tableInfo struct {
 	sync.RWMutex
-	tables     map[string]map[string]string
+	tables     map[string]blockchain_table.Permissions // 表名 -> 表的权限表
+	schemas    map[string]tableSchema                  // 表名 -> 表当前的 schema, 没有声明过 schema 的表不在里面
+	indexes    map[string][]string                     // 表名 -> 表声明的二级索引字段
+	id         uint64                                  // 最后应用的表区块的 ID
+	hash       []byte                                  // 最后应用的表区块的 HASH
 	dataChain  *blockchain_data.BlockChain
 	tableChain *blockchain_table.BlockChain
 }
 
 // 初始化，读取所有的表。加载对应的权限信息到内存里面。
+// 权限表由表交易按照区块的顺序依次 授予, 移除, 替换 得到, 所以从创世区块开始应用所有的表交易
 func (tio *tableInfo) init(dataChain *blockchain_data.BlockChain, tableChain *blockchain_table.BlockChain) {
 	tio.Lock()
 	defer tio.Unlock()
@@ -31,34 +36,120 @@ func (tio *tableInfo) init(dataChain *blockchain_data.BlockChain, tableChain *bl
 	tio.dataChain = dataChain
 	tio.tableChain = tableChain
 
-	tio.tables = make(map[string]map[string]string)
+	tio.tables = make(map[string]blockchain_table.Permissions)
+	tio.schemas = make(map[string]tableSchema)
+	tio.indexes = make(map[string][]string)
 
+	var blocks []blockchain_table.Block
 	it := tio.tableChain.CreateIterator()
 	for {
-		// 放在前面可以不读创世区块。
 		block := it.Next()
-		if bytes.Equal(it.CurrentHash, []byte("welcome to 407")) == true {
-			fmt.Printf("(cache ) : pooled tables Initialization complete\n")
-			return
+		blocks = append(blocks, block)
+		if bytes.Equal(it.CurrentHash, []byte("welcome to 407")) {
+			break
 		}
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		tio.applyBlock(blocks[i])
+	}
+	// 创世区块里面的表没有经过 upDateByTables, 在这里创建相关链
+	for _, tx := range blocks[len(blocks)-1].Transactions {
+		if len(tx.PermissionTable) != 0 && !tio.hasBucket(tx.Table) {
+			tio.createBucket(tx.Table)
+		}
+	}
+	fmt.Printf("(cache ) : pooled tables Initialization complete\n")
+}
 
-		for _, tx := range block.Transactions {
-			// 只读取最新的权限表信息
-			if _, has := tio.tables[tx.Table]; !has {
-				tio.tables[tx.Table] = make(map[string]string)
-				for _, v := range tx.PermissionTable {
-					tio.tables[tx.Table][v[:len(v)-1]] = v[len(v)-1:]
-				}
-			}
+// applyBlock 按照顺序应用表区块里面的交易, 创世区块里面只读取创世文件定义的表(有权限的表)。 调用时已经持有锁
+func (tio *tableInfo) applyBlock(block blockchain_table.Block) {
+	isGenesis := bytes.Equal(block.PreviousBlockHash, []byte("welcome to 407"))
+	for _, tx := range block.Transactions {
+		if isGenesis && len(tx.PermissionTable) == 0 {
+			continue
+		}
+		tio.applyTx(tx)
+	}
+	tio.id, tio.hash = uint64(block.ID), block.CurrentBlockHash
+}
+
+// applyTx 应用一个表交易。 调用时已经持有锁
+func (tio *tableInfo) applyTx(tx *blockchain_table.Transaction) {
+	tio.tables[tx.Table] = tio.tables[tx.Table].Apply(tx)
+	tio.applySchema(tx)
+	tio.applyIndexes(tx)
+}
+
+// position 最后应用的表区块的 ID 和 HASH
+func (tio *tableInfo) position() (uint64, []byte) {
+	tio.RLock()
+	defer tio.RUnlock()
+
+	return tio.id, tio.hash
+}
+
+// snapshot 当前的权限表和 schema 的副本, 不能用来读写相关链。
+// 副本不会被后面的表区块修改, 同一个副本里面的权限表, schema 和位置一致
+func (tio *tableInfo) snapshot() *tableInfo {
+	tio.RLock()
+	defer tio.RUnlock()
+
+	s := &tableInfo{
+		tables:  make(map[string]blockchain_table.Permissions, len(tio.tables)),
+		schemas: make(map[string]tableSchema, len(tio.schemas)),
+		indexes: make(map[string][]string),
+		id:      tio.id,
+		hash:    tio.hash,
+	}
+	// Apply 返回新的权限表, 不修改原来的, 可以共用
+	for table, p := range tio.tables {
+		s.tables[table] = p
+	}
+	for table, ts := range tio.schemas {
+		s.schemas[table] = ts
+	}
+	return s
+}
+
+// replay 表区块链在区块 id 时的权限表和 schema, 从创世区块开始重新应用表交易, 不能用来读写相关链
+func (tio *tableInfo) replay(id uint64) (*tableInfo, error) {
+	s := &tableInfo{
+		tables:  make(map[string]blockchain_table.Permissions),
+		schemas: make(map[string]tableSchema),
+		indexes: make(map[string][]string),
+	}
+	for i := uint64(1); i <= id; i++ {
+		hash := tio.tableChain.GetHashByID(int(i))
+		if hash == nil {
+			return nil, fmt.Errorf("本地的表区块链没有区块 %d", i)
+		}
+		block, err := tio.tableChain.GetBlockByHash(hash)
+		if err != nil {
+			return nil, err
 		}
+		s.applyBlock(*block)
 	}
+	return s, nil
+}
+
+// hasBucket 表的相关链是否已经创建
+func (tio *tableInfo) hasBucket(tableName string) bool {
+	has := false
+	tio.tableChain.Store.View(func(tx blockstore.Tx) error {
+		has = tx.Bucket(tableName) != nil
+		return nil
+	})
+	return has
 }
 
 // 创建新的表时，为新的表创建同名的bucket并初始化相关链。
 func (tio *tableInfo) createBucket(tableName string) {
 
-	tio.tableChain.Db.Update(func(tx *bolt.Tx) error {
-		bucket, err := tx.CreateBucket([]byte(tableName))
+	tio.tableChain.Store.Update(func(tx blockstore.Tx) error {
+		if tx.Bucket(tableName) != nil {
+			log.Panic("bucket already exists")
+		}
+		bucket, err := tx.CreateBucketIfNotExists(tableName)
 		if err != nil {
 			log.Panic(err)
 		}
@@ -86,21 +177,75 @@ func (tio *tableInfo) upDateByTables(block blockchain_table.Block) {
 	for _, tx := range block.Transactions {
 
 		if _, has := tio.tables[tx.Table]; !has {
-			tio.tables[tx.Table] = make(map[string]string)
 			tio.createBucket(tx.Table)
 		}
+		tio.applyTx(tx)
+	}
+	tio.id, tio.hash = uint64(block.ID), block.CurrentBlockHash
+}
 
-		for _, v := range tx.PermissionTable {
-			tio.tables[tx.Table][v[:len(v)-1]] = v[len(v)-1:]
-		}
+// tableSchema 表当前的 schema 和版本, schema 为 nil 时表的 schema 已经被去掉
+type tableSchema struct {
+	schema  *blockchain_table.Schema
+	version uint64
+}
+
+// applySchema 应用 OpSchema 的表交易, 表的 schema 的版本加一。 调用时已经持有锁
+func (tio *tableInfo) applySchema(tx *blockchain_table.Transaction) {
+	if tx.Op != blockchain_table.OpSchema {
+		return
 	}
+	// 区块校验时已经检查过, 解析错误时和去掉 schema 一样
+	schema, _, _ := blockchain_table.ParseSchema(tx.Schema)
+	tio.schemas[tx.Table] = tableSchema{schema: schema, version: tio.schemas[tx.Table].version + 1}
+}
+
+// applyIndexes 应用 OpIndex 的表交易, 替换表的索引字段。 调用时已经持有锁
+func (tio *tableInfo) applyIndexes(tx *blockchain_table.Transaction) {
+	if tx.Op != blockchain_table.OpIndex {
+		return
+	}
+	if len(tx.Indexes) == 0 {
+		delete(tio.indexes, tx.Table)
+		return
+	}
+	tio.indexes[tx.Table] = append([]string{}, tx.Indexes...)
+}
+
+// indexFields 表声明的索引字段
+func (tio *tableInfo) indexFields(table string) []string {
+	tio.RLock()
+	defer tio.RUnlock()
+
+	return append([]string{}, tio.indexes[table]...)
+}
+
+// indexedTables 所有声明了索引的表
+func (tio *tableInfo) indexedTables() []string {
+	tio.RLock()
+	defer tio.RUnlock()
+
+	var tables []string
+	for table := range tio.indexes {
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// schema 表当前的 schema (没有时为 nil) 和版本
+func (tio *tableInfo) schema(table string) (*blockchain_table.Schema, uint64) {
+	tio.RLock()
+	defer tio.RUnlock()
+
+	ts := tio.schemas[table]
+	return ts.schema, ts.version
 }
 
 // 在得到新的数据时，链长表相关链
 func (tio *tableInfo) updateBucket(tableName string, blockHash []byte) {
 
-	tio.tableChain.Db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(tableName))
+	tio.tableChain.Store.Update(func(tx blockstore.Tx) error {
+		bucket := tx.Bucket(tableName)
 		if bucket == nil {
 			fmt.Println("(cache): 更新共享表相关链失败; 读取到空的bucket")
 			os.Exit(1)
@@ -131,36 +276,27 @@ func (tio *tableInfo) updateBucket(tableName string, blockHash []byte) {
 }
 
 func (tio *tableInfo) upDateByData(block blockchain_data.Block) {
-	for _, tx := range block.Transactions {
+	for _, tx := range block.Writes() {
 		tio.updateBucket(tx.Table, block.CurrentBlockHash)
 	}
 }
 
 func (tio *tableInfo) getBlock(blockHash []byte) (block blockchain_data.Block) {
 
-	tio.dataChain.Db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(tio.dataChain.BlockBucket))
-		if bucket == nil {
-			log.Panic("bucket nil")
-		}
-
-		// 在boltDB里面得到区块
-		bytesBlock := bucket.Get(blockHash)
-		block = blockchain_data.Deserialize(bytesBlock)
-
-		return nil
-
-	})
-	return block
+	// 在存储里面得到区块, 轻节点会按需获取区块体
+	b, err := tio.dataChain.GetBlockByHash(blockHash)
+	if err != nil {
+		log.Panic(err)
+	}
+	return *b
 }
 
 func (tio *tableInfo) getInTableHashChain(dataID, tableName string) (Tx blockchain_data.Transaction, blockHash []byte, txID []byte, e error) {
 
-	var lastTimeStamp int64
 	var lastTX *blockchain_data.Transaction
 
-	tio.tableChain.Db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(tableName))
+	tio.tableChain.Store.View(func(tx blockstore.Tx) error {
+		bucket := tx.Bucket(tableName)
 		if bucket == nil {
 			log.Panic("bucket nil")
 		}
@@ -171,16 +307,14 @@ func (tio *tableInfo) getInTableHashChain(dataID, tableName string) (Tx blockcha
 		for !bytes.Equal(lastBlockHash, []byte("root")) {
 
 			block := tio.getBlock(lastBlockHash)
-			for _, tx := range block.Transactions {
+			// 同一个区块里面取靠后的交易
+			for _, tx := range block.Writes() {
 				if bytes.Equal(tx.DataID, []byte(dataID)) {
-					if tx.TimeStamp > lastTimeStamp {
-						lastTimeStamp = tx.TimeStamp
-						lastTX = tx
-					}
+					lastTX = tx
 					blockHash = block.CurrentBlockHash
 				}
 			}
-			if lastTimeStamp > 0 {
+			if lastTX != nil {
 				return nil
 			}
 			lastKey := util.BytesToUint64(lastKeyBytes)
@@ -192,7 +326,7 @@ func (tio *tableInfo) getInTableHashChain(dataID, tableName string) (Tx blockcha
 		return nil
 
 	})
-	if lastTimeStamp > 0 {
+	if lastTX != nil {
 		return *lastTX, blockHash, lastTX.TxID, nil
 	}
 	return blockchain_data.Transaction{}, nil, nil, errors.New("null")
@@ -202,8 +336,8 @@ func (tio *tableInfo) getHistoryInTableHashChain(dataID string, tableName string
 	tio.RLock()
 	defer tio.RUnlock()
 
-	tio.tableChain.Db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(tableName))
+	tio.tableChain.Store.View(func(tx blockstore.Tx) error {
+		bucket := tx.Bucket(tableName)
 		if bucket == nil {
 			log.Panic("bucket nil")
 		}
@@ -213,7 +347,7 @@ func (tio *tableInfo) getHistoryInTableHashChain(dataID string, tableName string
 
 		for !bytes.Equal(lastBlockHash, []byte("root")) {
 			block := tio.getBlock(lastBlockHash)
-			for _, tx := range block.Transactions {
+			for _, tx := range block.Writes() {
 				if bytes.Equal(tx.DataID, []byte(dataID)) {
 					txs = append(txs, *tx)
 				}
@@ -232,17 +366,17 @@ func (tio *tableInfo) getHistoryInTableHashChain(dataID string, tableName string
 	return txs, nil
 }
 
-// 得到同表里面的所有数据（根据key，拿到最新的数据）
+// 得到同表里面的所有数据（根据key，拿到最新的数据, 不包括被删除的数据）
 // 根据表相关链，遍历区块。
-// 得到新的数据时，添加。重复的key，比较时间戳在添加。
+// 得到新的数据时，添加。重复的key，只保留较新的区块里面的, 同一个区块里面取靠后的。
 func (tio *tableInfo) getTableData(tableName string) (txs map[string]*blockchain_data.Transaction) {
 	tio.RLock()
 	defer tio.RUnlock()
 
 	txs = make(map[string]*blockchain_data.Transaction)
 
-	tio.tableChain.Db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(tableName))
+	tio.tableChain.Store.View(func(tx blockstore.Tx) error {
+		bucket := tx.Bucket(tableName)
 		if bucket == nil {
 			log.Panic("bucket nil")
 		}
@@ -252,13 +386,17 @@ func (tio *tableInfo) getTableData(tableName string) (txs map[string]*blockchain
 
 		for !bytes.Equal(lastBlockHash, []byte("root")) {
 			block := tio.getBlock(lastBlockHash)
-			for _, tx := range block.Transactions {
-				if _, has := txs[tx.Key]; !has {
-					txs[tx.Key] = tx
-				} else {
-					if tx.TimeStamp > txs[tx.Key].TimeStamp {
-						txs[tx.Key] = tx
-					}
+			inBlock := make(map[string]*blockchain_data.Transaction)
+			for _, tx := range block.Writes() {
+				// 区块里面还有其他表的数据
+				if tx.Table != tableName {
+					continue
+				}
+				inBlock[tx.Key] = tx
+			}
+			for key, tx := range inBlock {
+				if _, has := txs[key]; !has {
+					txs[key] = tx
 				}
 			}
 			lastKey := util.BytesToUint64(lastKeyBytes)
@@ -269,31 +407,59 @@ func (tio *tableInfo) getTableData(tableName string) (txs map[string]*blockchain
 		return nil
 
 	})
+	// 最新的交易是墓碑的数据已经被删除
+	for key, tx := range txs {
+		if tx.IsDelete() {
+			delete(txs, key)
+		}
+	}
 	return txs
 }
 
-// CheckPermission 查找指定地址用户的权限
-// 返回用户在指定表的权限 或者 错误信息
-func (tio *tableInfo) checkPermission(address, table string) (string, error) {
+// CheckPermission 查找指定地址用户的角色
+// 返回用户在指定表的角色 (不在表里面时为 RoleNone) 或者 错误信息
+func (tio *tableInfo) checkPermission(address, table string) (blockchain_table.Role, error) {
 	tio.RLock()
 	defer tio.RUnlock()
 
 	if _, has := tio.tables[table]; !has {
-		return "-1", errors.New("no such table")
+		return blockchain_table.RoleNone, errors.New("no such table")
 	}
+	return tio.tables[table][address], nil
+}
 
-	if _, has := tio.tables[table][address]; !has {
-		return "-1", nil
+// checkAuthority 检查表交易能否应用到表当前的权限表
+func (tio *tableInfo) checkAuthority(tx *blockchain_table.Transaction) error {
+	tio.RLock()
+	defer tio.RUnlock()
+
+	p, has := tio.tables[tx.Table]
+	_, err := blockchain_table.CheckAuthority(p, has, tx)
+	return err
+}
+
+// permissions 表的权限表的副本
+func (tio *tableInfo) permissions(table string) (blockchain_table.Permissions, error) {
+	tio.RLock()
+	defer tio.RUnlock()
+
+	p, has := tio.tables[table]
+	if !has {
+		return nil, errors.New("no such table")
 	}
-	return tio.tables[table][address], nil
+	copied := make(blockchain_table.Permissions, len(p))
+	for address, role := range p {
+		copied[address] = role
+	}
+	return copied, nil
 }
 
 // uerTables 查看用户的所有表
-func (tio *tableInfo) uerTables(address string) (myTabels map[string]string, err error) {
+func (tio *tableInfo) uerTables(address string) (myTabels map[string]blockchain_table.Role, err error) {
 	tio.RLock()
 	defer tio.RUnlock()
 
-	myTabels = make(map[string]string)
+	myTabels = make(map[string]blockchain_table.Role)
 	for tn, tpl := range tio.tables {
 		if pl, has := tpl[address]; has {
 			myTabels[tn] = pl
@@ -0,0 +1,242 @@
+package cache
+
+import (
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/blockchain/blockchain_table"
+	"alg_bcDB/util"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 数据写入的权限:
+// write (2) 可以创建新的 key, 和修改自己拥有的 key;
+// overwrite (3) 及以上可以修改任何人的 key。
+// key 的拥有者是写入这个 key 最新的值的用户, 由交易的公钥得到地址 (Possessor 只是用户名, 不能用来校验)
+//
+// 条件写入: 交易的条件对 key 当前的值 (包括同一个区块里面前面的交易写入的值) 成立才可以写入,
+// 打包时去掉条件不成立的交易, 提交时条件不成立的交易被标记为无效 (blockchain_data.validateReads)
+// 读写集交易: 读取集的表要有查看权限, 读取的版本在提交时校验 (blockchain_data.validateReads)
+// 有 schema 的表: 写入记录的 schema 版本是表当前的版本, 值符合 schema (见 checkSchema)
+//
+// 权限表和 schema 由表区块链决定, 各个节点收到表区块的时间不同。 打包时记录使用的表区块链的位置 (区块头的 TableID, TableHash),
+// 校验区块时按照这个位置的权限表和 schema 检查, 所有节点的结果一样 (见 tablesAt)
+
+// ConflictError 条件写入的条件不成立
+type ConflictError struct {
+	Table   string
+	Key     string
+	Cond    blockchain_data.Condition
+	Current []byte // key 当前的版本, key 不存在时为 nil
+}
+
+func (e *ConflictError) Error() string {
+	return blockchain_data.ConflictReason(e.Table, e.Key, e.Current)
+}
+
+// CheckWrite 检查交易的写入者是否可以写入这个 key, 以及写入的条件是否成立, 使用本地当前的权限表和数据。
+// 批量交易要所有的写入都可以写入
+func (c *Cache) CheckWrite(tx *blockchain_data.Transaction) error {
+	return c.checkTx(&c.tableInfo, tx, nil)
+}
+
+// CheckBlockWrites 按照区块里面交易的顺序检查每个写入, 前面的交易创建或者修改的 key 对后面的交易生效。
+// 权限表和 schema 使用区块头里面记录的表区块链的位置 (见 tablesAt)。
+// 条件不成立的交易在区块头里面被标记为无效, 只跳过这个交易, 不影响区块
+func (c *Cache) CheckBlockWrites(block *blockchain_data.Block) error {
+	tio, err := c.tablesAt(block)
+	if err != nil {
+		return err
+	}
+	written := make(map[string]*blockchain_data.Transaction)
+	for i, tx := range block.Transactions {
+		err := c.checkTx(tio, tx, written)
+		var conflict *ConflictError
+		if errors.As(err, &conflict) && !block.IsValid(i) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("第 %d 个交易: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// Rejection 没有通过检查的交易和原因
+type Rejection struct {
+	Tx  *blockchain_data.Transaction
+	Err error
+}
+
+// FilterWrites 去掉没有写入权限或者条件不成立的交易, 剩下的交易按照顺序可以通过 CheckBlockWrites。
+// 批量交易有一个写入不能写入时整个交易被去掉。
+// 返回检查时使用的表区块链的位置, 要写入区块头的 TableID, TableHash
+func (c *Cache) FilterWrites(txs []*blockchain_data.Transaction) (valid []*blockchain_data.Transaction, rejected []Rejection, tableID uint64, tableHash []byte) {
+	tio := c.tableInfo.snapshot()
+	written := make(map[string]*blockchain_data.Transaction)
+	for _, tx := range txs {
+		if err := c.checkTx(tio, tx, written); err != nil {
+			rejected = append(rejected, Rejection{Tx: tx, Err: err})
+			continue
+		}
+		valid = append(valid, tx)
+	}
+	return valid, rejected, tio.id, tio.hash
+}
+
+// tablesAt 校验区块的写入时使用的权限表和 schema: 区块头里面记录的表区块链的位置。
+// 位置不能早于前面的区块的位置, 否则提议者可以使用被移除之前的权限。
+// 是本地当前的位置时使用当前的副本, 更早的位置从创世区块开始重新应用表交易 (保留最近一次的结果);
+// 本地的表区块链还没有这个区块时返回错误, 同步表区块链以后再校验。
+// 没有记录位置的区块 (旧的区块和空的区块) 使用本地当前的权限表和 schema
+func (c *Cache) tablesAt(block *blockchain_data.Block) (*tableInfo, error) {
+	if len(block.TableHash) == 0 {
+		return &c.tableInfo, nil
+	}
+	if prev := c.prevTableID(block); block.TableID < prev {
+		return nil, fmt.Errorf("区块头里面表区块链的位置 %d 早于前面的区块的位置 %d", block.TableID, prev)
+	}
+	current := c.tableInfo.snapshot()
+	if current.id == block.TableID && bytes.Equal(current.hash, block.TableHash) {
+		return current, nil
+	}
+	if block.TableID > current.id {
+		return nil, fmt.Errorf("本地的表区块链还没有区块 %d, 同步以后再校验", block.TableID)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if c.tablesReplay == nil || c.tablesReplay.id != block.TableID {
+		replayed, err := c.tableInfo.replay(block.TableID)
+		if err != nil {
+			return nil, err
+		}
+		c.tablesReplay = replayed
+	}
+	if !bytes.Equal(c.tablesReplay.hash, block.TableHash) {
+		return nil, fmt.Errorf("区块头里面的表区块 %d 和本地的表区块链不一致", block.TableID)
+	}
+	return c.tablesReplay, nil
+}
+
+// prevTableID 前面最近的记录了位置的区块里面表区块链的位置, 都没有记录时为 0
+func (c *Cache) prevTableID(block *blockchain_data.Block) uint64 {
+	hash := block.PreviousBlockHash
+	for {
+		// 创世区块的前一个区块不存在
+		prev, err := c.dataChain.GetHeaderByHash(hash)
+		if err != nil {
+			return 0
+		}
+		if len(prev.TableHash) != 0 {
+			return prev.TableID
+		}
+		hash = prev.PreviousBlockHash
+	}
+}
+
+// checkTx 按照 tio 的权限表和 schema 检查交易的所有写入, 都可以写入时把写入记录到 written (不为 nil 时)。
+// 读取集过期的交易可以打包, 提交时被标记为无效, 它的写入对后面的交易不可见
+func (c *Cache) checkTx(tio *tableInfo, tx *blockchain_data.Transaction, written map[string]*blockchain_data.Transaction) error {
+	if err := tx.CheckBatch(); err != nil {
+		return err
+	}
+	if err := tx.CheckDataID(); err != nil {
+		return err
+	}
+	if err := tx.CheckCondition(); err != nil {
+		return err
+	}
+	if err := tx.CheckReads(); err != nil {
+		return err
+	}
+	if err := tx.CheckValues(); err != nil {
+		return err
+	}
+	address := util.CalculateAddress(tx.PublicKey)
+	for _, r := range tx.Reads {
+		if role, err := tio.checkPermission(address, r.Table); err != nil || role < blockchain_table.RoleRead {
+			return fmt.Errorf("没有对表 %s 的查看权限", r.Table)
+		}
+	}
+	writes := tx.Expand()
+	for _, w := range writes {
+		if err := c.checkWrite(tio, w, written); err != nil {
+			return err
+		}
+	}
+	if err := c.checkSchema(tio, writes, written); err != nil {
+		return err
+	}
+	if written == nil || c.StaleRead(tx, written) != nil {
+		return nil
+	}
+	for _, w := range writes {
+		written[string(w.DataID)] = w
+	}
+	return nil
+}
+
+// StaleRead 交易的读取集里面版本已经过期的读取, 都没有过期时返回 nil。
+// written 为同一个区块里面前面的交易的写入, 可以为 nil
+func (c *Cache) StaleRead(tx *blockchain_data.Transaction, written map[string]*blockchain_data.Transaction) *blockchain_data.Read {
+	return tx.StaleRead(func(dataID string) []byte {
+		if current := c.current(dataID, tableOf(dataID), written); current != nil {
+			return current.TxID
+		}
+		return nil
+	})
+}
+
+// tableOf 数据ID 里面的表名
+func tableOf(dataID string) string {
+	if i := strings.Index(dataID, "-QAQ-"); i >= 0 {
+		return dataID[:i]
+	}
+	return dataID
+}
+
+// current key 当前的值, 不存在或者已经被删除时返回 nil。
+// written 为同一个区块里面前面的交易的写入, 可以为 nil
+func (c *Cache) current(dataID, table string, written map[string]*blockchain_data.Transaction) *blockchain_data.Transaction {
+	if w, has := written[dataID]; has {
+		if w.IsDelete() {
+			return nil
+		}
+		return w
+	}
+	current, err := c.GetOneValue(dataID, table)
+	if err != nil {
+		return nil
+	}
+	return &current
+}
+
+// checkWrite 按照 tio 的权限表检查一个写入的权限和条件
+func (c *Cache) checkWrite(tio *tableInfo, tx *blockchain_data.Transaction, written map[string]*blockchain_data.Transaction) error {
+	address := util.CalculateAddress(tx.PublicKey)
+	role, err := tio.checkPermission(address, tx.Table)
+	if err != nil {
+		return fmt.Errorf("不存在这个表; %s", tx.Table)
+	}
+	if role < blockchain_table.RoleWrite {
+		return fmt.Errorf("没有对表 %s 的写入权限", tx.Table)
+	}
+	current := c.current(string(tx.DataID), tx.Table, written)
+	if !tx.Holds(current) {
+		conflict := &ConflictError{Table: tx.Table, Key: tx.Key, Cond: tx.Cond}
+		if current != nil {
+			conflict.Current = current.TxID
+		}
+		return conflict
+	}
+	if role >= blockchain_table.RoleOverwrite || current == nil {
+		// overwrite 权限, 或者新的 key
+		return nil
+	}
+	if util.CalculateAddress(current.PublicKey) != address {
+		return fmt.Errorf("key %s 属于其他用户, 需要 overwrite 权限", tx.Key)
+	}
+	return nil
+}
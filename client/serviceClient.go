@@ -1,12 +1,24 @@
 package client
 
 import (
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/cache"
+	"alg_bcDB/config"
 	"alg_bcDB/server"
+	"encoding/hex"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"strconv"
 )
 
 var Cserver *server.Server
 
+// uid 请求的用户 (用户名-QAQ-密码), 在请求头 X-UID 里面。
+// 不放在 URL 的参数里面, gin 的日志会记录完整的 URL
+func uid(c *gin.Context) string {
+	return c.GetHeader("X-UID")
+}
+
 type Aircondition struct {
 	Temperature       string `json:"temperature" xml:"temperature" form:"temperature" query:"temperature"`
 	TargetTemperature string `json:"target" xml:"target" form:"target" query:"target"`
@@ -16,23 +28,452 @@ type Refrigerator struct {
 	IceTemperature  string `json:"ice" xml:"ice" form:"ice" query:"ice"`
 }
 
+// 设备的数据作为一个 JSON 值保存在表 data 里面, key 为设备的类型, 由设备的账户通过 PUT /data?type=json 写入。
+// 以前每个字段是一个单独的 key, 没有 JSON 值时读取原来的字段
+const (
+	deviceUID   = "123" + "-QAQ-" + "123456"
+	deviceTable = "data"
+)
+
+// getDevice 读取设备的 JSON 值到 v, 没有时返回 false
+func getDevice(key string, v interface{}) bool {
+	value, typ, _, err := Cserver.GetValue(deviceUID, key, deviceTable, "")
+	if err != nil || typ != blockchain_data.TypeJSON {
+		return false
+	}
+	return json.Unmarshal(value, v) == nil
+}
+
 func getAData(c *gin.Context) {
 	var a Aircondition
-	a.Temperature = Cserver.Get("123"+"-QAQ-"+"123456", "Temperature", "data")
-	a.TargetTemperature = Cserver.Get("123"+"-QAQ-"+"123456", "TargetTemperature", "data")
+	if !getDevice("Aircondition", &a) {
+		a.Temperature = Cserver.Get(deviceUID, "Temperature", deviceTable)
+		a.TargetTemperature = Cserver.Get(deviceUID, "TargetTemperature", deviceTable)
+	}
 	c.JSON(200, a)
 }
 
 func getRData(c *gin.Context) {
 	var r Refrigerator
-	r.ColdTemperature = Cserver.Get("123"+"-QAQ-"+"123456", "ColdTemperature", "data")
-	r.IceTemperature = Cserver.Get("123"+"-QAQ-"+"123456", "IceTemperature", "data")
+	if !getDevice("Refrigerator", &r) {
+		r.ColdTemperature = Cserver.Get(deviceUID, "ColdTemperature", deviceTable)
+		r.IceTemperature = Cserver.Get(deviceUID, "IceTemperature", deviceTable)
+	}
 	c.JSON(200, r)
 }
 
+// VerifiableResult 可验证查询的结果, 字节数组为十六进制。
+// 校验方法和 GRPC 的 VerifiableGet 一样, 可以使用 blockchain_data.VerifyTxProof,
+// 区块的 HASH 要从可信的来源得到 (比如轻节点同步的区块头), 不能只相信返回的 block_hash
+type VerifiableResult struct {
+	Value       string   `json:"value"`
+	Transaction string   `json:"transaction"` // 交易的规范编码
+	Header      string   `json:"header"`      // 区块头的规范编码
+	BlockHash   string   `json:"block_hash"`
+	Signature   string   `json:"signature"` // 区块提议者的签名
+	Round       uint64   `json:"round"`
+	LeafIndex   uint64   `json:"leaf_index"` // 交易在区块里面的序号
+	TreeSize    uint64   `json:"tree_size"`  // 区块的交易数量
+	Proof       []string `json:"proof"`      // 从叶子节点到根的兄弟节点
+}
+
+// getVerifiable 可验证的查询 /verifiable?table=&key=
+func getVerifiable(c *gin.Context) {
+	proof, err := Cserver.VerifiableGet(uid(c), c.Query("key"), c.Query("table"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	var hashes []string
+	for _, hash := range proof.Proof.Hashes {
+		hashes = append(hashes, hex.EncodeToString(hash))
+	}
+	c.JSON(200, VerifiableResult{
+		Value:       proof.Write.Value,
+		Transaction: hex.EncodeToString(proof.Transaction.Encode()),
+		Header:      hex.EncodeToString(proof.Header.EncodeHeader()),
+		BlockHash:   hex.EncodeToString(proof.Header.CurrentBlockHash),
+		Signature:   hex.EncodeToString(proof.Header.Signature),
+		Round:       proof.Header.Round,
+		LeafIndex:   proof.Proof.Index,
+		TreeSize:    proof.Proof.Size,
+		Proof:       hashes,
+	})
+}
+
+// ScanEntry 范围查询的一条数据
+type ScanEntry struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Possessor string `json:"possessor"`
+	TimeStamp int64  `json:"time_stamp"`
+	Round     uint64 `json:"round,omitempty"` // 数据所在区块的 Round (时间点查询)
+	Version   string `json:"version"`         // 数据的版本 (写入数据的交易的 ID), 用于条件写入
+	Type      string `json:"type"`            // 值的类型, bytes 的值为 0x 开头的十六进制
+}
+
+// newScanEntry 交易对应的一条数据
+func newScanEntry(tx *blockchain_data.Transaction, round uint64) ScanEntry {
+	return ScanEntry{
+		Key:       tx.Key,
+		Value:     server.FormatValue([]byte(tx.Value), tx.Type),
+		Possessor: tx.Possessor,
+		TimeStamp: tx.TimeStamp,
+		Round:     round,
+		Version:   hex.EncodeToString(tx.TxID),
+		Type:      tx.Type.String(),
+	}
+}
+
+// getScan 范围查询和前缀查询 /scan?table=&from=&to=&prefix=&limit=
+// 返回的 next 不为空时还有数据, 作为下一次查询的 from
+func getScan(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	txs, next, err := Cserver.Scan(uid(c), c.Query("table"), c.Query("from"), c.Query("to"), c.Query("prefix"), limit)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	entries := make([]ScanEntry, 0, len(txs))
+	for _, tx := range txs {
+		entries = append(entries, newScanEntry(&tx, 0))
+	}
+	c.JSON(200, gin.H{"entries": entries, "next": next})
+}
+
+// getAsOf 时间点查询 /asof?table=&key=&round= 或者 /asof?table=&key=&time=
+// time 为 Unix 秒或者 RFC3339, key 为空时查询整个表
+func getAsOf(c *gin.Context) {
+	at := c.Query("round")
+	if t := c.Query("time"); t != "" {
+		at = "@" + t
+	}
+	round, err := Cserver.ResolveAsOf(at)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	var values []cache.AsOfValue
+	if key := c.Query("key"); key != "" {
+		var value cache.AsOfValue
+		value, err = Cserver.GetAsOf(uid(c), key, c.Query("table"), round)
+		values = append(values, value)
+	} else {
+		values, err = Cserver.GetTableAsOf(uid(c), c.Query("table"), round)
+	}
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error(), "round": round})
+		return
+	}
+	entries := make([]ScanEntry, 0, len(values))
+	for _, value := range values {
+		tx := value.Transaction
+		entries = append(entries, newScanEntry(&tx, value.Round))
+	}
+	c.JSON(200, gin.H{"entries": entries, "round": round})
+}
+
+// putData 写入数据 PUT /data?table=&key=&type=, 请求体是原始的值, type 为 string (默认), bytes 或者 json。 返回交易的 ID
+func putData(c *gin.Context) {
+	typ, err := blockchain_data.ParseValueType(c.Query("type"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	value, err := c.GetRawData()
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	txID, err := Cserver.PutValue(uid(c), c.Query("key"), c.Query("table"), value, typ)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"tx_id": hex.EncodeToString(txID)})
+}
+
+// getData 查询数据 GET /data?table=&key=&path=, 返回原始的值, 值是 JSON 时可以用 path 取出子字段。
+// 数据的版本在响应头 X-Version 里面
+func getData(c *gin.Context) {
+	value, typ, tx, err := Cserver.GetValue(uid(c), c.Query("key"), c.Query("table"), c.Query("path"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("X-Version", hex.EncodeToString(tx.TxID))
+	switch typ {
+	case blockchain_data.TypeBytes:
+		c.Data(200, "application/octet-stream", value)
+	case blockchain_data.TypeJSON:
+		c.Data(200, "application/json; charset=utf-8", value)
+	default:
+		c.Data(200, "text/plain; charset=utf-8", value)
+	}
+}
+
+// getSchema 查看表的 schema GET /schema?table=, schema 为空时表没有 schema
+func getSchema(c *gin.Context) {
+	schema, version, err := Cserver.GetSchema(uid(c), c.Query("table"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	var raw json.RawMessage
+	if schema != "" {
+		raw = json.RawMessage(schema)
+	}
+	c.JSON(200, gin.H{"schema": raw, "version": version})
+}
+
+// putSchema 修改表的 schema PUT /schema?table=, 请求体是 schema 的 JSON, 为空时去掉表的 schema。 返回表交易的 ID
+func putSchema(c *gin.Context) {
+	schema, err := c.GetRawData()
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	txID, err := Cserver.SetSchema(uid(c), c.Query("table"), string(schema))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"tx_id": hex.EncodeToString(txID)})
+}
+
+// getFind 通过二级索引查找 /find?table=&field=&value=&limit=, value 对字符串字段是字符串的内容, 其他字段是 JSON
+func getFind(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	txs, err := Cserver.Find(uid(c), c.Query("table"), c.Query("field"), c.Query("value"), limit)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	entries := make([]ScanEntry, 0, len(txs))
+	for i := range txs {
+		entries = append(entries, newScanEntry(&txs[i], 0))
+	}
+	c.JSON(200, gin.H{"entries": entries})
+}
+
+// getIndexes 查看表的索引字段 GET /indexes?table=
+func getIndexes(c *gin.Context) {
+	fields, err := Cserver.GetIndexes(uid(c), c.Query("table"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"fields": fields})
+}
+
+// putIndexes 声明表的所有索引字段 PUT /indexes?table=, 请求体是字段的数组, 为空数组时去掉所有索引。 返回表交易的 ID
+func putIndexes(c *gin.Context) {
+	var fields []string
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	txID, err := Cserver.SetIndexes(uid(c), c.Query("table"), fields)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"tx_id": hex.EncodeToString(txID)})
+}
+
+// deleteData 删除数据 DELETE /data?table=&key=, 返回墓碑交易的 ID
+func deleteData(c *gin.Context) {
+	txID, err := Cserver.Delete(uid(c), c.Query("key"), c.Query("table"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"tx_id": hex.EncodeToString(txID)})
+}
+
+// putIf 条件写入 POST /putif?table=&key=&value=&expect=, expect 为版本的十六进制或者 absent。
+// 条件不成立时返回 409 和 key 当前的版本
+func putIf(c *gin.Context) {
+	cond, expect, err := server.ParseExpect(c.Query("expect"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	txID, err := Cserver.PutIf(uid(c), c.Query("key"), c.Query("value"), c.Query("table"), cond, expect)
+	if conflict, ok := err.(*cache.ConflictError); ok {
+		c.JSON(409, gin.H{"error": err.Error(), "conflict": true, "current_version": hex.EncodeToString(conflict.Current)})
+		return
+	}
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"tx_id": hex.EncodeToString(txID)})
+}
+
+// BatchWrite 批量写入的一个写入
+type BatchWrite struct {
+	Table  string `json:"table"`
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Delete bool   `json:"delete"`
+	Type   string `json:"type"` // string (默认), bytes (值为十六进制) 或者 json
+}
+
+// toWrites 把请求体里面的写入转换成批量交易的写入
+func toWrites(body []BatchWrite) ([]blockchain_data.Write, error) {
+	var writes []blockchain_data.Write
+	for _, w := range body {
+		typ, err := blockchain_data.ParseValueType(w.Type)
+		if err != nil {
+			return nil, err
+		}
+		write := blockchain_data.Write{Table: w.Table, Key: w.Key, Type: typ}
+		if w.Delete {
+			write.Op = blockchain_data.OpDelete
+		} else {
+			value, err := server.ParseValue(w.Value, typ)
+			if err != nil {
+				return nil, err
+			}
+			write.Value = string(value)
+		}
+		writes = append(writes, write)
+	}
+	return writes, nil
+}
+
+// postBatch 批量写入 POST /batch, 请求体是 BatchWrite 的数组, 返回批量交易的 ID
+func postBatch(c *gin.Context) {
+	var body []BatchWrite
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	writes, err := toWrites(body)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	txID, err := Cserver.Batch(uid(c), writes)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"tx_id": hex.EncodeToString(txID)})
+}
+
+// TxnRead 读写集交易的一个读取, version 为读取时 key 的版本的十六进制, key 不存在时为空
+type TxnRead struct {
+	Table   string `json:"table"`
+	Key     string `json:"key"`
+	Version string `json:"version"`
+}
+
+// Txn 读写集交易的请求体
+type Txn struct {
+	Reads  []TxnRead    `json:"reads"`
+	Writes []BatchWrite `json:"writes"`
+}
+
+// postTxn 读写集交易 POST /txn, 返回交易的 ID。 提交时读取的版本过期时交易无效, 用 /txstatus 查询
+func postTxn(c *gin.Context) {
+	var body Txn
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	var reads []blockchain_data.Read
+	for _, r := range body.Reads {
+		version, err := hex.DecodeString(r.Version)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "错误的版本 " + r.Version})
+			return
+		}
+		reads = append(reads, blockchain_data.Read{Table: r.Table, Key: r.Key, Version: version})
+	}
+	writes, err := toWrites(body.Writes)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	txID, err := Cserver.Transact(uid(c), reads, writes)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"tx_id": hex.EncodeToString(txID)})
+}
+
+// getTxStatus 数据交易的状态 /txstatus?txid=
+func getTxStatus(c *gin.Context) {
+	txID, err := hex.DecodeString(c.Query("txid"))
+	if err != nil || len(txID) == 0 {
+		c.JSON(400, gin.H{"error": "错误的交易 ID"})
+		return
+	}
+	status, reason, round := Cserver.TxStatus(txID)
+	c.JSON(200, gin.H{"status": status, "reason": reason, "round": round})
+}
+
+// getCacheStats 缓存的统计 /admin/cache, 需要管理员权限
+func getCacheStats(c *gin.Context) {
+	if !Cserver.IsAdmin(uid(c)) {
+		c.JSON(403, gin.H{"error": "需要管理员权限"})
+		return
+	}
+	c.JSON(200, Cserver.Cache.Stats())
+}
+
+// setCache 修改缓存队列的容量或者淘汰策略 POST /admin/cache?tx=&index=&policy=, 需要管理员权限
+func setCache(c *gin.Context) {
+	if !Cserver.IsAdmin(uid(c)) {
+		c.JSON(403, gin.H{"error": "需要管理员权限"})
+		return
+	}
+	if name := c.Query("policy"); name != "" {
+		if err := Cserver.SetCachePolicy(uid(c), name); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if c.Query("tx") == "" && c.Query("index") == "" {
+			c.JSON(200, Cserver.Cache.Stats())
+			return
+		}
+	}
+	txSize, err1 := strconv.Atoi(c.Query("tx"))
+	indexSize, err2 := strconv.Atoi(c.Query("index"))
+	if err1 != nil || err2 != nil {
+		c.JSON(400, gin.H{"error": "tx 和 index 必须是整数"})
+		return
+	}
+	if err := Cserver.SetCacheSize(uid(c), txSize, indexSize); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, Cserver.Cache.Stats())
+}
+
 func StartClient() {
 	r := gin.Default()
 	r.GET("/aircondition", getAData)
 	r.GET("/refrigerator", getRData)
-	r.Run(":8080")
+	r.GET("/verifiable", getVerifiable)
+	r.GET("/scan", getScan)
+	r.GET("/asof", getAsOf)
+	r.GET("/data", getData)
+	r.PUT("/data", putData)
+	r.DELETE("/data", deleteData)
+	r.GET("/schema", getSchema)
+	r.PUT("/schema", putSchema)
+	r.GET("/find", getFind)
+	r.GET("/indexes", getIndexes)
+	r.PUT("/indexes", putIndexes)
+	r.POST("/batch", postBatch)
+	r.POST("/putif", putIf)
+	r.POST("/txn", postTxn)
+	r.GET("/txstatus", getTxStatus)
+	r.GET("/admin/cache", getCacheStats)
+	r.POST("/admin/cache", setCache)
+	r.Run(config.LocalConfig.ListenAddr(config.LocalConfig.Ports.HTTP))
 }
@@ -0,0 +1,248 @@
+package common
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// 区块和交易的规范二进制编码, 用于 交易ID, 签名, 默克尔树叶子节点, 区块HASH。
+// 格式: 版本(1 byte), 然后按照固定的顺序写入所有字段(包括零值字段)。
+// 每个字段为 tag(1 byte) + 长度(uvarint) + 内容。
+// 整数为 8 字节大端序; 字符串和字节数组直接写入内容;
+// 字符串数组先写入元素个数(8 字节大端序), 然后每个元素都是一个同 tag 的字段。
+// 同样的数据只有一种编码, 字段之间不会因为拼接产生歧义。
+//
+// 第一个版本之后加入的字段都是可选字段 (PutOptional...), 写在所有必选字段的后面, tag 从小到大;
+// 值为零值时不写入, 解码时没有这个字段就是零值。 这样没有使用新字段的数据编码不变 (交易ID 和签名不变),
+// 旧的编码仍然可以解码, 不需要修改 EncodingVersion
+
+// EncodingVersion 当前的编码版本
+const EncodingVersion byte = 1
+
+// Encoder 规范编码器
+type Encoder struct {
+	buf []byte
+}
+
+// NewEncoder 创建编码器并写入版本
+func NewEncoder() *Encoder {
+	return &Encoder{buf: []byte{EncodingVersion}}
+}
+
+func (e *Encoder) putField(tag byte, value []byte) {
+	e.buf = append(e.buf, tag)
+	e.buf = append(e.buf, uvarint(uint64(len(value)))...)
+	e.buf = append(e.buf, value...)
+}
+
+func uvarint(v uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return buf[:binary.PutUvarint(buf, v)]
+}
+
+func (e *Encoder) PutBytes(tag byte, value []byte) *Encoder {
+	e.putField(tag, value)
+	return e
+}
+
+func (e *Encoder) PutString(tag byte, value string) *Encoder {
+	e.putField(tag, []byte(value))
+	return e
+}
+
+func (e *Encoder) PutUint64(tag byte, value uint64) *Encoder {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, value)
+	e.putField(tag, buf)
+	return e
+}
+
+func (e *Encoder) PutInt64(tag byte, value int64) *Encoder {
+	return e.PutUint64(tag, uint64(value))
+}
+
+func (e *Encoder) PutStrings(tag byte, values []string) *Encoder {
+	e.PutUint64(tag, uint64(len(values)))
+	for _, v := range values {
+		e.PutString(tag, v)
+	}
+	return e
+}
+
+// PutOptionalBytes 可选字段, 值为空时不写入
+func (e *Encoder) PutOptionalBytes(tag byte, value []byte) *Encoder {
+	if len(value) != 0 {
+		e.putField(tag, value)
+	}
+	return e
+}
+
+// PutOptionalString 可选字段, 值为空时不写入
+func (e *Encoder) PutOptionalString(tag byte, value string) *Encoder {
+	return e.PutOptionalBytes(tag, []byte(value))
+}
+
+// PutOptionalUint64 可选字段, 值为 0 时不写入
+func (e *Encoder) PutOptionalUint64(tag byte, value uint64) *Encoder {
+	if value != 0 {
+		e.PutUint64(tag, value)
+	}
+	return e
+}
+
+// PutOptionalStrings 可选字段, 数组为空时不写入
+func (e *Encoder) PutOptionalStrings(tag byte, values []string) *Encoder {
+	if len(values) != 0 {
+		e.PutStrings(tag, values)
+	}
+	return e
+}
+
+// Encoded 返回编码后的字节
+func (e *Encoder) Encoded() []byte {
+	return e.buf
+}
+
+// Decoder 规范解码器, 字段要按照编码时的顺序读取
+type Decoder struct {
+	data []byte
+	err  error
+}
+
+// NewDecoder 创建解码器并检查版本
+func NewDecoder(data []byte) *Decoder {
+	d := &Decoder{}
+	if len(data) == 0 {
+		d.err = errors.New("empty encoding")
+		return d
+	}
+	if data[0] != EncodingVersion {
+		d.err = fmt.Errorf("unsupported encoding version %d", data[0])
+		return d
+	}
+	d.data = data[1:]
+	return d
+}
+
+func (d *Decoder) field(tag byte) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) == 0 {
+		d.err = fmt.Errorf("field %d: unexpected end", tag)
+		return nil
+	}
+	if d.data[0] != tag {
+		d.err = fmt.Errorf("field %d: got tag %d", tag, d.data[0])
+		return nil
+	}
+	length, n := binary.Uvarint(d.data[1:])
+	// 长度必须是最短的 uvarint 编码
+	if n <= 0 || n != len(uvarint(length)) || length > uint64(len(d.data)-1-n) {
+		d.err = fmt.Errorf("field %d: bad length", tag)
+		return nil
+	}
+	start := 1 + n
+	value := d.data[start : start+int(length)]
+	d.data = d.data[start+int(length):]
+	return value
+}
+
+func (d *Decoder) Bytes(tag byte) []byte {
+	value := d.field(tag)
+	if len(value) == 0 {
+		return nil
+	}
+	return CopyBytes(value)
+}
+
+func (d *Decoder) String(tag byte) string {
+	return string(d.field(tag))
+}
+
+func (d *Decoder) Uint64(tag byte) uint64 {
+	value := d.field(tag)
+	if d.err != nil {
+		return 0
+	}
+	if len(value) != 8 {
+		d.err = fmt.Errorf("field %d: bad integer", tag)
+		return 0
+	}
+	return binary.BigEndian.Uint64(value)
+}
+
+func (d *Decoder) Int64(tag byte) int64 {
+	return int64(d.Uint64(tag))
+}
+
+func (d *Decoder) Strings(tag byte) []string {
+	count := d.Uint64(tag)
+	if d.err != nil {
+		return nil
+	}
+	if count > uint64(len(d.data)) {
+		d.err = fmt.Errorf("field %d: bad count", tag)
+		return nil
+	}
+	var values []string
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		values = append(values, d.String(tag))
+	}
+	return values
+}
+
+// has 下一个字段是否是 tag, 用于可选字段
+func (d *Decoder) has(tag byte) bool {
+	return d.err == nil && len(d.data) != 0 && d.data[0] == tag
+}
+
+// OptionalBytes 可选字段, 没有时为 nil。 写入了空值的编码不是规范的编码
+func (d *Decoder) OptionalBytes(tag byte) []byte {
+	if !d.has(tag) {
+		return nil
+	}
+	value := d.Bytes(tag)
+	if d.err == nil && len(value) == 0 {
+		d.err = fmt.Errorf("field %d: empty optional field", tag)
+	}
+	return value
+}
+
+// OptionalString 可选字段, 没有时为空字符串
+func (d *Decoder) OptionalString(tag byte) string {
+	return string(d.OptionalBytes(tag))
+}
+
+// OptionalUint64 可选字段, 没有时为 0
+func (d *Decoder) OptionalUint64(tag byte) uint64 {
+	if !d.has(tag) {
+		return 0
+	}
+	value := d.Uint64(tag)
+	if d.err == nil && value == 0 {
+		d.err = fmt.Errorf("field %d: empty optional field", tag)
+	}
+	return value
+}
+
+// OptionalStrings 可选字段, 没有时为 nil
+func (d *Decoder) OptionalStrings(tag byte) []string {
+	if !d.has(tag) {
+		return nil
+	}
+	values := d.Strings(tag)
+	if d.err == nil && len(values) == 0 {
+		d.err = fmt.Errorf("field %d: empty optional field", tag)
+	}
+	return values
+}
+
+// Finish 结束解码, 返回解码过程中的错误。编码后面不能有多余的字节
+func (d *Decoder) Finish() error {
+	if d.err == nil && len(d.data) != 0 {
+		d.err = errors.New("trailing bytes")
+	}
+	return d.err
+}
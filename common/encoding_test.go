@@ -0,0 +1,140 @@
+package common
+
+import (
+	"bytes"
+	"testing"
+)
+
+const (
+	tagTable byte = iota + 1
+	tagKey
+	tagRound
+	tagList
+	tagExtra
+	tagMore
+)
+
+type record struct {
+	Table string
+	Key   string
+	Round uint64
+	List  []string
+	Extra []byte // 可选
+	More  uint64 // 可选
+}
+
+func (r *record) encode() []byte {
+	return NewEncoder().
+		PutString(tagTable, r.Table).
+		PutString(tagKey, r.Key).
+		PutUint64(tagRound, r.Round).
+		PutStrings(tagList, r.List).
+		PutOptionalBytes(tagExtra, r.Extra).
+		PutOptionalUint64(tagMore, r.More).
+		Encoded()
+}
+
+func decodeRecord(data []byte) (*record, error) {
+	d := NewDecoder(data)
+	r := &record{
+		Table: d.String(tagTable),
+		Key:   d.String(tagKey),
+		Round: d.Uint64(tagRound),
+		List:  d.Strings(tagList),
+		Extra: d.OptionalBytes(tagExtra),
+		More:  d.OptionalUint64(tagMore),
+	}
+	return r, d.Finish()
+}
+
+// field 手工拼接一个字段
+func field(tag byte, value ...byte) []byte {
+	return append([]byte{tag, byte(len(value))}, value...)
+}
+
+func join(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+var round1 = []byte{0, 0, 0, 0, 0, 0, 0, 1}
+var count0 = []byte{0, 0, 0, 0, 0, 0, 0, 0}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	tests := []record{
+		{},
+		{Table: "t", Key: "k", Round: 7},
+		{Table: "t", Key: "k", List: []string{"a", "", "b"}},
+		{Table: "t", Key: "k", Extra: []byte{0}, More: 1 << 40},
+	}
+	for _, want := range tests {
+		got, err := decodeRecord(want.encode())
+		if err != nil {
+			t.Errorf("%+v: %v", want, err)
+			continue
+		}
+		if got.Table != want.Table || got.Key != want.Key || got.Round != want.Round ||
+			len(got.List) != len(want.List) || !bytes.Equal(got.Extra, want.Extra) || got.More != want.More {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestEncodingNoCollision(t *testing.T) {
+	a := (&record{Table: "ab", Key: "c"}).encode()
+	b := (&record{Table: "a", Key: "bc"}).encode()
+	if bytes.Equal(a, b) {
+		t.Fatal("不同的字段拼接出相同的编码")
+	}
+	for _, test := range []struct {
+		data       []byte
+		table, key string
+	}{{a, "ab", "c"}, {b, "a", "bc"}} {
+		r, err := decodeRecord(test.data)
+		if err != nil || r.Table != test.table || r.Key != test.key {
+			t.Errorf("decode = %+v, %v", r, err)
+		}
+	}
+}
+
+func TestDecoderRejects(t *testing.T) {
+	valid := join([]byte{EncodingVersion}, field(tagTable, 'a', 'b'), field(tagKey, 'c'), field(tagRound, round1...), field(tagList, count0...))
+	if _, err := decodeRecord(valid); err != nil {
+		t.Fatalf("手工拼接的编码: %v", err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"空的编码", nil},
+		{"错误的版本", join([]byte{EncodingVersion + 1}, valid[1:])},
+		{"字段顺序交换", join([]byte{EncodingVersion}, field(tagKey, 'c'), field(tagTable, 'a', 'b'), field(tagRound, round1...), field(tagList, count0...))},
+		{"缺少字段", join([]byte{EncodingVersion}, field(tagTable, 'a', 'b'), field(tagRound, round1...), field(tagList, count0...))},
+		{"长度不是最短的 uvarint", join([]byte{EncodingVersion, tagTable, 0x82, 0x00, 'a', 'b'}, valid[4:])},
+		{"长度超出末尾", join([]byte{EncodingVersion}, field(tagTable, 'a', 'b'), []byte{tagKey, 5, 'c'})},
+		{"整数不是 8 字节", join([]byte{EncodingVersion}, field(tagTable, 'a', 'b'), field(tagKey, 'c'), field(tagRound, 1), field(tagList, count0...))},
+		{"数组个数超出末尾", join([]byte{EncodingVersion}, field(tagTable, 'a', 'b'), field(tagKey, 'c'), field(tagRound, round1...), field(tagList, round1...))},
+		{"多余的字节", join(valid, []byte{0})},
+		{"可选字段为空值", join(valid, field(tagExtra))},
+		{"可选整数为 0", join(valid, field(tagMore, count0...))},
+		{"可选字段顺序交换", join(valid, field(tagMore, round1...), field(tagExtra, 'x'))},
+		{"可选字段重复", join(valid, field(tagExtra, 'x'), field(tagExtra, 'y'))},
+		{"未知的字段", join(valid, field(tagMore+1, 'x'))},
+	}
+	for _, test := range tests {
+		if _, err := decodeRecord(test.data); err == nil {
+			t.Errorf("%s: 没有返回 err", test.name)
+		}
+	}
+	// 截断在必选字段里面的编码都不能解码; 截断在可选字段之间时是没有后面可选字段的规范编码
+	required := (&record{Table: "ab", Key: "c", Round: 1, List: []string{"x"}}).encode()
+	full := (&record{Table: "ab", Key: "c", Round: 1, List: []string{"x"}, Extra: []byte("e"), More: 2}).encode()
+	for n := 0; n < len(full); n++ {
+		r, err := decodeRecord(full[:n])
+		if err != nil {
+			continue
+		}
+		if n < len(required) || !bytes.Equal(r.encode(), full[:n]) || r.More != 0 {
+			t.Errorf("截断到 %d 字节: 解码为 %+v", n, r)
+		}
+	}
+}
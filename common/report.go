@@ -0,0 +1,53 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BlockIssue 审计区块链时发现的一个问题
+type BlockIssue struct {
+	Round  uint64 // 区块的 Round (表区块为 ID)
+	Hash   []byte // 区块的 HASH
+	Kind   string // 问题的类型: index, link, hash, body, merkle, transaction, signature, tail
+	Detail string
+}
+
+// ChainReport 一条区块链从创世区块到链尾的审计结果
+type ChainReport struct {
+	Chain       string // 区块链的名字 data / table
+	Blocks      uint64 // 检查过的区块数量
+	FirstBroken int64  // 第一个出错区块的 Round, 没有错误时为 -1
+	Issues      []BlockIssue
+}
+
+// NewChainReport _NewChainReport
+func NewChainReport(chain string) *ChainReport {
+	return &ChainReport{Chain: chain, FirstBroken: -1}
+}
+
+// AddIssue 记录一个问题, 同时更新第一个出错的区块
+func (r *ChainReport) AddIssue(round uint64, hash []byte, kind, detail string) {
+	r.Issues = append(r.Issues, BlockIssue{Round: round, Hash: CopyBytes(hash), Kind: kind, Detail: detail})
+	if r.FirstBroken < 0 || int64(round) < r.FirstBroken {
+		r.FirstBroken = int64(round)
+	}
+}
+
+// OK 区块链没有问题
+func (r *ChainReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+func (r *ChainReport) String() string {
+	var sb strings.Builder
+	if r.OK() {
+		fmt.Fprintf(&sb, "[%s] 共检查 %d 个区块, 没有发现问题\n", r.Chain, r.Blocks)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "[%s] 共检查 %d 个区块, 发现 %d 个问题, 第一个出错的区块 #%d\n", r.Chain, r.Blocks, len(r.Issues), r.FirstBroken)
+	for _, issue := range r.Issues {
+		fmt.Fprintf(&sb, "  #%d %x %s: %s\n", issue.Round, issue.Hash, issue.Kind, issue.Detail)
+	}
+	return sb.String()
+}
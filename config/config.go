@@ -0,0 +1,294 @@
+package config
+
+import (
+	"alg_bcDB/util"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 节点的配置。 先读取配置文件(YAML), 再用命令行参数覆盖。
+// 每个节点使用不同的端口和数据目录, 就可以在一台机器上通过回环地址运行多个节点
+
+// Config 节点的配置
+type Config struct {
+	BindIP      string    `yaml:"bind_ip"`      // 监听的地址, 为空时监听所有地址
+	AdvertiseIP string    `yaml:"advertise_ip"` // 其他节点访问本节点的地址, 为空时自动获取
+	DataDir     string    `yaml:"data_dir"`     // 区块链, 用户文件, 集群文件所在的目录
+	Genesis     string    `yaml:"genesis"`      // 创世文件的路径, 为空时使用原来的创世区块
+	Ports       Ports     `yaml:"ports"`
+	Consensus   Consensus `yaml:"consensus"`
+	Cache       Cache     `yaml:"cache"`
+	Admins      []string  `yaml:"admins"` // 管理员地址, 没有创世文件时使用
+	Light       string    `yaml:"light"`  // 轻节点同步区块头的全节点地址 ip:port (GRPC 端口), 为空时以全节点运行
+}
+
+// Ports 节点使用的端口
+type Ports struct {
+	Grpc     int `yaml:"grpc"`     // 节点之间的 GRPC 服务
+	Raft     int `yaml:"raft"`     // Raft 的 RPC 服务
+	Cluster  int `yaml:"cluster"`  // 集群文件的发送
+	Algorand int `yaml:"algorand"` // Algorand 的节点标识
+	Client   int `yaml:"client"`   // 客户端的 GRPC 服务
+	HTTP     int `yaml:"http"`     // gin 的 HTTP 服务
+}
+
+// Consensus 共识的超时时间
+type Consensus struct {
+	ElectionTimeout  time.Duration `yaml:"election_timeout"`  // Raft 选举超时
+	HeartbeatTimeout time.Duration `yaml:"heartbeat_timeout"` // Raft 心跳超时
+	HeartbeatRate    time.Duration `yaml:"heartbeat_rate"`    // Raft 心跳间隔
+	LamdaPriority    time.Duration `yaml:"lamda_priority"`    // Algorand 广播区块提议的时间
+	LamdaBlock       time.Duration `yaml:"lamda_block"`       // Algorand 接收区块的超时
+	LamdaStep        time.Duration `yaml:"lamda_step"`        // Algorand BA* 每一步的超时
+	LamdaStepvar     time.Duration `yaml:"lamda_stepvar"`     // Algorand BA* 完成时间的方差
+}
+
+// Cache 查询缓存的淘汰策略和容量, 运行时可以用 cachepolicy, cachesize 命令修改。
+// 淘汰策略的名字由 cache.CheckPolicy 检查
+type Cache struct {
+	Policy    string `yaml:"policy"`     // 淘汰策略: lru, lruk, arc, tinylfu
+	TxSize    int    `yaml:"tx_size"`    // 交易队列(保存交易)的容量
+	IndexSize int    `yaml:"index_size"` // 索引队列(保存交易的位置)的容量
+}
+
+// LocalConfig 本节点的配置(全局), 在启动时由 Apply 设置
+var LocalConfig = Default()
+
+// Default 默认配置, 和原来写死的端口与路径一致
+func Default() *Config {
+	return &Config{
+		DataDir: ".",
+		Ports: Ports{
+			Grpc:     3301,
+			Raft:     3302,
+			Cluster:  3303,
+			Algorand: 3304,
+			Client:   8888,
+			HTTP:     8080,
+		},
+		Consensus: Consensus{
+			ElectionTimeout:  3 * time.Second,
+			HeartbeatTimeout: 4 * time.Second,
+			HeartbeatRate:    2 * time.Second,
+			LamdaPriority:    util.LamdaPriority,
+			LamdaBlock:       util.LamdaBlock,
+			LamdaStep:        util.LamdaStep,
+			LamdaStepvar:     util.LamdaStepvar,
+		},
+		Cache: Cache{
+			Policy:    "lru",
+			TxSize:    120,
+			IndexSize: 300,
+		},
+	}
+}
+
+// Load 读取配置文件, 文件里没有的配置使用默认值
+func Load(path string) (*Config, error) {
+	c := Default()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(content, c); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败; %v", path, err)
+	}
+	return c, nil
+}
+
+// Parse 解析命令行参数。 -config 指定配置文件, 其他参数覆盖配置文件里面的值
+func Parse(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("alg_bcDB", flag.ContinueOnError)
+	path := fs.String("config", "", "配置文件的路径")
+	f := Default()
+	fs.StringVar(&f.BindIP, "bind", f.BindIP, "监听的地址")
+	fs.StringVar(&f.AdvertiseIP, "advertise", f.AdvertiseIP, "其他节点访问本节点的地址")
+	fs.StringVar(&f.DataDir, "datadir", f.DataDir, "数据目录")
+	fs.StringVar(&f.Genesis, "genesis", f.Genesis, "创世文件")
+	fs.IntVar(&f.Ports.Grpc, "grpc-port", f.Ports.Grpc, "节点之间的 GRPC 端口")
+	fs.IntVar(&f.Ports.Raft, "raft-port", f.Ports.Raft, "Raft 端口")
+	fs.IntVar(&f.Ports.Cluster, "cluster-port", f.Ports.Cluster, "集群文件端口")
+	fs.IntVar(&f.Ports.Algorand, "algorand-port", f.Ports.Algorand, "Algorand 节点标识端口")
+	fs.IntVar(&f.Ports.Client, "client-port", f.Ports.Client, "客户端 GRPC 端口")
+	fs.IntVar(&f.Ports.HTTP, "http-port", f.Ports.HTTP, "HTTP 端口")
+	fs.DurationVar(&f.Consensus.ElectionTimeout, "election-timeout", f.Consensus.ElectionTimeout, "Raft 选举超时")
+	fs.DurationVar(&f.Consensus.HeartbeatTimeout, "heartbeat-timeout", f.Consensus.HeartbeatTimeout, "Raft 心跳超时")
+	fs.DurationVar(&f.Consensus.HeartbeatRate, "heartbeat-rate", f.Consensus.HeartbeatRate, "Raft 心跳间隔")
+	fs.DurationVar(&f.Consensus.LamdaPriority, "lamda-priority", f.Consensus.LamdaPriority, "Algorand 广播区块提议的时间")
+	fs.DurationVar(&f.Consensus.LamdaBlock, "lamda-block", f.Consensus.LamdaBlock, "Algorand 接收区块的超时")
+	fs.DurationVar(&f.Consensus.LamdaStep, "lamda-step", f.Consensus.LamdaStep, "Algorand BA* 每一步的超时")
+	fs.DurationVar(&f.Consensus.LamdaStepvar, "lamda-stepvar", f.Consensus.LamdaStepvar, "Algorand BA* 完成时间的方差")
+	fs.StringVar(&f.Cache.Policy, "cache-policy", f.Cache.Policy, "缓存的淘汰策略")
+	fs.IntVar(&f.Cache.TxSize, "cache-tx-size", f.Cache.TxSize, "缓存交易队列的容量")
+	fs.IntVar(&f.Cache.IndexSize, "cache-index-size", f.Cache.IndexSize, "缓存索引队列的容量")
+	admins := fs.String("admins", "", "管理员地址, 用逗号分隔, 没有创世文件时使用")
+	fs.StringVar(&f.Light, "light", f.Light, "以轻节点运行, 同步区块头的全节点地址 ip:port")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	c := Default()
+	if *path != "" {
+		var err error
+		if c, err = Load(*path); err != nil {
+			return nil, err
+		}
+	}
+	// 只覆盖命令行里面出现的参数
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "bind":
+			c.BindIP = f.BindIP
+		case "advertise":
+			c.AdvertiseIP = f.AdvertiseIP
+		case "datadir":
+			c.DataDir = f.DataDir
+		case "genesis":
+			c.Genesis = f.Genesis
+		case "grpc-port":
+			c.Ports.Grpc = f.Ports.Grpc
+		case "raft-port":
+			c.Ports.Raft = f.Ports.Raft
+		case "cluster-port":
+			c.Ports.Cluster = f.Ports.Cluster
+		case "algorand-port":
+			c.Ports.Algorand = f.Ports.Algorand
+		case "client-port":
+			c.Ports.Client = f.Ports.Client
+		case "http-port":
+			c.Ports.HTTP = f.Ports.HTTP
+		case "election-timeout":
+			c.Consensus.ElectionTimeout = f.Consensus.ElectionTimeout
+		case "heartbeat-timeout":
+			c.Consensus.HeartbeatTimeout = f.Consensus.HeartbeatTimeout
+		case "heartbeat-rate":
+			c.Consensus.HeartbeatRate = f.Consensus.HeartbeatRate
+		case "lamda-priority":
+			c.Consensus.LamdaPriority = f.Consensus.LamdaPriority
+		case "lamda-block":
+			c.Consensus.LamdaBlock = f.Consensus.LamdaBlock
+		case "lamda-step":
+			c.Consensus.LamdaStep = f.Consensus.LamdaStep
+		case "lamda-stepvar":
+			c.Consensus.LamdaStepvar = f.Consensus.LamdaStepvar
+		case "cache-policy":
+			c.Cache.Policy = f.Cache.Policy
+		case "cache-tx-size":
+			c.Cache.TxSize = f.Cache.TxSize
+		case "cache-index-size":
+			c.Cache.IndexSize = f.Cache.IndexSize
+		case "light":
+			c.Light = f.Light
+		case "admins":
+			c.Admins = nil
+			for _, admin := range strings.Split(*admins, ",") {
+				if admin = strings.TrimSpace(admin); admin != "" {
+					c.Admins = append(c.Admins, admin)
+				}
+			}
+		}
+	})
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate 检查配置, 端口不能重复
+func (c *Config) Validate() error {
+	if c.BindIP != "" && net.ParseIP(c.BindIP) == nil {
+		return fmt.Errorf("监听地址 %s 错误", c.BindIP)
+	}
+	if c.DataDir == "" {
+		return errors.New("数据目录不能为空")
+	}
+	if c.Light != "" {
+		if _, _, err := c.LightPeer(); err != nil {
+			return err
+		}
+	}
+	ports := map[string]int{
+		"grpc": c.Ports.Grpc, "raft": c.Ports.Raft, "cluster": c.Ports.Cluster,
+		"algorand": c.Ports.Algorand, "client": c.Ports.Client, "http": c.Ports.HTTP,
+	}
+	used := make(map[int]string)
+	for name, port := range ports {
+		if port <= 0 || port > 65535 {
+			return fmt.Errorf("%s 端口 %d 错误", name, port)
+		}
+		if other, has := used[port]; has {
+			return fmt.Errorf("%s 端口和 %s 端口都是 %d", name, other, port)
+		}
+		used[port] = name
+	}
+	t := c.Consensus
+	for _, d := range []time.Duration{t.ElectionTimeout, t.HeartbeatTimeout, t.HeartbeatRate, t.LamdaPriority, t.LamdaBlock, t.LamdaStep, t.LamdaStepvar} {
+		if d <= 0 {
+			return errors.New("共识的超时时间必须大于 0")
+		}
+	}
+	if c.Cache.TxSize <= 0 || c.Cache.IndexSize <= 0 {
+		return errors.New("缓存的容量必须大于 0")
+	}
+	return nil
+}
+
+// LightPeer 轻节点同步区块头的全节点的地址和端口
+func (c *Config) LightPeer() (string, int, error) {
+	host, portStr, err := net.SplitHostPort(c.Light)
+	if err != nil {
+		return "", 0, fmt.Errorf("轻节点的全节点地址 %s 错误; %v", c.Light, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, fmt.Errorf("轻节点的全节点端口 %s 错误", portStr)
+	}
+	return host, port, nil
+}
+
+// IsAdmin 判断地址是不是配置里面的管理员
+func (c *Config) IsAdmin(address string) bool {
+	for _, admin := range c.Admins {
+		if admin == address {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply 把配置设置为本节点的配置, 并创建数据目录
+func (c *Config) Apply() error {
+	if err := os.MkdirAll(c.DataDir, 0755); err != nil {
+		return err
+	}
+	util.LocalIP = c.AdvertiseIP
+	if util.LocalIP == "" {
+		util.LocalIP = util.GetLocalIp()
+	}
+	util.LocalPort = c.Ports.Grpc
+	util.LamdaPriority = c.Consensus.LamdaPriority
+	util.LamdaBlock = c.Consensus.LamdaBlock
+	util.LamdaStep = c.Consensus.LamdaStep
+	util.LamdaStepvar = c.Consensus.LamdaStepvar
+	LocalConfig = c
+	return nil
+}
+
+// Path 数据目录下的文件路径
+func (c *Config) Path(name string) string {
+	return filepath.Join(c.DataDir, name)
+}
+
+// ListenAddr 监听地址
+func (c *Config) ListenAddr(port int) string {
+	return net.JoinHostPort(c.BindIP, strconv.Itoa(port))
+}
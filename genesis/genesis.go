@@ -0,0 +1,174 @@
+package genesis
+
+import (
+	"alg_bcDB/common"
+	"alg_bcDB/util"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 创世文件, 定义网络的 ID, 初始的共享表与权限, 管理员地址, Algorand 的参数。
+// 两条区块链的创世区块都由创世文件生成, 创世文件不同的网络不能加入同一个集群。
+// 没有创世文件时使用原来的创世区块
+
+// Genesis 创世文件
+type Genesis struct {
+	ChainID   string   `yaml:"chain_id"`  // 网络的 ID, 例如 staging, production
+	TimeStamp uint64   `yaml:"timestamp"` // 创世区块的时间戳
+	Admins    []string `yaml:"admins"`    // 初始的管理员地址
+	Tables    []Table  `yaml:"tables"`    // 初始的共享表
+	Algorand  Algorand `yaml:"algorand"`
+}
+
+// Table 初始的共享表
+type Table struct {
+	Name        string   `yaml:"name"`
+	Possessor   string   `yaml:"possessor"`
+	Permissions []string `yaml:"permissions"` // 用户地址+权限 或者 用户地址:角色, 和 table 命令的格式一样
+}
+
+// Algorand Algorand 的参数, 对应 util/parameter.go
+type Algorand struct {
+	UserAmount                    uint64  `yaml:"user_amount"`
+	TokenPerUser                  uint64  `yaml:"token_per_user"`
+	ExpectedBlockProposers        int     `yaml:"expected_block_proposers"`
+	ExpectedCommitteeMembers      int     `yaml:"expected_committee_members"`
+	ThresholdOfBAStep             float64 `yaml:"threshold_of_ba_step"`
+	ExpectedFinalCommitteeMembers int     `yaml:"expected_final_committee_members"`
+	FinalThreshold                float64 `yaml:"final_threshold"`
+	MaxSteps                      int     `yaml:"max_steps"`
+	SeedRefreshInterval           uint64  `yaml:"seed_refresh_interval"` // seed 刷新间隔 R
+}
+
+// LocalGenesis 本节点使用的创世文件, 为 nil 时使用原来的创世区块
+var LocalGenesis *Genesis
+
+// Load 读取创世文件, 文件里没有的 Algorand 参数使用默认值
+func Load(path string) (*Genesis, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	g := &Genesis{Algorand: Algorand{
+		UserAmount:                    util.UserAmount,
+		TokenPerUser:                  util.TokenPerUser,
+		ExpectedBlockProposers:        util.ExpectedBlockProposers,
+		ExpectedCommitteeMembers:      util.ExpectedCommitteeMembers,
+		ThresholdOfBAStep:             util.ThresholdOfBAStep,
+		ExpectedFinalCommitteeMembers: util.ExpectedFinalCommitteeMembers,
+		FinalThreshold:                util.FinalThreshold,
+		MaxSteps:                      util.MAXSTEPS,
+		SeedRefreshInterval:           util.R,
+	}}
+	if err := yaml.Unmarshal(content, g); err != nil {
+		return nil, fmt.Errorf("解析创世文件 %s 失败; %v", path, err)
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Validate 检查创世文件
+func (g *Genesis) Validate() error {
+	if g.ChainID == "" {
+		return errors.New("创世文件没有 chain_id")
+	}
+	names := make(map[string]bool)
+	for _, table := range g.Tables {
+		if table.Name == "" {
+			return errors.New("共享表的名字不能为空")
+		}
+		// 和 blockchain_table.CheckTableName 一样, 表名里面不能有数据ID 的分隔符
+		if strings.Contains(table.Name, "-QAQ-") {
+			return fmt.Errorf("共享表 %s 的名字里面不能有 -QAQ-", table.Name)
+		}
+		if names[table.Name] {
+			return fmt.Errorf("共享表 %s 重复", table.Name)
+		}
+		names[table.Name] = true
+		if len(table.Permissions) == 0 {
+			return fmt.Errorf("共享表 %s 没有权限", table.Name)
+		}
+		for _, p := range table.Permissions {
+			if len(p) < 2 {
+				return fmt.Errorf("共享表 %s 的权限 %s 错误", table.Name, p)
+			}
+		}
+	}
+	a := g.Algorand
+	if a.UserAmount == 0 || a.TokenPerUser == 0 || a.ExpectedBlockProposers <= 0 || a.ExpectedCommitteeMembers <= 0 ||
+		a.ExpectedFinalCommitteeMembers <= 0 || a.MaxSteps <= 0 || a.SeedRefreshInterval == 0 {
+		return errors.New("Algorand 的参数必须大于 0")
+	}
+	if a.ThresholdOfBAStep <= 0 || a.ThresholdOfBAStep > 1 || a.FinalThreshold <= 0 || a.FinalThreshold > 1 {
+		return errors.New("Algorand 的阈值必须在 (0, 1] 之间")
+	}
+	return nil
+}
+
+// Apply 使用创世文件, 设置 Algorand 的参数
+func (g *Genesis) Apply() {
+	a := g.Algorand
+	util.UserAmount = a.UserAmount
+	util.TokenPerUser = a.TokenPerUser
+	util.ExpectedBlockProposers = a.ExpectedBlockProposers
+	util.ExpectedCommitteeMembers = a.ExpectedCommitteeMembers
+	util.ThresholdOfBAStep = a.ThresholdOfBAStep
+	util.ExpectedFinalCommitteeMembers = a.ExpectedFinalCommitteeMembers
+	util.FinalThreshold = a.FinalThreshold
+	util.MAXSTEPS = a.MaxSteps
+	util.R = a.SeedRefreshInterval
+	LocalGenesis = g
+}
+
+// Encode 创世文件的规范编码
+func (g *Genesis) Encode() []byte {
+	e := common.NewEncoder().
+		PutString(1, g.ChainID).
+		PutUint64(2, g.TimeStamp).
+		PutStrings(3, g.Admins).
+		PutUint64(4, uint64(len(g.Tables)))
+	for _, table := range g.Tables {
+		e.PutBytes(4, common.NewEncoder().
+			PutString(1, table.Name).
+			PutString(2, table.Possessor).
+			PutStrings(3, table.Permissions).
+			Encoded())
+	}
+	a := g.Algorand
+	return e.PutBytes(5, common.NewEncoder().
+		PutUint64(1, a.UserAmount).
+		PutUint64(2, a.TokenPerUser).
+		PutUint64(3, uint64(a.ExpectedBlockProposers)).
+		PutUint64(4, uint64(a.ExpectedCommitteeMembers)).
+		PutUint64(5, math.Float64bits(a.ThresholdOfBAStep)).
+		PutUint64(6, uint64(a.ExpectedFinalCommitteeMembers)).
+		PutUint64(7, math.Float64bits(a.FinalThreshold)).
+		PutUint64(8, uint64(a.MaxSteps)).
+		PutUint64(9, a.SeedRefreshInterval).
+		Encoded()).
+		Encoded()
+}
+
+// Hash 创世文件的 HASH, 写在数据创世区块的 Seed 里面
+func (g *Genesis) Hash() []byte {
+	hash := sha256.Sum256(g.Encode())
+	return hash[:]
+}
+
+// IsAdmin 判断地址是不是管理员
+func (g *Genesis) IsAdmin(address string) bool {
+	for _, admin := range g.Admins {
+		if admin == address {
+			return true
+		}
+	}
+	return false
+}
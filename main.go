@@ -5,7 +5,10 @@ import (
 	"alg_bcDB/Raft"
 	"alg_bcDB/algorand"
 	"alg_bcDB/blockqueue"
+	"alg_bcDB/cache"
 	"alg_bcDB/client"
+	"alg_bcDB/config"
+	"alg_bcDB/genesis"
 	"alg_bcDB/server"
 	"alg_bcDB/serverExec"
 	"fmt"
@@ -15,6 +18,29 @@ import (
 
 func main() {
 
+	// 读取配置文件与命令行参数
+	cfg, err := config.Parse(os.Args[1:])
+	if err == nil {
+		err = cache.CheckPolicy(cfg.Cache.Policy)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	if err := cfg.Apply(); err != nil {
+		log.Panic(err)
+	}
+	// 读取创世文件, 要在初始化区块链之前
+	if cfg.Genesis != "" {
+		g, err := genesis.Load(cfg.Genesis)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+		g.Apply()
+		fmt.Printf("网络 %s, 创世文件 HASH %x\n", g.ChainID, g.Hash())
+	}
+
 	blockqueue.LocalDataBlockQueue = blockqueue.Init()
 	blockqueue.LocalTableBlockQueue = blockqueue.Init()
 	//blockqueue.AlgToBC = blockqueue.Init()
@@ -25,14 +51,14 @@ func main() {
 	s.Init()
 
 	// 判断集群文件是否存在，如果不存在则直接执行cmd程序
-	_, err := os.Stat("./ClusterInfo")
+	_, err = os.Stat(Cluster.FilePath())
 	if os.IsNotExist(err) {
 		go s.Command()
 		go client.StartClient()
 		serverExec.ServerStart()
 	} else {
 		// 启动Raft, 读取集群文件
-		cluster, err := Cluster.LoadClusterFile("./ClusterInfo")
+		cluster, err := Cluster.LoadClusterFile(Cluster.FilePath())
 		if err != nil {
 			log.Panic(err)
 		}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"errors"
+	"strings"
+)
+
+// splitArgs 把终端输入的一行分成参数。 参数以空白分隔;
+// 双引号或者单引号里面的空白不分隔参数, 双引号里面 \" 和 \\ 为转义, 引号外面 \ 转义下一个字符。
+// 比如 put k "hello world" t 和 put k '{"a": 1}' t
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				current.WriteByte(c)
+			}
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else if c == '\\' && i+1 < len(line) && (line[i+1] == '"' || line[i+1] == '\\') {
+				i++
+				current.WriteByte(line[i])
+			} else {
+				current.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inArg = true
+		case c == '\\' && i+1 < len(line):
+			i++
+			current.WriteByte(line[i])
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("引号没有结束")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -5,9 +5,9 @@ import (
 	"alg_bcDB/GRPC"
 	"alg_bcDB/Raft"
 	"alg_bcDB/algorand"
+	"alg_bcDB/blockchain/blockchain_table"
 	"alg_bcDB/util"
 	"bufio"
-	"bytes"
 	"fmt"
 	"log"
 	"os"
@@ -21,14 +21,35 @@ const Usage0 = `Help info
   register username userPassword  -- 用户注册
   login username userPassword -- 用户登录
   address -- 查看用户的地址
-  table tableName userAddress+permissions -- 创建新的共享表
-  put key value tableName -- 向共享表中添加数据
-  get key tableName -- 在共享表中查询数据
+  table tableName [grant|revoke|replace] address:role... -- 创建共享表或者修改权限表, role 为 read|write|overwrite|manage (或者 1-4), revoke 只需要地址
+  schema tableName [schemaJSON|-] -- 查看表的 schema, 或者声明/修改表的 schema (需要 manage 角色), - 表示去掉 schema; 有 schema 的表的值是 JSON 对象, 写入时检查字段的类型, 必须的字段, enum, 范围 (min, max) 和引用其他表的 key (ref)
+  put key value tableName [string|bytes|json] -- 向共享表中添加数据, 值有空格时加引号; bytes 写作十六进制, json 写入前校验
+  putif key value tableName version|absent -- 条件写入, key 当前的版本是 version (get 输出的 version) 或者 key 不存在时才写入
+  get key tableName [jsonPath] -- 在共享表中查询数据, 值是 JSON 时可以用路径 (比如 $.a.b[0]) 查询子字段
+  delete key tableName -- 删除共享表中的数据 (历史里面还可以看到)
+  batch [read key tableName version|absent ...] put key value tableName [put ...] [delete key tableName ...] -- 批量写入, 所有写入在同一个交易里面一起生效; 有读取时提交时读取的版本过期则整个交易无效
+  txstatus txID -- 查询数据交易的状态 (等待打包, 打包时被拒绝, 提交时无效)
+  vget key tableName -- 可验证的查询, 输出数据的默克尔证明和区块头
+  scan tableName from to [limit] -- 按照 key 的顺序查询 [from, to) 之间的数据, "-" 表示不限制
+  prefix tableName prefix [limit] -- 查询 key 有指定前缀的数据
+  index tableName [field...|-] -- 查看表的二级索引, 或者声明表的所有索引字段 (需要 manage 角色, 字段是 json 值里面的路径, 比如 status, device.id), - 表示去掉所有索引
+  find tableName field=value [limit] -- 通过二级索引查找字段等于 value 的数据, 字符串写内容, 其他值写 JSON (比如 3, true)
+  getasof key tableName round|@time -- 查询数据在指定 Round (或者时间, Unix 秒或 RFC3339) 时的值
+  tableasof tableName round|@time -- 查询整个表在指定 Round (或者时间) 时的数据
   gethistory key tableName -- 查询表的更新历史
   mytables -- 查看自己所在的共享表
   root-tables -- 查看自己所在表的权限
   isaccount -- 查看节点是否拥有记账权
   set-pkg_num -- 设置打包模式
+  verifychain [ip port] -- 审计本地(或者指定节点)的数据区块链和表区块链
+  export path -- 把数据区块链和表区块链导出到归档文件
+  import path -- 用归档文件初始化新的节点
+  genesis -- 查看网络的 ID 与创世区块
+  cachestats -- 查看缓存每一层的命中率, 耗时和淘汰次数
+  cachesize txSize indexSize -- 修改缓存交易队列和索引队列的容量
+  cachepolicy lru|lruk|arc|tinylfu -- 更换缓存的淘汰策略(缓存会清空)
+  rebuildstate -- 从创世区块开始重新生成世界状态, 并和原来的世界状态比较
+  rebuildindex -- 由世界状态重新生成所有的二级索引
   u_in username userpaaword -- 在终端登录用户
   exit -- 退出登录或退出程序
   help -- 输出辅助信息
@@ -51,16 +72,15 @@ func (s *Server) Command() {
 			fmt.Printf(" %s ", username)
 		}
 		fmt.Printf("> ")
-		args := make([]string, 0)
 		line, _, err := reader.ReadLine()
 		if err != nil {
 			log.Panic(err)
 		}
-		split := bytes.Split(line, []byte(" "))
-		for _, argBytes := range split {
-			if string(argBytes) != "" {
-				args = append(args, string(argBytes))
-			}
+		// 引号里面的值可以有空格
+		args, err := splitArgs(string(line))
+		if err != nil {
+			fmt.Println(err)
+			continue
 		}
 		if len(args) == 0 {
 			continue
@@ -108,21 +128,116 @@ func (s *Server) Command() {
 		// 创建一个表
 		case "table":
 			if len(args) >= 3 {
-				plist := make([]string, 0)
-				for i := 2; i < len(args); i++ {
-					plist = append(plist, args[i])
+				// 第二个参数是操作的名字时, 后面是权限表; 否则为授予
+				op, err := blockchain_table.ParsePermissionOp(args[2])
+				plist := args[2:]
+				if err == nil {
+					plist = args[3:]
+				} else {
+					op = blockchain_table.OpGrant
 				}
-				s.Table(username+"-QAQ-"+password, args[1], plist)
+				s.Table(username+"-QAQ-"+password, args[1], op, plist)
 			} else {
-				fmt.Println("table tablename 用户地+用户权限（1-4）...")
+				fmt.Println("table tablename [grant|revoke|replace] 用户地址:角色(read|write|overwrite|manage 或者 1-4)...")
+			}
+		case "schema":
+			if len(args) == 2 {
+				s.SchemaCmd(username+"-QAQ-"+password, args[1], "")
+			} else if len(args) == 3 {
+				s.SchemaCmd(username+"-QAQ-"+password, args[1], args[2])
+			} else {
+				fmt.Println("schema tableName [schemaJSON|-]")
 			}
 		case "put":
 			if len(args) == 4 {
 				s.Put(username+"-QAQ-"+password, args[1], args[2], args[3])
+			} else if len(args) == 5 {
+				s.PutValueCmd(username+"-QAQ-"+password, args[1], args[2], args[3], args[4])
+			} else {
+				fmt.Println("put key value tableName [string|bytes|json]")
+			}
+		case "putif":
+			if len(args) == 5 {
+				s.PutIfCmd(username+"-QAQ-"+password, args[1], args[2], args[3], args[4])
+			} else {
+				fmt.Println("putif key value tableName version|absent")
 			}
 		case "get":
 			if len(args) == 3 {
 				s.Get(username+"-QAQ-"+password, args[1], args[2])
+			} else if len(args) == 4 {
+				s.GetPathCmd(username+"-QAQ-"+password, args[1], args[2], args[3])
+			} else {
+				fmt.Println("get key tableName [jsonPath]")
+			}
+		case "delete":
+			if len(args) == 3 {
+				s.DeleteCmd(username+"-QAQ-"+password, args[1], args[2])
+			} else {
+				fmt.Println("delete key tableName")
+			}
+		case "batch":
+			reads, writes, err := parseBatch(args[1:])
+			if err != nil {
+				fmt.Println(err)
+				fmt.Println("batch [read key tableName version|absent ...] put key value tableName [put ...] [delete key tableName ...]")
+				break
+			}
+			s.BatchCmd(username+"-QAQ-"+password, reads, writes)
+		case "txstatus":
+			if len(args) == 2 {
+				s.TxStatusCmd(args[1])
+			} else {
+				fmt.Println("txstatus txID")
+			}
+		case "vget":
+			if len(args) == 3 {
+				s.VGet(username+"-QAQ-"+password, args[1], args[2])
+			} else {
+				fmt.Println("vget key tableName")
+			}
+		case "scan":
+			if len(args) == 4 || len(args) == 5 {
+				from, to := args[2], args[3]
+				if from == "-" {
+					from = ""
+				}
+				if to == "-" {
+					to = ""
+				}
+				s.ScanCmd(username+"-QAQ-"+password, args[1], from, to, "", scanLimit(args, 4))
+			} else {
+				fmt.Println("scan tableName from to [limit]")
+			}
+		case "prefix":
+			if len(args) == 3 || len(args) == 4 {
+				s.ScanCmd(username+"-QAQ-"+password, args[1], "", "", args[2], scanLimit(args, 3))
+			} else {
+				fmt.Println("prefix tableName prefix [limit]")
+			}
+		case "find":
+			if len(args) == 3 || len(args) == 4 {
+				s.FindCmd(username+"-QAQ-"+password, args[1], args[2], scanLimit(args, 3))
+			} else {
+				fmt.Println("find tableName field=value [limit]")
+			}
+		case "index":
+			if len(args) >= 2 {
+				s.IndexCmd(username+"-QAQ-"+password, args[1], args[2:])
+			} else {
+				fmt.Println("index tableName [field...|-]")
+			}
+		case "getasof":
+			if len(args) == 4 {
+				s.AsOfCmd(username+"-QAQ-"+password, args[1], args[2], args[3])
+			} else {
+				fmt.Println("getasof key tableName round|@time")
+			}
+		case "tableasof":
+			if len(args) == 3 {
+				s.AsOfCmd(username+"-QAQ-"+password, "", args[1], args[2])
+			} else {
+				fmt.Println("tableasof tableName round|@time")
 			}
 		case "gethistory":
 			if len(args) == 3 {
@@ -148,7 +263,7 @@ func (s *Server) Command() {
 			}
 		case "join":
 			if len(args) == 4 {
-				_, err := os.Stat("./ClusterInfo")
+				_, err := os.Stat(Cluster.FilePath())
 				if os.IsNotExist(err) {
 					ip := args[1]
 					port, err := strconv.Atoi(args[2])
@@ -183,6 +298,61 @@ func (s *Server) Command() {
 			if len(args) == 1 {
 				fmt.Printf(Usage0)
 			}
+		case "verifychain":
+			if len(args) == 1 {
+				s.VerifyChain()
+			} else if len(args) == 3 {
+				port, err := strconv.Atoi(args[2])
+				if err != nil {
+					fmt.Println("verifychain ip port")
+					continue
+				}
+				s.VerifyRemoteChain(args[1], port)
+			} else {
+				fmt.Println("verifychain [ip port]")
+			}
+		case "export":
+			if len(args) == 2 {
+				s.ExportChain(args[1])
+			} else {
+				fmt.Println("export path")
+			}
+		case "import":
+			if len(args) == 2 {
+				s.ImportChain(args[1])
+			} else {
+				fmt.Println("import path")
+			}
+		case "genesis":
+			s.Genesis()
+		case "cachestats":
+			s.CacheStats()
+		case "cachesize":
+			if len(args) == 3 {
+				txSize, err1 := strconv.Atoi(args[1])
+				indexSize, err2 := strconv.Atoi(args[2])
+				if err1 != nil || err2 != nil {
+					fmt.Println("cachesize txSize indexSize")
+					continue
+				}
+				if err := s.Cache.SetSize(txSize, indexSize); err != nil {
+					fmt.Println(err)
+				}
+			} else {
+				fmt.Println("cachesize txSize indexSize")
+			}
+		case "cachepolicy":
+			if len(args) == 2 {
+				if err := s.Cache.SetPolicy(args[1]); err != nil {
+					fmt.Println(err)
+				}
+			} else {
+				fmt.Println("cachepolicy lru|lruk|arc|tinylfu")
+			}
+		case "rebuildstate":
+			s.RebuildState()
+		case "rebuildindex":
+			s.RebuildIndexes()
 		case "set-pkg_num":
 			num, _ := strconv.Atoi(args[1])
 			s.TxPool.SetPackNumber(num)
@@ -195,9 +365,14 @@ func (s *Server) Command() {
 		case "id":
 			if len(args) == 1 {
 				fmt.Printf(Usage0)
+				continue
 			}
 			a, _ := strconv.Atoi(args[1])
 			b := s.dataChain.GetByRound(uint64(a))
+			if b == nil {
+				fmt.Println("不存在这个区块")
+				continue
+			}
 			fmt.Println(b.Round)
 		case "gen":
 			fmt.Println(s.dataChain.GetBlockByHash([]byte{}))
@@ -212,7 +387,7 @@ func (s *Server) Command() {
 			s.TxPool.SetMod1()
 		case "printcluster":
 			if len(args) == 1 {
-				_, err := os.Stat("./ClusterInfo")
+				_, err := os.Stat(Cluster.FilePath())
 				if os.IsNotExist(err) {
 					fmt.Println("集群文件不存在")
 				} else {
@@ -227,3 +402,15 @@ func (s *Server) Command() {
 		}
 	}
 }
+
+// scanLimit 读取范围查询的数量参数, 没有或者格式错误时使用默认值
+func scanLimit(args []string, i int) int {
+	if len(args) <= i {
+		return 0
+	}
+	limit, err := strconv.Atoi(args[i])
+	if err != nil {
+		return 0
+	}
+	return limit
+}
@@ -24,8 +24,12 @@ type Server struct {
 func (s *Server) Init() {
 	s.manage.Init()
 	s.dataChain.InitBlockChain()
+	s.initLight()
 	s.tableChain.InitBlockChain()
 	s.Cache.Init(&s.dataChain, &s.tableChain)
 	s.TxPool.Init(&s.dataChain, &s.tableChain, &s.Cache)
 	s.Grpc.Init()
+	if s.dataChain.Light {
+		go s.lightSync()
+	}
 }
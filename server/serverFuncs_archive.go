@@ -0,0 +1,31 @@
+package server
+
+import (
+	"alg_bcDB/archive"
+	"fmt"
+	"time"
+)
+
+// ExportChain 把本地的表区块链和数据区块链导出到归档文件
+func (s *Server) ExportChain(path string) {
+	start := time.Now()
+	manifest, err := archive.Export(path, &s.dataChain, &s.tableChain)
+	if err != nil {
+		fmt.Println("导出失败;", err)
+		return
+	}
+	fmt.Printf("导出完成: 表区块 %d 个, 数据区块 %d 个\n", manifest.TableBlocks, manifest.DataBlocks)
+	fmt.Println("导出耗时：", time.Since(start))
+}
+
+// ImportChain 用归档文件初始化本节点的区块链, 校验所有区块并重建共享表相关链
+func (s *Server) ImportChain(path string) {
+	start := time.Now()
+	manifest, err := archive.Import(path, &s.dataChain, &s.tableChain, &s.Cache)
+	if err != nil {
+		fmt.Println("导入失败;", err)
+		return
+	}
+	fmt.Printf("导入完成: 表区块 %d 个, 数据区块 %d 个\n", manifest.TableBlocks, manifest.DataBlocks)
+	fmt.Println("导入耗时：", time.Since(start))
+}
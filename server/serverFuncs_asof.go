@@ -0,0 +1,82 @@
+package server
+
+import (
+	"alg_bcDB/cache"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResolveAsOf 解析时间点: 数字为区块的 Round, "@" 开头为时间 (Unix 秒或者 2006-01-02T15:04:05Z07:00),
+// 时间转换为那个时间区块链最新的区块的 Round
+func (s *Server) ResolveAsOf(at string) (uint64, error) {
+	if !strings.HasPrefix(at, "@") {
+		round, err := strconv.ParseUint(at, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Round 格式错误; %s", at)
+		}
+		return round, nil
+	}
+	at = at[1:]
+	var timeStamp int64
+	if t, err := time.Parse(time.RFC3339, at); err == nil {
+		timeStamp = t.Unix()
+	} else if timeStamp, err = strconv.ParseInt(at, 10, 64); err != nil {
+		return 0, fmt.Errorf("时间格式错误; %s", at)
+	}
+	if timeStamp < 0 {
+		return 0, errors.New("这个时间之前没有区块")
+	}
+	return s.dataChain.RoundAtTime(uint64(timeStamp))
+}
+
+// GetAsOf 查询数据在 Round 为 round 时的值
+func (s *Server) GetAsOf(UID, key, table string, round uint64) (cache.AsOfValue, error) {
+	if err := s.checkRead(UID, table); err != nil {
+		return cache.AsOfValue{}, err
+	}
+	value, err := s.Cache.GetValueAsOf(table+"-QAQ-"+key, table, round)
+	if err != nil {
+		return value, errors.New("没有找到该数据")
+	}
+	return value, nil
+}
+
+// GetTableAsOf 查询整个表在 Round 为 round 时的数据, 按照 key 排序
+func (s *Server) GetTableAsOf(UID, table string, round uint64) ([]cache.AsOfValue, error) {
+	if err := s.checkRead(UID, table); err != nil {
+		return nil, err
+	}
+	return s.Cache.GetTableAsOf(table, round)
+}
+
+// AsOfCmd 在终端进行时间点查询, key 为空时查询整个表
+func (s *Server) AsOfCmd(UID, key, table, at string) {
+	start := time.Now()
+	round, err := s.ResolveAsOf(at)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var values []cache.AsOfValue
+	if key != "" {
+		value, err := s.GetAsOf(UID, key, table, round)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		values = append(values, value)
+	} else if values, err = s.GetTableAsOf(UID, table, round); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("(Round %d 时的数据)\n", round)
+	for _, value := range values {
+		tx := value.Transaction
+		fmt.Printf("key : %s    value: %s    possessor: %s    round: %d    alterTime : %v\n", tx.Key, tx.Value, tx.Possessor,
+			value.Round, time.Unix(tx.TimeStamp, 0).Format("2006-01-02 03:04:05 PM"))
+	}
+	fmt.Println("该查找执行完成耗时：", time.Since(start))
+}
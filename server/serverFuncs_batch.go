@@ -0,0 +1,107 @@
+package server
+
+import (
+	"alg_bcDB/GRPC"
+	"alg_bcDB/blockchain/blockchain_data"
+	"errors"
+	"fmt"
+)
+
+// Batch 批量写入: 所有写入在一个交易里面, 打包进同一个区块, 要么都生效, 要么都不生效。
+// 每个写入的权限和单个的写入或者删除一样, 有一个写入没有权限时整个交易不提交
+func (s *Server) Batch(UID string, writes []blockchain_data.Write) ([]byte, error) {
+	return s.Transact(UID, nil, writes)
+}
+
+// Transact 读写集交易: reads 是读取的 key 和读取时的版本, 区块提交时有一个读取的版本过期, 交易被标记为无效, 所有写入都不生效。
+// 没有读取时就是批量写入
+func (s *Server) Transact(UID string, reads []blockchain_data.Read, writes []blockchain_data.Write) ([]byte, error) {
+	a, err := s.manage.ViewAccount(UID)
+	if err != nil {
+		return nil, errors.New("用户未登录")
+	}
+	if len(writes) == 0 {
+		return nil, errors.New("批量交易没有写入")
+	}
+	for i, w := range writes {
+		if _, err = s.Cache.CheckPermission(a.Address, w.Table); err != nil {
+			return nil, fmt.Errorf("不存在这个表; %s", w.Table)
+		}
+		if w.Op != blockchain_data.OpDelete {
+			writes[i].SchemaVersion = s.schemaVersion(w.Table, &writes[i].Type)
+			continue
+		}
+		writes[i].SchemaVersion = s.schemaVersion(w.Table, nil)
+		if _, err = s.Cache.GetOneValue(string(w.DataID()), w.Table); err != nil {
+			return nil, fmt.Errorf("没有找到该数据; %s %s", w.Table, w.Key)
+		}
+	}
+
+	var tx blockchain_data.Transaction
+	tx.InitReadWrite(reads, writes, a.UserName, a.PublicKey, a.PrivateKey)
+	if err = s.Cache.CheckWrite(&tx); err != nil {
+		return nil, err
+	}
+	// 提交时读取已经过期的交易一定是无效的, 不进入交易池
+	if r := s.Cache.StaleRead(&tx, nil); r != nil {
+		return nil, fmt.Errorf("表 %s 里面的 key %s 读取的版本过期", r.Table, r.Key)
+	}
+	if !blockchain_data.VerifyTransaction(tx) {
+		return nil, errors.New("批量交易校验失败")
+	}
+	if err = s.TxPool.TxDataIN(tx); err != nil {
+		return nil, err
+	}
+	// 交易的广播
+	GRPC.SubmitDataTransaction(tx)
+	return tx.TxID, nil
+}
+
+// BatchCmd 在终端提交批量写入或者读写集交易
+func (s *Server) BatchCmd(UID string, reads []blockchain_data.Read, writes []blockchain_data.Write) {
+	txID, err := s.Transact(UID, reads, writes)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("批量交易 %x 已提交, %d 个读取, %d 个写入\n", txID, len(reads), len(writes))
+}
+
+// parseBatch 解析终端的批量交易: put key value tableName, delete key tableName 或者 read key tableName version|absent, 可以有多个
+func parseBatch(args []string) ([]blockchain_data.Read, []blockchain_data.Write, error) {
+	var reads []blockchain_data.Read
+	var writes []blockchain_data.Write
+	for i := 0; i < len(args); {
+		switch args[i] {
+		case "put":
+			if i+3 >= len(args) {
+				return nil, nil, errors.New("put key value tableName")
+			}
+			writes = append(writes, blockchain_data.Write{Table: args[i+3], Key: args[i+1], Value: args[i+2]})
+			i += 4
+		case "delete":
+			if i+2 >= len(args) {
+				return nil, nil, errors.New("delete key tableName")
+			}
+			writes = append(writes, blockchain_data.Write{Table: args[i+2], Key: args[i+1], Op: blockchain_data.OpDelete})
+			i += 3
+		case "read":
+			if i+3 >= len(args) {
+				return nil, nil, errors.New("read key tableName version|absent")
+			}
+			// absent 表示读取时 key 不存在, 版本为空
+			_, version, err := ParseExpect(args[i+3])
+			if err != nil {
+				return nil, nil, err
+			}
+			reads = append(reads, blockchain_data.Read{Table: args[i+2], Key: args[i+1], Version: version})
+			i += 4
+		default:
+			return nil, nil, fmt.Errorf("错误的操作 %s, 可以是 put, delete, read", args[i])
+		}
+	}
+	if len(writes) == 0 {
+		return nil, nil, errors.New("批量交易没有写入")
+	}
+	return reads, writes, nil
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"alg_bcDB/config"
+	"alg_bcDB/genesis"
+	"errors"
+	"fmt"
+)
+
+// IsAdmin 判断用户是不是创世文件里面的管理员, 没有使用创世文件时使用配置里面的管理员
+func (s *Server) IsAdmin(UID string) bool {
+	a, err := s.manage.ViewAccount(UID)
+	if err != nil {
+		return false
+	}
+	if genesis.LocalGenesis != nil {
+		return genesis.LocalGenesis.IsAdmin(a.Address)
+	}
+	return config.LocalConfig.IsAdmin(a.Address)
+}
+
+// SetCacheSize 修改缓存两个队列的容量, 需要管理员权限
+func (s *Server) SetCacheSize(UID string, txSize, indexSize int) error {
+	if !s.IsAdmin(UID) {
+		return errors.New("需要管理员权限")
+	}
+	return s.Cache.SetSize(txSize, indexSize)
+}
+
+// SetCachePolicy 更换缓存的淘汰策略, 需要管理员权限
+func (s *Server) SetCachePolicy(UID string, name string) error {
+	if !s.IsAdmin(UID) {
+		return errors.New("需要管理员权限")
+	}
+	return s.Cache.SetPolicy(name)
+}
+
+// CacheStats 在终端输出缓存每一层的命中率, 耗时和两个队列的淘汰次数
+func (s *Server) CacheStats() {
+	stats := s.Cache.Stats()
+	fmt.Printf("淘汰策略: %s\n", stats.Policy)
+	fmt.Printf("%-12s %10s %10s %8s %12s %12s %12s\n", "tier", "hit", "miss", "rate", "mean(us)", "p50(us)", "p99(us)")
+	for _, t := range stats.Tiers {
+		fmt.Printf("%-12s %10d %10d %7.1f%% %12.1f %12.1f %12.1f\n", t.Name, t.Hits, t.Misses, t.HitRate*100, t.Mean, t.P50, t.P99)
+	}
+	fmt.Printf("没有找到的查询: %d\n", stats.Misses)
+	fmt.Printf("交易队列: %d/%d  淘汰 %d\n", stats.TxLen, stats.TxSize, stats.TxEvictions)
+	fmt.Printf("索引队列: %d/%d  淘汰 %d  升级到交易队列 %d\n", stats.IndexLen, stats.IndexSize, stats.IndexEvictions, stats.IndexPromotions)
+}
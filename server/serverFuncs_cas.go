@@ -0,0 +1,64 @@
+package server
+
+import (
+	"alg_bcDB/GRPC"
+	"alg_bcDB/blockchain/blockchain_data"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// PutIf 条件写入 (compare-and-set): key 当前的版本是 expect, 或者 cond 为 CondAbsent 时 key 不存在, 才写入。
+// 提交时和区块提交时都检查条件, 条件不成立时返回 *cache.ConflictError, 里面有 key 当前的版本
+func (s *Server) PutIf(UID, key, value, table string, cond blockchain_data.Condition, expect []byte) ([]byte, error) {
+	a, err := s.manage.ViewAccount(UID)
+	if err != nil {
+		return nil, errors.New("用户未登录")
+	}
+	if _, err = s.Cache.CheckPermission(a.Address, table); err != nil {
+		return nil, fmt.Errorf("不存在这个表; %s", table)
+	}
+
+	var tx blockchain_data.Transaction
+	tx.SchemaVersion = s.schemaVersion(table, &tx.Type)
+	tx.InitConditional(table, key, value, blockchain_data.OpPut, cond, expect, a.UserName, a.PublicKey, a.PrivateKey)
+	if err = s.Cache.CheckWrite(&tx); err != nil {
+		return nil, err
+	}
+	if !blockchain_data.VerifyTransaction(tx) {
+		return nil, errors.New("数据写入交易校验失败")
+	}
+	if err = s.TxPool.TxDataIN(tx); err != nil {
+		return nil, err
+	}
+	// 交易的广播
+	GRPC.SubmitDataTransaction(tx)
+	return tx.TxID, nil
+}
+
+// ParseExpect 解析期望的版本: "absent" 表示 key 必须不存在, 其他为版本 (TxID) 的十六进制
+func ParseExpect(expect string) (blockchain_data.Condition, []byte, error) {
+	if expect == "absent" {
+		return blockchain_data.CondAbsent, nil, nil
+	}
+	version, err := hex.DecodeString(expect)
+	if err != nil || len(version) == 0 {
+		return blockchain_data.CondNone, nil, fmt.Errorf("错误的版本 %s", expect)
+	}
+	return blockchain_data.CondVersion, version, nil
+}
+
+// PutIfCmd 在终端进行条件写入
+func (s *Server) PutIfCmd(UID, key, value, table, expect string) {
+	cond, version, err := ParseExpect(expect)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	txID, err := s.PutIf(UID, key, value, table, cond, version)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("条件写入交易 %x 已提交, 区块提交时条件不成立不会写入\n", txID)
+}
@@ -54,7 +54,9 @@ func (s *Server) SignOutAccount(ID string) bool {
 	return true
 }
 
-func (s *Server) Table(UID string, table string, permissionTable []string) bool {
+// Table 创建共享表, 或者修改共享表的权限表: 授予 (grant), 移除 (revoke), 替换 (replace)。
+// 修改已有的表需要 manage 角色, 修改以后表里面至少要有一个管理员
+func (s *Server) Table(UID string, table string, op blockchain_table.PermissionOp, permissionTable []string) bool {
 
 	// 登录状态检查
 	a, err := s.manage.ViewAccount(UID)
@@ -64,21 +66,40 @@ func (s *Server) Table(UID string, table string, permissionTable []string) bool
 	}
 	ad := a.Address
 
+	if op == blockchain_table.OpSchema {
+		fmt.Println("修改表的 schema 使用 schema 命令")
+		return false
+	}
+	if op == blockchain_table.OpIndex {
+		fmt.Println("修改表的索引使用 index 命令")
+		return false
+	}
+	permissionTable, err = blockchain_table.NormalizePermissions(op, permissionTable)
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+
 	// 权限检查
-	permission, err := s.Cache.CheckPermission(ad, table)
+	current, err := s.Cache.Permissions(table)
 	if err != nil {
-		fmt.Printf("创建一个新的表, %s\n", table)
-		goto alter
-	} else {
-		if permission < "4" {
-			fmt.Println("没有对表的修改权限")
+		if op == blockchain_table.OpRevoke {
+			fmt.Printf("不存在这个表; %s\n", table)
 			return false
 		}
+		fmt.Printf("创建一个新的表, %s\n", table)
+	} else if current[ad] < blockchain_table.RoleManage {
+		fmt.Println("没有对表的修改权限")
+		return false
 	}
 
-alter:
 	var tx blockchain_table.Transaction
-	tx.Init(table, permissionTable, a.UserName, a.PublicKey, a.PrivateKey)
+	tx.Init(table, op, permissionTable, a.UserName, a.PublicKey, a.PrivateKey)
+
+	if current.Apply(&tx).Managers() == 0 {
+		fmt.Println("修改以后表里面没有管理员, 至少要保留一个 manage 角色")
+		return false
+	}
 
 	if blockchain_table.VerifyTransaction(tx) {
 
@@ -104,24 +125,18 @@ func (s *Server) Put(UID string, key, value, table string) bool {
 		fmt.Println("用户未登录")
 		return false
 	}
-	ad := a.Address
-
-	// 权限检查
-	permission, err := s.Cache.CheckPermission(ad, table)
-	if err != nil {
-		fmt.Printf("不存在这个表; %s\n", table)
-		return false
-	} else {
-		if permission < "3" {
-			fmt.Println("没有对表的写入权限")
-			return false
-		}
-	}
 
 	// 1. 创建交易（这是本地的处理，如果是其他节点的交易直接校验并添加数据到交易池）
 	var tx blockchain_data.Transaction
+	tx.SchemaVersion = s.schemaVersion(table, &tx.Type)
 	tx.Init(table, key, value, a.UserName, a.PublicKey, a.PrivateKey)
 
+	// 权限检查: write 可以创建新的 key 和修改自己的 key, overwrite 可以修改任何人的 key
+	if err = s.Cache.CheckWrite(&tx); err != nil {
+		fmt.Println(err)
+		return false
+	}
+
 	// 2.验证交易
 	// 检验并添加到交易池
 	if blockchain_data.VerifyTransaction(tx) {
@@ -157,7 +172,7 @@ func (s *Server) Get(UID, key, table string) string {
 		fmt.Printf("不存在这个表; %s\n", table)
 		return ""
 	} else {
-		if permission < "1" {
+		if permission < blockchain_table.RoleRead {
 			fmt.Println("没有对表的查看权限")
 			return ""
 		}
@@ -171,8 +186,15 @@ func (s *Server) Get(UID, key, table string) string {
 		return ""
 	}
 	fmt.Printf("key : %s    ", tx.Key)
-	fmt.Printf("value: %s    ", tx.Value)
+	fmt.Printf("value: %s    ", FormatValue([]byte(tx.Value), tx.Type))
+	if tx.Type != blockchain_data.TypeString {
+		fmt.Printf("type: %s    ", tx.Type)
+	}
+	if tx.SchemaVersion != 0 {
+		fmt.Printf("schema: %d    ", tx.SchemaVersion)
+	}
 	fmt.Printf("possessor: %s    ", tx.Possessor)
+	fmt.Printf("version: %x    ", tx.TxID)
 	fmt.Printf("alterTime : %v\n", time.Unix(tx.TimeStamp, 0).Format("2006-01-02 03:04:05 PM"))
 	fmt.Printf("(查询结束)\n")
 
@@ -198,7 +220,7 @@ func (s *Server) GetHistory(UID, key, table string) {
 		fmt.Printf("不存在这个表; %s\n", table)
 		return
 	} else {
-		if permission < "1" {
+		if permission < blockchain_table.RoleRead {
 			fmt.Println("没有对表的查看权限")
 			return
 		}
@@ -207,7 +229,11 @@ func (s *Server) GetHistory(UID, key, table string) {
 	txs, err := s.Cache.GetHistoryValue(table+"-QAQ-"+key, table)
 	for _, tx := range txs {
 		fmt.Printf("key : %s    ", tx.Key)
-		fmt.Printf("value: %s    ", tx.Value)
+		if tx.IsDelete() {
+			fmt.Printf("(已删除)    ")
+		} else {
+			fmt.Printf("value: %s    ", FormatValue([]byte(tx.Value), tx.Type))
+		}
 		fmt.Printf("possessor: %s    ", tx.Possessor)
 		fmt.Printf("alterTime : %v\n", time.Unix(tx.TimeStamp, 0).Format("2006-01-02 03:04:05 PM"))
 	}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"alg_bcDB/blockchain/blockchain_table"
 	"fmt"
 	"time"
 )
@@ -22,7 +23,7 @@ func (s *Server) GetTableData(UID, table string) {
 		fmt.Printf("不存在这个表; %s\n", table)
 		return
 	} else {
-		if permission < "1" {
+		if permission < blockchain_table.RoleRead {
 			fmt.Println("没有对表的查看权限")
 			return
 		}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"alg_bcDB/GRPC"
+	"alg_bcDB/blockchain/blockchain_data"
+	"errors"
+	"fmt"
+)
+
+// Delete 删除数据: 创建一个墓碑交易, 进入交易池并广播, 返回交易的 ID。
+// 删除和写入的权限一样: write 可以删除自己的 key, overwrite 可以删除任何人的 key
+func (s *Server) Delete(UID, key, table string) ([]byte, error) {
+	a, err := s.manage.ViewAccount(UID)
+	if err != nil {
+		return nil, errors.New("用户未登录")
+	}
+	if _, err = s.Cache.CheckPermission(a.Address, table); err != nil {
+		return nil, fmt.Errorf("不存在这个表; %s", table)
+	}
+	if _, err = s.Cache.GetOneValue(table+"-QAQ-"+key, table); err != nil {
+		return nil, errors.New("没有找到该数据")
+	}
+
+	var tx blockchain_data.Transaction
+	tx.SchemaVersion = s.schemaVersion(table, nil)
+	tx.InitDelete(table, key, a.UserName, a.PublicKey, a.PrivateKey)
+	if err = s.Cache.CheckWrite(&tx); err != nil {
+		return nil, err
+	}
+	if !blockchain_data.VerifyTransaction(tx) {
+		return nil, errors.New("删除交易校验失败")
+	}
+	if err = s.TxPool.TxDataIN(tx); err != nil {
+		return nil, err
+	}
+	// 交易的广播
+	GRPC.SubmitDataTransaction(tx)
+	return tx.TxID, nil
+}
+
+// DeleteCmd 在终端删除数据
+func (s *Server) DeleteCmd(UID, key, table string) {
+	txID, err := s.Delete(UID, key, table)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("删除交易 %x 已提交\n", txID)
+}
@@ -0,0 +1,22 @@
+package server
+
+import (
+	"alg_bcDB/genesis"
+	"fmt"
+)
+
+// Genesis 输出本节点的网络信息: 网络的 ID, 两条区块链的创世区块, 管理员与初始的共享表
+func (s *Server) Genesis() {
+	g := genesis.LocalGenesis
+	if g == nil {
+		fmt.Println("没有使用创世文件")
+	} else {
+		fmt.Printf("网络: %s\n创世文件 HASH: %x\n", g.ChainID, g.Hash())
+		fmt.Println("管理员:", g.Admins)
+		for _, table := range g.Tables {
+			fmt.Printf("初始共享表: %s %v\n", table.Name, table.Permissions)
+		}
+	}
+	fmt.Printf("数据创世区块: %x\n", s.dataChain.GetHashByRound(0))
+	fmt.Printf("表创世区块: %x\n", s.tableChain.GetHashByID(1))
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"alg_bcDB/GRPC"
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/blockchain/blockchain_table"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SetIndexes 声明表的二级索引字段 (替换原来的, 为空时去掉所有索引), 需要 manage 角色。 返回表交易的 ID。
+// 字段是 json 值里面的 JSON 路径, 比如 status 或者 device.id
+func (s *Server) SetIndexes(UID, table string, fields []string) ([]byte, error) {
+	a, err := s.manage.ViewAccount(UID)
+	if err != nil {
+		return nil, errors.New("用户未登录")
+	}
+	current, err := s.Cache.Permissions(table)
+	if err != nil {
+		return nil, fmt.Errorf("不存在这个表; %s", table)
+	}
+	if current[a.Address] < blockchain_table.RoleManage {
+		return nil, errors.New("没有对表的修改权限")
+	}
+	fields, err = blockchain_table.NormalizeIndexes(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx blockchain_table.Transaction
+	tx.InitIndexes(table, fields, a.UserName, a.PublicKey, a.PrivateKey)
+	if !blockchain_table.VerifyTransaction(tx) {
+		return nil, errors.New("索引交易校验失败")
+	}
+	if err = s.TxPool.TxTableIN(tx); err != nil {
+		return nil, err
+	}
+	// 交易的广播
+	GRPC.SubmitTableTransaction(tx)
+	return tx.TxID, nil
+}
+
+// GetIndexes 表声明的索引字段, 需要查看权限
+func (s *Server) GetIndexes(UID, table string) ([]string, error) {
+	if err := s.checkRead(UID, table); err != nil {
+		return nil, err
+	}
+	return s.Cache.Indexes(table), nil
+}
+
+// Find 通过二级索引查找表里面字段 field 的值等于 value 的数据, 按照 key 的顺序。
+// 字符串字段的值是字符串的内容, 其他字段的值是 JSON (比如 3, true)
+func (s *Server) Find(UID, table, field, value string, limit int) ([]blockchain_data.Transaction, error) {
+	if err := s.checkRead(UID, table); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultScanLimit
+	}
+	if limit > MaxScanLimit {
+		limit = MaxScanLimit
+	}
+	fields, err := blockchain_table.NormalizeIndexes([]string{field})
+	if err != nil {
+		return nil, err
+	}
+	return s.Cache.Find(table, fields[0], value, limit)
+}
+
+// IndexCmd 在终端查看或者修改表的索引字段: fields 为空时查看, 为 "-" 时去掉所有索引
+func (s *Server) IndexCmd(UID, table string, fields []string) {
+	if len(fields) == 0 {
+		current, err := s.GetIndexes(UID, table)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if len(current) == 0 {
+			fmt.Printf("表 %s 没有索引\n", table)
+			return
+		}
+		fmt.Printf("表 %s 的索引字段: %s\n", table, strings.Join(current, ", "))
+		return
+	}
+	if len(fields) == 1 && fields[0] == "-" {
+		fields = nil
+	}
+	txID, err := s.SetIndexes(UID, table, fields)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("索引交易 %x 已提交\n", txID)
+}
+
+// FindCmd 在终端通过索引查找数据, cond 写作 field=value
+func (s *Server) FindCmd(UID, table, cond string, limit int) {
+	start := time.Now()
+	i := strings.Index(cond, "=")
+	if i <= 0 {
+		fmt.Println("find tableName field=value [limit]")
+		return
+	}
+	txs, err := s.Find(UID, table, cond[:i], cond[i+1:], limit)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, tx := range txs {
+		fmt.Printf("key : %s    value: %s    possessor: %s    alterTime : %v\n", tx.Key, FormatValue([]byte(tx.Value), tx.Type), tx.Possessor,
+			time.Unix(tx.TimeStamp, 0).Format("2006-01-02 03:04:05 PM"))
+	}
+	fmt.Printf("(共 %d 条)\n", len(txs))
+	fmt.Println("该查找执行完成耗时：", time.Since(start))
+}
+
+// RebuildIndexes 由世界状态重新生成所有的二级索引
+func (s *Server) RebuildIndexes() {
+	start := time.Now()
+	count, err := s.Cache.RebuildIndexes()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("重建二级索引完成, 共 %d 个索引项, 耗时 %v\n", count, time.Since(start))
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"alg_bcDB/GRPC"
+	"alg_bcDB/config"
+	"fmt"
+	"log"
+	"time"
+)
+
+// initLight 按照配置决定是否以轻节点运行, 只在启动时决定, 运行时不能切换。
+// 轻节点只向配置里面的全节点同步表区块链和数据区块头, 读取数据时按需获取区块体并用区块头校验。
+// 全节点的存储不能变成轻节点, 轻节点的存储 (没有区块体) 也不能作为全节点使用
+func (s *Server) initLight() {
+	if config.LocalConfig.Light == "" {
+		if s.dataChain.IsLightStore() {
+			log.Panic("数据目录是轻节点的存储, 全节点要使用新的数据目录")
+		}
+		return
+	}
+	ip, port, err := config.LocalConfig.LightPeer()
+	if err != nil {
+		log.Panic(err)
+	}
+	if err = s.dataChain.InitLight(GRPC.DataBodyFetcher(ip, port)); err != nil {
+		log.Panic(err)
+	}
+	fmt.Printf("以轻节点运行, 向 %s:%d 同步区块头\n", ip, port)
+}
+
+// lightSync 轻节点定时同步新的区块头
+func (s *Server) lightSync() {
+	ip, port, _ := config.LocalConfig.LightPeer()
+	if err := GRPC.LightSynchronization(ip, port); err != nil {
+		fmt.Println("区块头同步失败;", err)
+	} else {
+		fmt.Println("区块头同步完成")
+	}
+	for {
+		time.Sleep(time.Second * 2)
+		if err := GRPC.LightSynchronization(ip, port); err != nil {
+			log.Println("区块头同步失败;", err)
+		}
+	}
+}
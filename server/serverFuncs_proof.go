@@ -0,0 +1,55 @@
+package server
+
+import (
+	"alg_bcDB/blockchain/blockchain_data"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// VerifiableGet 可验证的查询, 返回数据最新的交易, 交易的默克尔证明和包含交易的区块头。
+// 数据在批量交易里面时证明的是整个批量交易, proof.Write 是这个数据的写入
+func (s *Server) VerifiableGet(UID, key, table string) (*blockchain_data.TxProof, error) {
+	if err := s.checkRead(UID, table); err != nil {
+		return nil, err
+	}
+	dataID := table + "-QAQ-" + key
+	blockHash, txID, err := s.Cache.LocateValue(dataID, table)
+	if err != nil {
+		return nil, errors.New("没有找到该数据")
+	}
+	proof, err := s.dataChain.ProveTransaction(blockHash, txID)
+	if err != nil {
+		return nil, err
+	}
+	write, has := proof.Transaction.Lookup(dataID)
+	if !has || write.IsDelete() {
+		return nil, errors.New("没有找到该数据")
+	}
+	proof.Write = write
+	return proof, nil
+}
+
+// VGet 在终端进行可验证的查询, 输出证明并在本地校验
+func (s *Server) VGet(UID, key, table string) {
+	start := time.Now()
+	proof, err := s.VerifiableGet(UID, key, table)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	tx, header := proof.Write, proof.Header
+	fmt.Printf("key : %s    value: %s    possessor: %s\n", tx.Key, tx.Value, tx.Possessor)
+	fmt.Printf("区块: Round %d  HASH %x\n", header.Round, header.CurrentBlockHash)
+	fmt.Printf("默克尔根: %x  交易序号: %d/%d\n", header.MerKelRoot, proof.Proof.Index, proof.Proof.Size)
+	for i, hash := range proof.Proof.Hashes {
+		fmt.Printf("  证明[%d]: %x\n", i, hash)
+	}
+	// 在终端里面信任本地的区块链
+	if err := proof.VerifyTrusted(s.dataChain.GetHashByRound(header.Round)); err != nil {
+		fmt.Println("证明校验失败;", err)
+	} else {
+		fmt.Println("证明校验成功")
+	}
+	fmt.Println("该查找执行完成耗时：", time.Since(start))
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/blockchain/blockchain_table"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultScanLimit = 100  // DefaultScanLimit 范围查询默认返回的数量
+	MaxScanLimit     = 1000 // MaxScanLimit 范围查询一次最多返回的数量
+)
+
+// checkRead 检查用户是否登录, 是否有表的查看权限, 和 Get 的检查一样
+func (s *Server) checkRead(UID, table string) error {
+	a, err := s.manage.ViewAccount(UID)
+	if err != nil {
+		return errors.New("用户未登录")
+	}
+	permission, err := s.Cache.CheckPermission(a.Address, table)
+	if err != nil {
+		return fmt.Errorf("不存在这个表; %s", table)
+	}
+	if permission < blockchain_table.RoleRead {
+		return errors.New("没有对表的查看权限")
+	}
+	return nil
+}
+
+// Scan 按照 key 的顺序查询表里面 [from, to) 之间有前缀 prefix 的数据, to 为空时到表的末尾。
+// 返回的 next 不为空时表示还有数据, 作为下一次查询的 from 继续查询
+func (s *Server) Scan(UID, table, from, to, prefix string, limit int) ([]blockchain_data.Transaction, string, error) {
+	if err := s.checkRead(UID, table); err != nil {
+		return nil, "", err
+	}
+	if limit <= 0 {
+		limit = DefaultScanLimit
+	}
+	if limit > MaxScanLimit {
+		limit = MaxScanLimit
+	}
+	return s.Cache.ScanTable(table, from, to, prefix, limit)
+}
+
+// ScanCmd 在终端进行范围查询或者前缀查询, 输出查询的结果和下一次查询的 from
+func (s *Server) ScanCmd(UID, table, from, to, prefix string, limit int) {
+	start := time.Now()
+	txs, next, err := s.Scan(UID, table, from, to, prefix, limit)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, tx := range txs {
+		fmt.Printf("key : %s    value: %s    possessor: %s    alterTime : %v\n", tx.Key, tx.Value, tx.Possessor,
+			time.Unix(tx.TimeStamp, 0).Format("2006-01-02 03:04:05 PM"))
+	}
+	fmt.Printf("(共 %d 条)\n", len(txs))
+	if next != "" {
+		fmt.Printf("还有更多数据, 下一次查询从 %s 开始\n", next)
+	}
+	fmt.Println("该查找执行完成耗时：", time.Since(start))
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"alg_bcDB/GRPC"
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/blockchain/blockchain_table"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SetSchema 声明, 修改或者去掉 (schema 为空) 表的 schema, 需要 manage 角色。 返回表交易的 ID。
+// schema 的格式见 blockchain_table.Schema, 引用的表必须已经存在
+func (s *Server) SetSchema(UID, table, schema string) ([]byte, error) {
+	a, err := s.manage.ViewAccount(UID)
+	if err != nil {
+		return nil, errors.New("用户未登录")
+	}
+	current, err := s.Cache.Permissions(table)
+	if err != nil {
+		return nil, fmt.Errorf("不存在这个表; %s", table)
+	}
+	if current[a.Address] < blockchain_table.RoleManage {
+		return nil, errors.New("没有对表的修改权限")
+	}
+	parsed, canonical, err := blockchain_table.ParseSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	if parsed != nil {
+		for _, ref := range parsed.Refs() {
+			if _, err = s.Cache.Permissions(ref); err != nil {
+				return nil, fmt.Errorf("引用的表 %s 不存在", ref)
+			}
+		}
+	}
+
+	var tx blockchain_table.Transaction
+	tx.InitSchema(table, canonical, a.UserName, a.PublicKey, a.PrivateKey)
+	if !blockchain_table.VerifyTransaction(tx) {
+		return nil, errors.New("schema 交易校验失败")
+	}
+	if err = s.TxPool.TxTableIN(tx); err != nil {
+		return nil, err
+	}
+	// 交易的广播
+	GRPC.SubmitTableTransaction(tx)
+	return tx.TxID, nil
+}
+
+// GetSchema 表当前的 schema 的 JSON (没有时为空) 和版本, 需要查看权限
+func (s *Server) GetSchema(UID, table string) (string, uint64, error) {
+	if err := s.checkRead(UID, table); err != nil {
+		return "", 0, err
+	}
+	schema, version := s.Cache.Schema(table)
+	if schema == nil {
+		return "", version, nil
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return "", version, err
+	}
+	return string(data), version, nil
+}
+
+// schemaVersion 写入时使用的表的 schema 的版本。 typ 不为 nil 时, 表有 schema 并且值是 string 的写入改为 json
+func (s *Server) schemaVersion(table string, typ *blockchain_data.ValueType) uint64 {
+	schema, version := s.Cache.Schema(table)
+	if schema != nil && typ != nil && *typ == blockchain_data.TypeString {
+		*typ = blockchain_data.TypeJSON
+	}
+	return version
+}
+
+// SchemaCmd 在终端查看或者修改表的 schema: schema 为空时查看, 为 "-" 时去掉表的 schema
+func (s *Server) SchemaCmd(UID, table, schema string) {
+	if schema == "" {
+		current, version, err := s.GetSchema(UID, table)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if current == "" {
+			current = "(没有 schema)"
+		}
+		fmt.Printf("表 %s 的 schema 版本 %d: %s\n", table, version, current)
+		return
+	}
+	if schema == "-" {
+		schema = ""
+	}
+	txID, err := s.SetSchema(UID, table, schema)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("schema 交易 %x 已提交\n", txID)
+}
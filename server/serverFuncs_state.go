@@ -0,0 +1,31 @@
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+// RebuildState 从创世区块开始重新生成世界状态, 在终端输出和原来的世界状态的差别
+func (s *Server) RebuildState() {
+	start := time.Now()
+	diff, err := s.Cache.RebuildState()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("重建世界状态完成, 共 %d 个数据, 耗时 %v\n", diff.Entries, time.Since(start))
+	if diff.Consistent() {
+		fmt.Println("和原来的世界状态一致")
+		return
+	}
+	fmt.Println("和原来的世界状态不一致, 已经替换为重建的世界状态:")
+	for _, dataID := range diff.Missing {
+		fmt.Printf("  缺少: %s\n", dataID)
+	}
+	for _, dataID := range diff.Extra {
+		fmt.Printf("  多余: %s\n", dataID)
+	}
+	for _, dataID := range diff.Changed {
+		fmt.Printf("  不一致: %s\n", dataID)
+	}
+}
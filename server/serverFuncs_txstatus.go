@@ -0,0 +1,49 @@
+package server
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// 数据交易的状态
+const (
+	TxPending  = "pending"  // 在交易池里面等待打包
+	TxRejected = "rejected" // 打包时没有通过检查 (没有权限, 条件不成立), 没有上链
+	TxInvalid  = "invalid"  // 在区块里面, 提交时读取集校验失败, 写入没有生效
+	TxUnknown  = "unknown"  // 本节点没有记录: 已经提交, 或者没有收到这个交易
+)
+
+// TxStatus 数据交易的状态和原因, 无效的交易同时返回所在区块的 Round。
+// 打包时被拒绝的记录只在打包的节点上, 无效交易的记录在每个全节点上
+func (s *Server) TxStatus(txID []byte) (status, reason string, round uint64) {
+	if r, err := s.dataChain.GetInvalidRecord(txID); err == nil {
+		return TxInvalid, r.Reason, r.Round
+	}
+	if reason, has := s.TxPool.DataTxRejected(txID); has {
+		return TxRejected, reason, 0
+	}
+	if s.TxPool.DataTxPending(txID) {
+		return TxPending, "", 0
+	}
+	return TxUnknown, "", 0
+}
+
+// TxStatusCmd 在终端查询数据交易的状态
+func (s *Server) TxStatusCmd(txID string) {
+	id, err := hex.DecodeString(txID)
+	if err != nil || len(id) == 0 {
+		fmt.Println("错误的交易 ID", txID)
+		return
+	}
+	status, reason, round := s.TxStatus(id)
+	switch status {
+	case TxInvalid:
+		fmt.Printf("交易无效 (Round %d): %s\n", round, reason)
+	case TxRejected:
+		fmt.Println("交易打包时被拒绝:", reason)
+	case TxPending:
+		fmt.Println("交易在交易池里面等待打包")
+	default:
+		fmt.Println("本节点没有这个交易的记录 (已经提交或者没有收到)")
+	}
+}
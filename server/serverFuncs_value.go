@@ -0,0 +1,116 @@
+package server
+
+import (
+	"alg_bcDB/GRPC"
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/util"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PutValue 写入指定类型的值: string, bytes (任意字节) 或者 json (写入前校验), 返回交易的 ID
+func (s *Server) PutValue(UID, key, table string, value []byte, typ blockchain_data.ValueType) ([]byte, error) {
+	a, err := s.manage.ViewAccount(UID)
+	if err != nil {
+		return nil, errors.New("用户未登录")
+	}
+	if err = blockchain_data.CheckValue(typ, blockchain_data.OpPut, string(value)); err != nil {
+		return nil, err
+	}
+
+	var tx blockchain_data.Transaction
+	tx.SchemaVersion = s.schemaVersion(table, &typ)
+	tx.InitTyped(table, key, value, typ, a.UserName, a.PublicKey, a.PrivateKey)
+	if err = s.Cache.CheckWrite(&tx); err != nil {
+		return nil, err
+	}
+	if !blockchain_data.VerifyTransaction(tx) {
+		return nil, errors.New("数据写入交易校验失败")
+	}
+	if err = s.TxPool.TxDataIN(tx); err != nil {
+		return nil, err
+	}
+	// 交易的广播
+	GRPC.SubmitDataTransaction(tx)
+	return tx.TxID, nil
+}
+
+// GetValue 查询数据的值和类型, path 不为空时值必须是 JSON, 返回 JSON 路径指向的子字段 (类型为 json)
+func (s *Server) GetValue(UID, key, table, path string) ([]byte, blockchain_data.ValueType, *blockchain_data.Transaction, error) {
+	if err := s.checkRead(UID, table); err != nil {
+		return nil, 0, nil, err
+	}
+	tx, err := s.Cache.GetOneValue(table+"-QAQ-"+key, table)
+	if err != nil {
+		return nil, 0, nil, errors.New("没有找到该数据")
+	}
+	if path == "" {
+		return []byte(tx.Value), tx.Type, &tx, nil
+	}
+	if tx.Type != blockchain_data.TypeJSON {
+		return nil, 0, nil, fmt.Errorf("值的类型是 %s, 不能使用 JSON 路径", tx.Type)
+	}
+	value, err := util.JSONPath([]byte(tx.Value), path)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return value, blockchain_data.TypeJSON, &tx, nil
+}
+
+// FormatValue 在终端显示的值: bytes 显示为十六进制
+func FormatValue(value []byte, typ blockchain_data.ValueType) string {
+	if typ == blockchain_data.TypeBytes {
+		return "0x" + hex.EncodeToString(value)
+	}
+	return string(value)
+}
+
+// ParseValue 解析终端输入的值: bytes 写作十六进制 (可以有 0x 前缀)
+func ParseValue(value string, typ blockchain_data.ValueType) ([]byte, error) {
+	if typ != blockchain_data.TypeBytes {
+		return []byte(value), nil
+	}
+	if len(value) >= 2 && value[0] == '0' && (value[1] == 'x' || value[1] == 'X') {
+		value = value[2:]
+	}
+	data, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("bytes 类型的值要写作十六进制")
+	}
+	return data, nil
+}
+
+// PutValueCmd 在终端写入指定类型的值
+func (s *Server) PutValueCmd(UID, key, value, table, typeName string) {
+	typ, err := blockchain_data.ParseValueType(typeName)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	data, err := ParseValue(value, typ)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	txID, err := s.PutValue(UID, key, table, data, typ)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("数据写入交易 %x 已提交\n", txID)
+}
+
+// GetPathCmd 在终端查询 JSON 值里面的子字段
+func (s *Server) GetPathCmd(UID, key, table, path string) {
+	start := time.Now()
+	value, typ, tx, err := s.GetValue(UID, key, table, path)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("key : %s    path: %s    value: %s    ", key, path, FormatValue(value, typ))
+	fmt.Printf("possessor: %s    version: %x\n", tx.Possessor, tx.TxID)
+	fmt.Println("该查找执行完成耗时：", time.Since(start))
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"alg_bcDB/GRPC"
+	"alg_bcDB/common"
+	"fmt"
+	"time"
+)
+
+// VerifyChain 审计本地的数据区块链和表区块链, 输出审计结果
+func (s *Server) VerifyChain() {
+	start := time.Now()
+	reports := []*common.ChainReport{s.dataChain.VerifyChain(), s.tableChain.VerifyChain()}
+	printReports(reports)
+	fmt.Println("审计完成耗时：", time.Since(start))
+}
+
+// VerifyRemoteChain 通过 GRPC 让指定节点审计它的区块链
+func (s *Server) VerifyRemoteChain(ip string, port int) {
+	reports, err := GRPC.VerifyRemoteChain(ip, port, "")
+	if err != nil {
+		fmt.Printf("%s:%d 审计失败; %v\n", ip, port, err)
+		return
+	}
+	printReports(reports)
+}
+
+func printReports(reports []*common.ChainReport) {
+	for _, report := range reports {
+		fmt.Print(report.String())
+	}
+}
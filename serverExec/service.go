@@ -1,6 +1,10 @@
 package serverExec
 
 import (
+	"alg_bcDB/blockchain/blockchain_data"
+	"alg_bcDB/blockchain/blockchain_table"
+	"alg_bcDB/cache"
+	"alg_bcDB/config"
 	"alg_bcDB/server"
 	"alg_bcDB/serverExec/service"
 	"alg_bcDB/util"
@@ -34,6 +38,24 @@ type ExecCommandService interface {
 	StreamClient(res service.Server_StreamClientServer) error
 	//双向流
 	StreamTwo(res service.Server_StreamTwoServer) error
+	//可验证的查询
+	VerifiableGet(ctx context.Context, req *service.VerifiableGetRequest) (*service.VerifiableGetReply, error)
+	//范围查询
+	Scan(ctx context.Context, req *service.ScanRequest) (*service.ScanReply, error)
+	//时间点查询
+	GetAsOf(ctx context.Context, req *service.AsOfRequest) (*service.AsOfReply, error)
+	//删除数据
+	Delete(ctx context.Context, req *service.DeleteRequest) (*service.DeleteReply, error)
+	Batch(ctx context.Context, req *service.BatchRequest) (*service.BatchReply, error)
+	PutIf(ctx context.Context, req *service.PutIfRequest) (*service.PutIfReply, error)
+	TxStatus(ctx context.Context, req *service.TxStatusRequest) (*service.TxStatusReply, error)
+	PutValue(ctx context.Context, req *service.PutValueRequest) (*service.PutValueReply, error)
+	GetValue(ctx context.Context, req *service.GetValueRequest) (*service.GetValueReply, error)
+	GetSchema(ctx context.Context, req *service.GetSchemaRequest) (*service.GetSchemaReply, error)
+	SetSchema(ctx context.Context, req *service.SetSchemaRequest) (*service.SetSchemaReply, error)
+	Find(ctx context.Context, req *service.FindRequest) (*service.FindReply, error)
+	GetIndexes(ctx context.Context, req *service.GetIndexesRequest) (*service.GetIndexesReply, error)
+	SetIndexes(ctx context.Context, req *service.SetIndexesRequest) (*service.SetIndexesReply, error)
 	MustEmbedUnimplementedServerServer()
 }
 
@@ -127,7 +149,8 @@ func (exec *Exec) Cmd(ctx context.Context, cmd *service.CommandRequest) (*servic
 	//创建表
 	if cmd.TabelName != "" && cmd.PermissionTable != "" {
 		permissionTable := strings.Split(cmd.PermissionTable, ",")
-		isCreatetabelSuccess := RPCs.Table(cmd.Uid, cmd.TabelName, permissionTable)
+		op, err := blockchain_table.ParsePermissionOp(cmd.PermissionOp)
+		isCreatetabelSuccess := err == nil && RPCs.Table(cmd.Uid, cmd.TabelName, op, permissionTable)
 		time.Sleep(time.Second * 5)
 		if isCreatetabelSuccess {
 			tabelNames := RPCs.MyTable(cmd.Uid) //将共享表表名传过去
@@ -273,18 +296,107 @@ func (exec *Exec) StreamTwo(res service.Server_StreamTwoServer) error {
 	return nil
 }
 
+// VerifiableGet 可验证的查询, 返回交易与区块头的规范编码和交易的默克尔证明, 客户端可以自己校验结果
+func (exec *Exec) VerifiableGet(ctx context.Context, req *service.VerifiableGetRequest) (*service.VerifiableGetReply, error) {
+	proof, err := RPCs.VerifiableGet(req.Uid, req.Key, req.TabelName)
+	if err != nil {
+		return &service.VerifiableGetReply{Error: err.Error()}, nil
+	}
+	return &service.VerifiableGetReply{
+		Value:       util.TextValue(proof.Write.Value),
+		RawValue:    []byte(proof.Write.Value),
+		ValueType:   proof.Write.Type.String(),
+		Transaction: proof.Transaction.Encode(),
+		Header:      proof.Header.EncodeHeader(),
+		BlockHash:   proof.Header.CurrentBlockHash,
+		Signature:   proof.Header.Signature,
+		Round:       proof.Header.Round,
+		LeafIndex:   proof.Proof.Index,
+		TreeSize:    proof.Proof.Size,
+		Proof:       proof.Proof.Hashes,
+	}, nil
+}
+
+// Scan 范围查询和前缀查询, 返回的 next_key 作为下一次查询的 from_key
+func (exec *Exec) Scan(ctx context.Context, req *service.ScanRequest) (*service.ScanReply, error) {
+	txs, next, err := RPCs.Scan(req.Uid, req.TabelName, req.FromKey, req.ToKey, req.Prefix, int(req.Limit))
+	if err != nil {
+		return &service.ScanReply{Error: err.Error()}, nil
+	}
+	reply := &service.ScanReply{NextKey: next}
+	for _, tx := range txs {
+		reply.Entries = append(reply.Entries, &service.KeyValue{
+			Key:       tx.Key,
+			Value:     util.TextValue(tx.Value),
+			RawValue:  []byte(tx.Value),
+			ValueType: tx.Type.String(),
+			Possessor: tx.Possessor,
+			TimeStamp: tx.TimeStamp,
+			Version:   tx.TxID,
+		})
+	}
+	return reply, nil
+}
+
+// Delete 删除数据, 返回墓碑交易的 ID
+func (exec *Exec) Delete(ctx context.Context, req *service.DeleteRequest) (*service.DeleteReply, error) {
+	txID, err := RPCs.Delete(req.Uid, req.Key, req.TabelName)
+	if err != nil {
+		return &service.DeleteReply{Error: err.Error()}, nil
+	}
+	return &service.DeleteReply{TxId: txID}, nil
+}
+
+// GetAsOf 时间点查询, key 为空时查询整个表
+func (exec *Exec) GetAsOf(ctx context.Context, req *service.AsOfRequest) (*service.AsOfReply, error) {
+	at := strconv.FormatUint(req.Round, 10)
+	if req.ByTime {
+		at = "@" + strconv.FormatInt(req.TimeStamp, 10)
+	}
+	round, err := RPCs.ResolveAsOf(at)
+	if err != nil {
+		return &service.AsOfReply{Error: err.Error()}, nil
+	}
+	var values []cache.AsOfValue
+	if req.Key != "" {
+		var value cache.AsOfValue
+		value, err = RPCs.GetAsOf(req.Uid, req.Key, req.TabelName, round)
+		values = append(values, value)
+	} else {
+		values, err = RPCs.GetTableAsOf(req.Uid, req.TabelName, round)
+	}
+	if err != nil {
+		return &service.AsOfReply{Round: round, Error: err.Error()}, nil
+	}
+	reply := &service.AsOfReply{Round: round}
+	for _, value := range values {
+		tx := value.Transaction
+		reply.Entries = append(reply.Entries, &service.KeyValue{
+			Key:       tx.Key,
+			Value:     util.TextValue(tx.Value),
+			RawValue:  []byte(tx.Value),
+			ValueType: tx.Type.String(),
+			Possessor: tx.Possessor,
+			TimeStamp: tx.TimeStamp,
+			Round:     value.Round,
+			Version:   tx.TxID,
+		})
+	}
+	return reply, nil
+}
+
 func (exec *Exec) MustEmbedUnimplementedServerServer() {}
 
 func ServerStart() {
 	//localhost := string(GetOutboundIP())
-	localhost := util.GetLocalIp1()
-	l, err := net.Listen("tcp", localhost+":8888") //开启监听
+	address := config.LocalConfig.ListenAddr(config.LocalConfig.Ports.Client)
+	l, err := net.Listen("tcp", address) //开启监听
 	//fmt.Println(l.Addr().String())
 
 	if err != nil {
 		log.Panic(err)
 	}
-	fmt.Println("Listen on " + localhost + ":8888")
+	fmt.Println("Listen on " + address)
 
 	grpcService := grpc.NewServer()
 
@@ -299,3 +411,132 @@ func ServerStart() {
 		println(err)
 	}
 }
+
+// Batch 批量写入或者读写集交易, 返回交易的 ID
+func (exec *Exec) Batch(ctx context.Context, req *service.BatchRequest) (*service.BatchReply, error) {
+	var writes []blockchain_data.Write
+	for _, w := range req.Writes {
+		typ, err := blockchain_data.ParseValueType(w.ValueType)
+		if err != nil {
+			return &service.BatchReply{Error: err.Error()}, nil
+		}
+		write := blockchain_data.Write{Table: w.TabelName, Key: w.Key, Value: w.Value, Type: typ}
+		if len(w.RawValue) != 0 {
+			write.Value = string(w.RawValue)
+		}
+		if w.Delete {
+			write.Op = blockchain_data.OpDelete
+		}
+		writes = append(writes, write)
+	}
+	var reads []blockchain_data.Read
+	for _, r := range req.Reads {
+		reads = append(reads, blockchain_data.Read{Table: r.TabelName, Key: r.Key, Version: r.Version})
+	}
+	txID, err := RPCs.Transact(req.Uid, reads, writes)
+	if err != nil {
+		return &service.BatchReply{Error: err.Error()}, nil
+	}
+	return &service.BatchReply{TxId: txID}, nil
+}
+
+// PutIf 条件写入, 条件不成立时返回 conflict 和 key 当前的版本
+func (exec *Exec) PutIf(ctx context.Context, req *service.PutIfRequest) (*service.PutIfReply, error) {
+	cond, expect := blockchain_data.CondVersion, req.ExpectVersion
+	if req.MustNotExist {
+		cond, expect = blockchain_data.CondAbsent, nil
+	}
+	txID, err := RPCs.PutIf(req.Uid, req.Key, req.Value, req.TabelName, cond, expect)
+	if conflict, ok := err.(*cache.ConflictError); ok {
+		return &service.PutIfReply{Conflict: true, CurrentVersion: conflict.Current, Error: err.Error()}, nil
+	}
+	if err != nil {
+		return &service.PutIfReply{Error: err.Error()}, nil
+	}
+	return &service.PutIfReply{TxId: txID}, nil
+}
+
+// TxStatus 查询数据交易的状态
+func (exec *Exec) TxStatus(ctx context.Context, req *service.TxStatusRequest) (*service.TxStatusReply, error) {
+	status, reason, round := RPCs.TxStatus(req.TxId)
+	return &service.TxStatusReply{Status: status, Reason: reason, Round: round}, nil
+}
+
+// PutValue 写入指定类型的值, 返回交易的 ID
+func (exec *Exec) PutValue(ctx context.Context, req *service.PutValueRequest) (*service.PutValueReply, error) {
+	typ, err := blockchain_data.ParseValueType(req.ValueType)
+	if err != nil {
+		return &service.PutValueReply{Error: err.Error()}, nil
+	}
+	txID, err := RPCs.PutValue(req.Uid, req.Key, req.TabelName, req.Value, typ)
+	if err != nil {
+		return &service.PutValueReply{Error: err.Error()}, nil
+	}
+	return &service.PutValueReply{TxId: txID}, nil
+}
+
+// GetValue 查询值的原始字节和类型
+func (exec *Exec) GetValue(ctx context.Context, req *service.GetValueRequest) (*service.GetValueReply, error) {
+	value, typ, tx, err := RPCs.GetValue(req.Uid, req.Key, req.TabelName, req.Path)
+	if err != nil {
+		return &service.GetValueReply{Error: err.Error()}, nil
+	}
+	return &service.GetValueReply{Value: value, ValueType: typ.String(), Version: tx.TxID}, nil
+}
+
+// GetSchema 查看表的 schema 和版本
+func (exec *Exec) GetSchema(ctx context.Context, req *service.GetSchemaRequest) (*service.GetSchemaReply, error) {
+	schema, version, err := RPCs.GetSchema(req.Uid, req.TabelName)
+	if err != nil {
+		return &service.GetSchemaReply{Error: err.Error()}, nil
+	}
+	return &service.GetSchemaReply{Schema: schema, Version: version}, nil
+}
+
+// SetSchema 修改表的 schema, 返回表交易的 ID
+func (exec *Exec) SetSchema(ctx context.Context, req *service.SetSchemaRequest) (*service.SetSchemaReply, error) {
+	txID, err := RPCs.SetSchema(req.Uid, req.TabelName, req.Schema)
+	if err != nil {
+		return &service.SetSchemaReply{Error: err.Error()}, nil
+	}
+	return &service.SetSchemaReply{TxId: txID}, nil
+}
+
+// Find 通过二级索引查找数据
+func (exec *Exec) Find(ctx context.Context, req *service.FindRequest) (*service.FindReply, error) {
+	txs, err := RPCs.Find(req.Uid, req.TabelName, req.Field, req.Value, int(req.Limit))
+	if err != nil {
+		return &service.FindReply{Error: err.Error()}, nil
+	}
+	reply := &service.FindReply{}
+	for _, tx := range txs {
+		reply.Entries = append(reply.Entries, &service.KeyValue{
+			Key:       tx.Key,
+			Value:     util.TextValue(tx.Value),
+			RawValue:  []byte(tx.Value),
+			ValueType: tx.Type.String(),
+			Possessor: tx.Possessor,
+			TimeStamp: tx.TimeStamp,
+			Version:   tx.TxID,
+		})
+	}
+	return reply, nil
+}
+
+// GetIndexes 查看表的索引字段
+func (exec *Exec) GetIndexes(ctx context.Context, req *service.GetIndexesRequest) (*service.GetIndexesReply, error) {
+	fields, err := RPCs.GetIndexes(req.Uid, req.TabelName)
+	if err != nil {
+		return &service.GetIndexesReply{Error: err.Error()}, nil
+	}
+	return &service.GetIndexesReply{Fields: fields}, nil
+}
+
+// SetIndexes 声明表的索引字段, 返回表交易的 ID
+func (exec *Exec) SetIndexes(ctx context.Context, req *service.SetIndexesRequest) (*service.SetIndexesReply, error) {
+	txID, err := RPCs.SetIndexes(req.Uid, req.TabelName, req.Fields)
+	if err != nil {
+		return &service.SetIndexesReply{Error: err.Error()}, nil
+	}
+	return &service.SetIndexesReply{TxId: txID}, nil
+}
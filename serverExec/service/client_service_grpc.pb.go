@@ -30,6 +30,34 @@ type ServerClient interface {
 	StreamClient(ctx context.Context, opts ...grpc.CallOption) (Server_StreamClientClient, error)
 	//双向流
 	StreamTwo(ctx context.Context, opts ...grpc.CallOption) (Server_StreamTwoClient, error)
+	//可验证的查询, 返回交易, 默克尔证明和区块头
+	VerifiableGet(ctx context.Context, in *VerifiableGetRequest, opts ...grpc.CallOption) (*VerifiableGetReply, error)
+	//范围查询和前缀查询, 按照 key 的顺序返回
+	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (*ScanReply, error)
+	//时间点查询, 查询数据或者整个表在指定 Round (或者时间) 时的值
+	GetAsOf(ctx context.Context, in *AsOfRequest, opts ...grpc.CallOption) (*AsOfReply, error)
+	//删除数据, 写入一个墓碑交易
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteReply, error)
+	//批量写入, 所有写入在同一个交易里面一起生效
+	Batch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchReply, error)
+	//条件写入, key 当前的版本是 expect_version (或者 must_not_exist 时 key 不存在) 才写入
+	PutIf(ctx context.Context, in *PutIfRequest, opts ...grpc.CallOption) (*PutIfReply, error)
+	//查询数据交易的状态: 等待打包, 打包时被拒绝, 提交时无效
+	TxStatus(ctx context.Context, in *TxStatusRequest, opts ...grpc.CallOption) (*TxStatusReply, error)
+	//写入指定类型的值 (string, bytes, json), 值是原始字节
+	PutValue(ctx context.Context, in *PutValueRequest, opts ...grpc.CallOption) (*PutValueReply, error)
+	//查询值的原始字节和类型, path 不为空时返回 JSON 值里面的子字段
+	GetValue(ctx context.Context, in *GetValueRequest, opts ...grpc.CallOption) (*GetValueReply, error)
+	//查看表的 schema 和版本
+	GetSchema(ctx context.Context, in *GetSchemaRequest, opts ...grpc.CallOption) (*GetSchemaReply, error)
+	//声明, 修改或者去掉 (schema 为空) 表的 schema, 需要 manage 角色
+	SetSchema(ctx context.Context, in *SetSchemaRequest, opts ...grpc.CallOption) (*SetSchemaReply, error)
+	//通过二级索引查找字段等于 value 的数据
+	Find(ctx context.Context, in *FindRequest, opts ...grpc.CallOption) (*FindReply, error)
+	//查看表的二级索引字段
+	GetIndexes(ctx context.Context, in *GetIndexesRequest, opts ...grpc.CallOption) (*GetIndexesReply, error)
+	//声明表的所有二级索引字段 (替换原来的), 需要 manage 角色
+	SetIndexes(ctx context.Context, in *SetIndexesRequest, opts ...grpc.CallOption) (*SetIndexesReply, error)
 }
 
 type serverClient struct {
@@ -146,6 +174,132 @@ func (x *serverStreamTwoClient) Recv() (*StreamRes, error) {
 	return m, nil
 }
 
+func (c *serverClient) VerifiableGet(ctx context.Context, in *VerifiableGetRequest, opts ...grpc.CallOption) (*VerifiableGetReply, error) {
+	out := new(VerifiableGetReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/VerifiableGet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (*ScanReply, error) {
+	out := new(ScanReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/Scan", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) GetAsOf(ctx context.Context, in *AsOfRequest, opts ...grpc.CallOption) (*AsOfReply, error) {
+	out := new(AsOfReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/GetAsOf", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteReply, error) {
+	out := new(DeleteReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) Batch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchReply, error) {
+	out := new(BatchReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/Batch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) PutIf(ctx context.Context, in *PutIfRequest, opts ...grpc.CallOption) (*PutIfReply, error) {
+	out := new(PutIfReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/PutIf", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) TxStatus(ctx context.Context, in *TxStatusRequest, opts ...grpc.CallOption) (*TxStatusReply, error) {
+	out := new(TxStatusReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/TxStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) PutValue(ctx context.Context, in *PutValueRequest, opts ...grpc.CallOption) (*PutValueReply, error) {
+	out := new(PutValueReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/PutValue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) GetValue(ctx context.Context, in *GetValueRequest, opts ...grpc.CallOption) (*GetValueReply, error) {
+	out := new(GetValueReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/GetValue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) GetSchema(ctx context.Context, in *GetSchemaRequest, opts ...grpc.CallOption) (*GetSchemaReply, error) {
+	out := new(GetSchemaReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/GetSchema", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) SetSchema(ctx context.Context, in *SetSchemaRequest, opts ...grpc.CallOption) (*SetSchemaReply, error) {
+	out := new(SetSchemaReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/SetSchema", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) Find(ctx context.Context, in *FindRequest, opts ...grpc.CallOption) (*FindReply, error) {
+	out := new(FindReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/Find", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) GetIndexes(ctx context.Context, in *GetIndexesRequest, opts ...grpc.CallOption) (*GetIndexesReply, error) {
+	out := new(GetIndexesReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/GetIndexes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverClient) SetIndexes(ctx context.Context, in *SetIndexesRequest, opts ...grpc.CallOption) (*SetIndexesReply, error) {
+	out := new(SetIndexesReply)
+	err := c.cc.Invoke(ctx, "/grpc.Server/SetIndexes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ServerServer is the server API for Server service.
 // All implementations must embed UnimplementedServerServer
 // for forward compatibility
@@ -158,6 +312,34 @@ type ServerServer interface {
 	StreamClient(Server_StreamClientServer) error
 	//双向流
 	StreamTwo(Server_StreamTwoServer) error
+	//可验证的查询, 返回交易, 默克尔证明和区块头
+	VerifiableGet(context.Context, *VerifiableGetRequest) (*VerifiableGetReply, error)
+	//范围查询和前缀查询, 按照 key 的顺序返回
+	Scan(context.Context, *ScanRequest) (*ScanReply, error)
+	//时间点查询, 查询数据或者整个表在指定 Round (或者时间) 时的值
+	GetAsOf(context.Context, *AsOfRequest) (*AsOfReply, error)
+	//删除数据, 写入一个墓碑交易
+	Delete(context.Context, *DeleteRequest) (*DeleteReply, error)
+	//批量写入, 所有写入在同一个交易里面一起生效
+	Batch(context.Context, *BatchRequest) (*BatchReply, error)
+	//条件写入, key 当前的版本是 expect_version (或者 must_not_exist 时 key 不存在) 才写入
+	PutIf(context.Context, *PutIfRequest) (*PutIfReply, error)
+	//查询数据交易的状态: 等待打包, 打包时被拒绝, 提交时无效
+	TxStatus(context.Context, *TxStatusRequest) (*TxStatusReply, error)
+	//写入指定类型的值 (string, bytes, json), 值是原始字节
+	PutValue(context.Context, *PutValueRequest) (*PutValueReply, error)
+	//查询值的原始字节和类型, path 不为空时返回 JSON 值里面的子字段
+	GetValue(context.Context, *GetValueRequest) (*GetValueReply, error)
+	//查看表的 schema 和版本
+	GetSchema(context.Context, *GetSchemaRequest) (*GetSchemaReply, error)
+	//声明, 修改或者去掉 (schema 为空) 表的 schema, 需要 manage 角色
+	SetSchema(context.Context, *SetSchemaRequest) (*SetSchemaReply, error)
+	//通过二级索引查找字段等于 value 的数据
+	Find(context.Context, *FindRequest) (*FindReply, error)
+	//查看表的二级索引字段
+	GetIndexes(context.Context, *GetIndexesRequest) (*GetIndexesReply, error)
+	//声明表的所有二级索引字段 (替换原来的), 需要 manage 角色
+	SetIndexes(context.Context, *SetIndexesRequest) (*SetIndexesReply, error)
 	MustEmbedUnimplementedServerServer()
 }
 
@@ -177,6 +359,48 @@ func (UnimplementedServerServer) StreamClient(Server_StreamClientServer) error {
 func (UnimplementedServerServer) StreamTwo(Server_StreamTwoServer) error {
 	return status.Errorf(codes.Unimplemented, "method StreamTwo not implemented")
 }
+func (UnimplementedServerServer) VerifiableGet(context.Context, *VerifiableGetRequest) (*VerifiableGetReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifiableGet not implemented")
+}
+func (UnimplementedServerServer) Scan(context.Context, *ScanRequest) (*ScanReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
+func (UnimplementedServerServer) GetAsOf(context.Context, *AsOfRequest) (*AsOfReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAsOf not implemented")
+}
+func (UnimplementedServerServer) Delete(context.Context, *DeleteRequest) (*DeleteReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedServerServer) Batch(context.Context, *BatchRequest) (*BatchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Batch not implemented")
+}
+func (UnimplementedServerServer) PutIf(context.Context, *PutIfRequest) (*PutIfReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutIf not implemented")
+}
+func (UnimplementedServerServer) TxStatus(context.Context, *TxStatusRequest) (*TxStatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxStatus not implemented")
+}
+func (UnimplementedServerServer) PutValue(context.Context, *PutValueRequest) (*PutValueReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutValue not implemented")
+}
+func (UnimplementedServerServer) GetValue(context.Context, *GetValueRequest) (*GetValueReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetValue not implemented")
+}
+func (UnimplementedServerServer) GetSchema(context.Context, *GetSchemaRequest) (*GetSchemaReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSchema not implemented")
+}
+func (UnimplementedServerServer) SetSchema(context.Context, *SetSchemaRequest) (*SetSchemaReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSchema not implemented")
+}
+func (UnimplementedServerServer) Find(context.Context, *FindRequest) (*FindReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Find not implemented")
+}
+func (UnimplementedServerServer) GetIndexes(context.Context, *GetIndexesRequest) (*GetIndexesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIndexes not implemented")
+}
+func (UnimplementedServerServer) SetIndexes(context.Context, *SetIndexesRequest) (*SetIndexesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetIndexes not implemented")
+}
 func (UnimplementedServerServer) MustEmbedUnimplementedServerServer() {}
 
 // UnsafeServerServer may be embedded to opt out of forward compatibility for this service.
@@ -281,6 +505,258 @@ func (x *serverStreamTwoServer) Recv() (*StreamReq, error) {
 	return m, nil
 }
 
+func _Server_VerifiableGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifiableGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).VerifiableGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/VerifiableGet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).VerifiableGet(ctx, req.(*VerifiableGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_Scan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).Scan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/Scan",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).Scan(ctx, req.(*ScanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_GetAsOf_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AsOfRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).GetAsOf(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/GetAsOf",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).GetAsOf(ctx, req.(*AsOfRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_Batch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).Batch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/Batch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).Batch(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_PutIf_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutIfRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).PutIf(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/PutIf",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).PutIf(ctx, req.(*PutIfRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_TxStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).TxStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/TxStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).TxStatus(ctx, req.(*TxStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_PutValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).PutValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/PutValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).PutValue(ctx, req.(*PutValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_GetValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).GetValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/GetValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).GetValue(ctx, req.(*GetValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_GetSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).GetSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/GetSchema",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).GetSchema(ctx, req.(*GetSchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_SetSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).SetSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/SetSchema",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).SetSchema(ctx, req.(*SetSchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_Find_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).Find(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/Find",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).Find(ctx, req.(*FindRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_GetIndexes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIndexesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).GetIndexes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/GetIndexes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).GetIndexes(ctx, req.(*GetIndexesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Server_SetIndexes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetIndexesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerServer).SetIndexes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.Server/SetIndexes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerServer).SetIndexes(ctx, req.(*SetIndexesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Server_ServiceDesc is the grpc.ServiceDesc for Server service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -292,6 +768,62 @@ var Server_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "cmd",
 			Handler:    _Server_Cmd_Handler,
 		},
+		{
+			MethodName: "VerifiableGet",
+			Handler:    _Server_VerifiableGet_Handler,
+		},
+		{
+			MethodName: "Scan",
+			Handler:    _Server_Scan_Handler,
+		},
+		{
+			MethodName: "GetAsOf",
+			Handler:    _Server_GetAsOf_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Server_Delete_Handler,
+		},
+		{
+			MethodName: "Batch",
+			Handler:    _Server_Batch_Handler,
+		},
+		{
+			MethodName: "PutIf",
+			Handler:    _Server_PutIf_Handler,
+		},
+		{
+			MethodName: "TxStatus",
+			Handler:    _Server_TxStatus_Handler,
+		},
+		{
+			MethodName: "PutValue",
+			Handler:    _Server_PutValue_Handler,
+		},
+		{
+			MethodName: "GetValue",
+			Handler:    _Server_GetValue_Handler,
+		},
+		{
+			MethodName: "GetSchema",
+			Handler:    _Server_GetSchema_Handler,
+		},
+		{
+			MethodName: "SetSchema",
+			Handler:    _Server_SetSchema_Handler,
+		},
+		{
+			MethodName: "Find",
+			Handler:    _Server_Find_Handler,
+		},
+		{
+			MethodName: "GetIndexes",
+			Handler:    _Server_GetIndexes_Handler,
+		},
+		{
+			MethodName: "SetIndexes",
+			Handler:    _Server_SetIndexes_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
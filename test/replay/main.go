@@ -0,0 +1,136 @@
+package main
+
+import (
+	"alg_bcDB/cache/policy"
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 缓存淘汰策略的回放工具。
+// 读取记录的 key 序列 (每行一个, 例如 test/test.go -trace 生成的), 依次交给每个淘汰策略, 输出命中率:
+// 1. 单层缓存: 每个容量 (-sizes) 下的命中率
+// 2. LRU-3 两层缓存: 和节点的查询缓存一样, 交易队列 (-tx) + 索引队列 (-index), 索引队列访问 3 次升级到交易队列
+//   go run ./test/replay -trace trace.txt -sizes 100,1000 -tx 120 -index 300
+
+var (
+	tracePath = flag.String("trace", "", "key trace, one key per line")
+	policies  = flag.String("policies", strings.Join(policy.Names(), ","), "eviction policies to compare")
+	sizes     = flag.String("sizes", "120,300,1000", "single tier cache sizes")
+	txSize    = flag.Int("tx", 120, "LRU-3 tx tier size")
+	indexSize = flag.Int("index", 300, "LRU-3 index tier size")
+)
+
+func main() {
+	flag.Parse()
+	if *tracePath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	keys, err := readTrace(*tracePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	distinct := make(map[string]bool)
+	for _, key := range keys {
+		distinct[key] = true
+	}
+	fmt.Printf("trace: %d reads, %d distinct keys\n\n", len(keys), len(distinct))
+
+	names := strings.Split(*policies, ",")
+	fmt.Printf("single tier\n%-10s %10s %10s %10s\n", "policy", "size", "hits", "hit ratio")
+	for _, s := range strings.Split(*sizes, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			log.Fatalf("bad size %q", s)
+		}
+		for _, name := range names {
+			p, err := policy.New(name, size, nil)
+			if err != nil {
+				log.Fatal(err)
+			}
+			hits := replay(p, keys)
+			fmt.Printf("%-10s %10d %10d %9.2f%%\n", name, size, hits, ratio(hits, len(keys)))
+		}
+	}
+
+	fmt.Printf("\nLRU-3 (tx %d, index %d)\n%-10s %10s %10s %10s %10s\n", *txSize, *indexSize, "policy", "tx hits", "idx hits", "promoted", "hit ratio")
+	for _, name := range names {
+		txHits, indexHits, promoted := replayLRU3(name, keys)
+		fmt.Printf("%-10s %10d %10d %10d %9.2f%%\n", name, txHits, indexHits, promoted, ratio(txHits+indexHits, len(keys)))
+	}
+}
+
+// readTrace 读取 key 序列, 忽略空行和 # 开头的行
+func readTrace(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var keys []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	return keys, scanner.Err()
+}
+
+// replay 单层缓存, 没有命中时加入缓存
+func replay(p policy.Policy, keys []string) (hits int) {
+	for _, key := range keys {
+		if _, has := p.Get(key); has {
+			hits++
+		} else {
+			p.Put(key, struct{}{})
+		}
+	}
+	return hits
+}
+
+// replayLRU3 两层缓存, 规则和 cache.lru3Query 一样
+func replayLRU3(name string, keys []string) (txHits, indexHits, promoted int) {
+	tx, err := policy.New(name, *txSize, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	index, err := policy.New(name, *indexSize, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, key := range keys {
+		if _, has := tx.Get(key); has {
+			txHits++
+			continue
+		}
+		if v, has := index.Get(key); has {
+			indexHits++
+			count := v.(*int)
+			*count++
+			if *count >= 3 {
+				index.Remove(key)
+				tx.Put(key, struct{}{})
+				promoted++
+			}
+			continue
+		}
+		count := 1
+		index.Put(key, &count)
+	}
+	return txHits, indexHits, promoted
+}
+
+func ratio(hits, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) * 100 / float64(total)
+}
@@ -2,12 +2,16 @@ package main
 
 import (
 	"alg_bcDB/serverExec/service"
+	"bufio"
 	"context"
 	"flag"
 	"fmt"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"log"
+	"math/rand"
+	"os"
+	"strconv"
 	"time"
 )
 
@@ -17,6 +21,11 @@ const (
 
 var (
 	addr = flag.String("addr", "192.168.1.101:8888", "the address to connect to")
+	// 读取的次数和 key 的数量, zipf > 1 时按照 Zipf 分布选择 key, 否则按顺序读取
+	n     = flag.Int("n", 4000, "number of reads")
+	keys  = flag.Int("keys", 4000, "number of distinct keys")
+	zipf  = flag.Float64("zipf", 0, "zipf parameter s (> 1), 0 for sequential keys")
+	trace = flag.String("trace", "", "record the read keys to this file, for test/replay")
 	//name = flag.String("name", defaultName, "Name to greet")
 )
 
@@ -53,9 +62,32 @@ func main() {
 
 	count := 0
 
+	// 记录读取的 key (数据ID, 和缓存里面的一样), 每行一个
+	var traceFile *bufio.Writer
+	if *trace != "" {
+		f, err := os.Create(*trace)
+		if err != nil {
+			log.Fatalf("could not create trace: %v", err)
+		}
+		defer f.Close()
+		traceFile = bufio.NewWriter(f)
+		defer traceFile.Flush()
+	}
+	var z *rand.Zipf
+	if *zipf > 1 {
+		z = rand.NewZipf(rand.New(rand.NewSource(1)), *zipf, 1, uint64(*keys-1))
+	}
+
 	start1 := time.Now() // 获取当前时间
-	for i := 0; i < 4000; i++ {
-		key := "key" + string(i)
+	for i := 0; i < *n; i++ {
+		k := i % *keys
+		if z != nil {
+			k = int(z.Uint64())
+		}
+		key := "key" + strconv.Itoa(k)
+		if traceFile != nil {
+			fmt.Fprintln(traceFile, taableName+"-QAQ-"+key)
+		}
 		//value := "value" + string(i)
 		//_, err := c.Cmd(context.Background(), &service.CommandRequest{Uid: uid, TabelName: taableName, Key: key, Value: value})
 		_, err := c.Cmd(context.Background(), &service.CommandRequest{Uid: uid, Key: key, TabelName: taableName})
@@ -112,6 +112,16 @@ func (tpl *TxPool) TxTableIN(transaction blockchain_table.Transaction) error {
 	return nil
 }
 
+// DataTxRejected 数据交易在打包时是否没有通过检查 (没有权限, 条件不成立), 返回原因
+func (tpl *TxPool) DataTxRejected(txID []byte) (string, bool) {
+	return tpl.txPoolData.Rejected(txID)
+}
+
+// DataTxPending 数据交易是否还在交易池里面等待打包
+func (tpl *TxPool) DataTxPending(txID []byte) bool {
+	return tpl.txPoolData.Pending(txID)
+}
+
 func (tpl *TxPool) UpdateByData(block blockchain_data.Block) {
 	tpl.txPoolData.OrdinaryRun(block)
 }
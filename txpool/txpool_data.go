@@ -86,13 +86,20 @@ type TxPoolData struct {
 
 	chain *blockchain_data.BlockChain
 	cache *cache.Cache
+
+	rejected      map[string]string // 打包时没有通过检查的交易: TxID -> 原因
+	rejectedOrder []string          // 按照时间顺序的 TxID, 超过 maxRejected 时删除最早的记录
 }
 
+// maxRejected 交易池保存的没有通过检查的交易记录的最大数量
+const maxRejected = 10000
+
 func (tpl *TxPoolData) init(chain *blockchain_data.BlockChain, cache *cache.Cache) {
 	tpl.Lock()
 	defer tpl.Unlock()
 
 	tpl.txQueue.init()
+	tpl.rejected = make(map[string]string)
 
 	tpl.packNumber = 1
 	tpl.count = 0
@@ -137,12 +144,25 @@ func (tpl *TxPoolData) bookKeeperRun() {
 				p = p.next
 			} // result: p指向一个不被打包的元素。或者刚好打包完，指向尾节点。
 
+			// 去掉没有写入权限或者写入条件不成立的交易 (比如两个用户在同一个区块里面创建同一个 key)
+			txs, rejected, tableID, tableHash := tpl.cache.FilterWrites(txs)
+			for _, r := range rejected {
+				fmt.Printf("交易 %x 不打包: %v\n", r.Tx.TxID, r.Err)
+				tpl.reject(r.Tx.TxID, r.Err.Error())
+			}
+			if len(txs) == 0 {
+				tpl.txQueue.head.next = p
+				p.pre = tpl.txQueue.head
+				tpl.count -= tpl.packNumber
+				continue
+			}
+
 			//block := blockchain_data.NewBlock()
 			//block.InitBlock(txs, tpl.chain.TailHash, tpl.chain.LastID)
 			// 提议区块
-			block := algorand.LocalAlg.ProcessMain(txs, round, vrf, proof, subUsers)
+			// 区块头里面记录检查时使用的表区块链的位置, 其他节点按照这个位置校验
+			block := algorand.LocalAlg.ProcessMain(txs, tableID, tableHash, round, vrf, proof, subUsers)
 			if block.Author == alg.Pubkey.Address() {
-				tpl.chain.LastID++
 				tpl.chain.AddBlockToChain(*block)
 				fmt.Println("生成一个新的数据区块", time.Now().String())
 				// 更新本地缓存
@@ -167,6 +187,36 @@ func (tpl *TxPoolData) bookKeeperRun() {
 	}
 }
 
+// reject 记录没有通过检查的交易, 调用时已经持有锁
+func (tpl *TxPoolData) reject(txID []byte, reason string) {
+	if _, has := tpl.rejected[string(txID)]; !has {
+		tpl.rejectedOrder = append(tpl.rejectedOrder, string(txID))
+	}
+	tpl.rejected[string(txID)] = reason
+	if len(tpl.rejectedOrder) > maxRejected {
+		delete(tpl.rejected, tpl.rejectedOrder[0])
+		tpl.rejectedOrder = tpl.rejectedOrder[1:]
+	}
+}
+
+// Rejected 交易在打包时是否没有通过检查, 返回原因
+func (tpl *TxPoolData) Rejected(txID []byte) (string, bool) {
+	tpl.Lock()
+	defer tpl.Unlock()
+
+	reason, has := tpl.rejected[string(txID)]
+	return reason, has
+}
+
+// Pending 交易是否还在交易池里面等待打包
+func (tpl *TxPoolData) Pending(txID []byte) bool {
+	tpl.Lock()
+	defer tpl.Unlock()
+
+	_, has := tpl.txQueue.txMap[string(txID)]
+	return has
+}
+
 // OrdinaryRun 普通节点在接受到记账节点，发过来的区块时。根据区块里面的交易，删除自己交易池里面对应的交易。
 func (tpl *TxPoolData) OrdinaryRun(block blockchain_data.Block) {
 	tpl.Lock()
@@ -188,6 +238,10 @@ func (tpl *TxPoolData) TxIn(transaction blockchain_data.Transaction) error {
 	if tpl.txQueue.curSize == tpl.txQueue.maxSize {
 		return errors.New("FULL")
 	}
+	// 不符合表的 schema 的写入不进入交易池
+	if err := tpl.cache.CheckSchema(&transaction); err != nil {
+		return err
+	}
 
 	tpl.txQueue.in(transaction)
 
@@ -63,6 +63,10 @@ func (q *tableQueue) in(transaction blockchain_table.Transaction) {
 func (q *tableQueue) out(transaction blockchain_table.Transaction) {
 
 	p := q.txMap[string(transaction.TxID)]
+	// 交易不在本地的交易池里面 (没有收到或者入池时被拒绝)
+	if p == nil {
+		return
+	}
 	p.pre.next = p.next
 	p.next.pre = p.pre
 
@@ -120,16 +124,26 @@ func (tpl *TxPoolTable) bookKeeperRun() {
 		var txs []*blockchain_table.Transaction
 		p := tpl.tableQueue.head.next // start: p.第一个要被打包的元素
 		for i := 0; i < tpl.packNumber; i++ {
-			txs = append(txs, &p.tx)
+			// 入池以后表的权限可能已经改变, 签名者没有权限的交易不打包
+			if err := tpl.cache.CheckTableTx(&p.tx); err != nil {
+				fmt.Println("丢弃表交易:", err)
+			} else {
+				txs = append(txs, &p.tx)
+			}
 			delete(tpl.tableQueue.txMap, string(p.tx.TxID))
 			tpl.tableQueue.curSize--
 
 			p = p.next
 		} // result: p指向一个不被打包的元素。或者刚好打包完，指向尾节点。
+		tpl.tableQueue.head.next = p
+		p.pre = tpl.tableQueue.head
+		tpl.count -= tpl.packNumber
+		if len(txs) == 0 {
+			continue
+		}
 
 		block := blockchain_table.NewBlock()
 		block.InitBlock(txs, tpl.chain.TailHash, tpl.chain.LastID)
-		tpl.chain.LastID++
 		tpl.chain.AddBlockToChain(block)
 		fmt.Println("生成一个新的共享表区块", time.Now().String())
 
@@ -140,10 +154,6 @@ func (tpl *TxPoolTable) bookKeeperRun() {
 		//fmt.Println("区块入队")
 		blockqueue.LocalTableBlockQueue.Put(block)
 		//fmt.Println("入队完成")
-		tpl.tableQueue.head.next = p
-		p.pre = tpl.tableQueue.head
-
-		tpl.count -= tpl.packNumber
 	}
 }
 
@@ -168,6 +178,10 @@ func (tpl *TxPoolTable) TxIn(transaction blockchain_table.Transaction) error {
 	if tpl.tableQueue.curSize == tpl.tableQueue.maxSize {
 		return errors.New("FULL")
 	}
+	// 签名者必须有修改表的权限
+	if err := tpl.cache.CheckTableTx(&transaction); err != nil {
+		return err
+	}
 
 	tpl.tableQueue.in(transaction)
 
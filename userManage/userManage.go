@@ -1,16 +1,14 @@
 package userManage
 
 import (
+	"alg_bcDB/config"
 	"alg_bcDB/util"
 	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/gob"
 	"errors"
-	"github.com/btcsuite/btcutil/base58"
-	"golang.org/x/crypto/ripemd160"
 	"log"
 	"os"
 	"sync"
@@ -30,7 +28,10 @@ func (umg *UserManage) Init() {
 	defer umg.Mutex.Unlock()
 
 	umg.EffectiveUser = make(map[string]*Account)
-	umg.UserFilesPath = "userManage/user_files/"
+	umg.UserFilesPath = config.LocalConfig.Path("userManage/user_files") + string(os.PathSeparator)
+	if err := os.MkdirAll(umg.UserFilesPath, 0755); err != nil {
+		log.Panic(err)
+	}
 }
 
 type Account struct {
@@ -78,7 +79,7 @@ func (umg *UserManage) Register(username, password string) error {
 	}
 	a.PrivateKey = *privateKey
 	a.PublicKey = append(privateKey.PublicKey.X.Bytes(), privateKey.PublicKey.Y.Bytes()...)
-	a.Address = calculateAddress(a.PublicKey)
+	a.Address = util.CalculateAddress(a.PublicKey)
 	// 2.保存到本地
 	err = umg.SaveAccount2File(a)
 	if err != nil {
@@ -184,42 +185,3 @@ func (umg *UserManage) LoadAccount2File(account *Account) error {
 	}
 	return nil
 }
-
-// CalculateAddress 初始化钱包里面的地址
-// 根据钱包的公钥私钥得到钱包对应的地址
-func calculateAddress(publicKey []byte) string {
-	// https://www.cnblogs.com/kumata/p/10477369.html
-	publicKeyHash := hashPublicKey(publicKey)
-
-	version := 0x00
-	payload := append([]byte{byte(version)}, publicKeyHash...)
-
-	checksum := checkSum(payload)
-	payload = append(payload, checksum...)
-
-	address := base58.Encode(payload)
-
-	return address
-}
-
-func hashPublicKey(publicKey []byte) []byte {
-
-	sha256Hash := sha256.Sum256(publicKey)
-
-	ripeMD160Hash := ripemd160.New()
-	_, err := ripeMD160Hash.Write(sha256Hash[:])
-	if err != nil {
-		log.Panic(err)
-	}
-	publicHash := ripeMD160Hash.Sum(nil)
-
-	return publicHash
-}
-
-func checkSum(payload []byte) []byte {
-	first := sha256.Sum256(payload)
-	second := sha256.Sum256(first[:])
-
-	checksum := second[0:4]
-	return checksum
-}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"crypto/sha256"
+	"log"
+
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// CalculateAddress 根据用户的公钥得到用户的地址。
+// 交易里面带有公钥, 所有节点都可以由交易得到写入者的地址
+func CalculateAddress(publicKey []byte) string {
+	// https://www.cnblogs.com/kumata/p/10477369.html
+	publicKeyHash := hashPublicKey(publicKey)
+
+	version := 0x00
+	payload := append([]byte{byte(version)}, publicKeyHash...)
+
+	checksum := checkSum(payload)
+	payload = append(payload, checksum...)
+
+	address := base58.Encode(payload)
+
+	return address
+}
+
+func hashPublicKey(publicKey []byte) []byte {
+
+	sha256Hash := sha256.Sum256(publicKey)
+
+	ripeMD160Hash := ripemd160.New()
+	_, err := ripeMD160Hash.Write(sha256Hash[:])
+	if err != nil {
+		log.Panic(err)
+	}
+	publicHash := ripeMD160Hash.Sum(nil)
+
+	return publicHash
+}
+
+func checkSum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+
+	checksum := second[0:4]
+	return checksum
+}
@@ -0,0 +1,127 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPath 在 JSON 里面按照路径取出子字段, 返回子字段原来的 JSON。
+// 路径的格式: $.a.b[0].c, 开头的 $ 和 . 可以省略, 名字里面有 . 或者 [ 时写作 ["a.b"]; 空路径返回整个 JSON
+func JSONPath(data []byte, path string) ([]byte, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	current := json.RawMessage(data)
+	for _, step := range steps {
+		if step.isIndex {
+			var array []json.RawMessage
+			if err := json.Unmarshal(current, &array); err != nil {
+				return nil, fmt.Errorf("%s 不是数组", step)
+			}
+			if step.index < 0 || step.index >= len(array) {
+				return nil, fmt.Errorf("%s 超出数组的长度 %d", step, len(array))
+			}
+			current = array[step.index]
+			continue
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(current, &object); err != nil {
+			return nil, fmt.Errorf("%s 的上一级不是对象", step)
+		}
+		value, has := object[step.name]
+		if !has {
+			return nil, fmt.Errorf("没有字段 %s", step)
+		}
+		current = value
+	}
+	return current, nil
+}
+
+// jsonStep 路径的一步, 对象的字段或者数组的下标
+type jsonStep struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+func (s jsonStep) String() string {
+	if s.isIndex {
+		return fmt.Sprintf("[%d]", s.index)
+	}
+	return s.name
+}
+
+func parseJSONPath(path string) ([]jsonStep, error) {
+	bad := fmt.Errorf("错误的 JSON 路径 %s", path)
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var steps []jsonStep
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			j := i
+			for j < len(path) && path[j] != '.' && path[j] != '[' {
+				j++
+			}
+			if j == i {
+				return nil, bad
+			}
+			steps = append(steps, jsonStep{name: path[i:j]})
+			i = j
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, bad
+			}
+			inner := path[i+1 : i+end]
+			if name, err := strconv.Unquote(inner); err == nil {
+				steps = append(steps, jsonStep{name: name})
+			} else if index, err := strconv.Atoi(inner); err == nil {
+				steps = append(steps, jsonStep{index: index, isIndex: true})
+			} else {
+				return nil, bad
+			}
+			i += end + 1
+		default:
+			// 开头省略了 .
+			if i != 0 {
+				return nil, bad
+			}
+			path = "." + path
+		}
+	}
+	return steps, nil
+}
+
+// CheckJSONPath 检查 JSON 路径的格式
+func CheckJSONPath(path string) error {
+	steps, err := parseJSONPath(path)
+	if err == nil && len(steps) == 0 {
+		err = fmt.Errorf("错误的 JSON 路径 %s", path)
+	}
+	return err
+}
+
+// IndexValue 二级索引里面 JSON 路径指向的值: 字符串为字符串的内容, 其他的值为规范的 JSON (比如 3, true)。
+// 没有这个字段或者值为 null 时返回 false
+func IndexValue(data []byte, path string) (string, bool) {
+	raw, err := JSONPath(data, path)
+	if err != nil {
+		return "", false
+	}
+	var v interface{}
+	if err = json.Unmarshal(raw, &v); err != nil || v == nil {
+		return "", false
+	}
+	if s, ok := v.(string); ok {
+		return s, true
+	}
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(canonical), true
+}
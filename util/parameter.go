@@ -7,12 +7,8 @@ var TokenPerUser uint64 = 10000
 var Malicious uint64 = 0
 var NetworkLatency = 0
 
-func TotalTokenAmount() uint64 {
-	return UserAmount * TokenPerUser
-}
-
-const (
-	// Algorand 系统参数
+// Algorand 系统参数, 可以在创世文件里面修改
+var (
 	ExpectedBlockProposers        = 26 // 期望区块提议者数量
 	ExpectedCommitteeMembers      = 10
 	ThresholdOfBAStep             = 0.2
@@ -20,16 +16,24 @@ const (
 	FinalThreshold                = 0.1
 	MAXSTEPS                      = 12
 
-	// timeout param
+	// interval
+	R uint64 = 1000 // seed 刷新间隔 (# of rounds)
+)
+
+// timeout param, 可以在配置文件里面修改
+var (
 	LamdaPriority = 5 * time.Second // time to gossip sortition proofs.
 	//LamdaBlock    = 1 * time.Minute // timeout for receiving a block.
 	LamdaBlock   = 10 * time.Second // timeout for receiving a block.
 	LamdaStep    = 2 * time.Second  // timeout for BA* step.
 	LamdaStepvar = 5 * time.Second  // estimate of BA* completion time variance.
+)
 
-	// interval
-	R = 1000 // seed 刷新间隔 (# of rounds)
+func TotalTokenAmount() uint64 {
+	return UserAmount * TokenPerUser
+}
 
+const (
 	// helper const var
 	Committee = "committee"
 	Proposer  = "proposer"
@@ -12,10 +12,11 @@ import (
 	"os"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
-// LocalIP LocalPort 本地的ip和端口号（全局）
-var LocalIP = GetLocalIp()
+// LocalIP LocalPort 本地的ip和端口号（全局）, 启动时由配置设置
+var LocalIP = "127.0.0.1"
 var LocalPort = 3301
 var LocalIsAccount = false
 var IsDone = true
@@ -113,18 +114,19 @@ func BytesToStr(val []byte) string {
 	return string(val)
 }
 
-// GetLocalIp 获取本机的ip
+// GetLocalIp 获取本机的ip。 没有网络时返回回环地址
 func GetLocalIp() (ip string) {
 	conn, err := net.Dial("udp", "8.8.8.8:53")
+	if err != nil {
+		log.Println("获取本机的ip失败, 使用 127.0.0.1;", err)
+		return "127.0.0.1"
+	}
 	defer func(conn net.Conn) {
 		err := conn.Close()
 		if err != nil {
 			log.Panic(err)
 		}
 	}(conn) // 链接的关闭
-	if err != nil {
-		log.Panic(err)
-	}
 	localAddr := conn.LocalAddr().(*net.UDPAddr)   // 获取回环地址，既可以与外界通信的ip地址
 	ip = strings.Split(localAddr.String(), ":")[0] // 得到ip
 	//port = strings.Split(localAddr.String(), ":")[1]
@@ -141,15 +143,15 @@ func GetLocalIp() (ip string) {
 }
 func GetLocalIp1() (ip string) {
 	conn, err := net.Dial("udp", "8.8.8.8:53")
+	if err != nil {
+		return "127.0.0.1"
+	}
 	defer func(conn net.Conn) {
 		err := conn.Close()
 		if err != nil {
 			log.Panic(err)
 		}
 	}(conn) // 链接的关闭
-	if err != nil {
-		log.Panic(err)
-	}
 	localAddr := conn.LocalAddr().(*net.UDPAddr)   // 获取回环地址，既可以与外界通信的ip地址
 	ip = strings.Split(localAddr.String(), ":")[0] // 得到ip
 	//port = strings.Split(localAddr.String(), ":")[1]
@@ -230,3 +232,11 @@ func DialCustom(network, address string, timeout time.Duration, localIP []byte,
 	d := net.Dialer{Timeout: timeout, LocalAddr: netAddr}
 	return d.Dial(network, address)
 }
+
+// TextValue protobuf 的 string 字段必须是 UTF-8, 不是 UTF-8 的值 (bytes 类型) 返回空字符串, 使用 bytes 字段传输
+func TextValue(value string) string {
+	if utf8.ValidString(value) {
+		return value
+	}
+	return ""
+}